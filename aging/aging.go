@@ -0,0 +1,87 @@
+// Package aging provides a priority queue in which waiting elements gain
+// priority over time, so that low-priority elements are not starved by a
+// steady stream of higher-priority ones.
+//
+// Every element's effective priority grows at the same constant rate while it
+// waits: priority + rate*(now - enqueued). Because the rate is the same for all
+// elements, the difference between the effective priorities of any two
+// elements never changes, so the queue is ordered by priority - rate*enqueued
+// and never needs to be re-weighted.
+package aging
+
+import (
+	"time"
+
+	"github.com/gammazero/heap"
+)
+
+// Queue is a priority queue where elements with higher effective priority are
+// removed first. It is not safe for concurrent use.
+type Queue[T any] struct {
+	h        *heap.Heap[entry[T]]
+	priority func(T) float64
+	rate     float64
+	epoch    time.Time
+	seq      uint64
+}
+
+type entry[T any] struct {
+	val T
+	key float64
+	seq uint64
+}
+
+// New returns a new Queue. The priority function returns the base priority of
+// an element, and rate is the amount of priority that an element gains for
+// each second that it waits in the queue.
+func New[T any](priority func(T) float64, rate float64) *Queue[T] {
+	return &Queue[T]{
+		h: heap.New(func(a, b entry[T]) bool {
+			if a.key != b.key {
+				return a.key > b.key
+			}
+			return a.seq < b.seq
+		}),
+		priority: priority,
+		rate:     rate,
+		epoch:    time.Now(),
+	}
+}
+
+// Len returns the number of elements in the queue.
+func (q *Queue[T]) Len() int {
+	return q.h.Len()
+}
+
+// Push adds an element to the queue, enqueued now.
+func (q *Queue[T]) Push(x T) {
+	q.PushAt(x, time.Now())
+}
+
+// PushAt adds an element to the queue as if it were enqueued at the given
+// time. This is useful for restoring elements that were already waiting.
+func (q *Queue[T]) PushAt(x T, enqueued time.Time) {
+	q.seq++
+	q.h.Push(entry[T]{
+		val: x,
+		key: q.priority(x) - q.rate*enqueued.Sub(q.epoch).Seconds(),
+		seq: q.seq,
+	})
+}
+
+// Peek returns the element with the highest effective priority without
+// removing it.
+func (q *Queue[T]) Peek() T {
+	return q.h.Peek().val
+}
+
+// Pop removes and returns the element with the highest effective priority.
+func (q *Queue[T]) Pop() T {
+	return q.h.Pop().val
+}
+
+// Priority returns the effective priority, at time now, of the element that
+// Peek returns.
+func (q *Queue[T]) Priority(now time.Time) float64 {
+	return q.h.Peek().key + q.rate*now.Sub(q.epoch).Seconds()
+}
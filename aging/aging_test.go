@@ -0,0 +1,56 @@
+package aging_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gammazero/heap/aging"
+)
+
+type job struct {
+	name string
+	prio float64
+}
+
+func TestAging(t *testing.T) {
+	// Each second of waiting is worth one unit of priority.
+	q := aging.New(func(j job) float64 { return j.prio }, 1)
+
+	start := time.Now()
+	q.PushAt(job{"old-low", 1}, start)
+	q.PushAt(job{"new-high", 5}, start.Add(10*time.Second))
+	q.PushAt(job{"new-mid", 3}, start.Add(10*time.Second))
+
+	// At start+10s, old-low has effective priority 11, higher than new-high.
+	if name := q.Peek().name; name != "old-low" {
+		t.Fatalf("expected old-low at head, got %s", name)
+	}
+	if p := q.Priority(start.Add(10 * time.Second)); p < 10.999 || p > 11.001 {
+		t.Fatalf("expected effective priority 11, got %f", p)
+	}
+
+	var got []string
+	for q.Len() != 0 {
+		got = append(got, q.Pop().name)
+	}
+	want := []string{"old-low", "new-high", "new-mid"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestNoAging(t *testing.T) {
+	q := aging.New(func(j job) float64 { return j.prio }, 0)
+	start := time.Now()
+	q.PushAt(job{"low", 1}, start)
+	q.PushAt(job{"high", 2}, start.Add(time.Hour))
+	q.Push(job{"also-high", 2})
+	if name := q.Pop().name; name != "high" {
+		t.Fatalf("expected high, got %s", name)
+	}
+	if name := q.Pop().name; name != "also-high" {
+		t.Fatalf("expected also-high, got %s", name)
+	}
+}
@@ -0,0 +1,199 @@
+// Package astar provides the open set of the A* search algorithm: a priority
+// queue of nodes ordered by f = g + h, where g is the cost of the best known
+// path to a node and h is the heuristic estimate of the remaining cost.
+//
+// When a better path to a node in the open set is found, the node's priority
+// is updated in place. The open set also remembers the nodes that have been
+// expanded, which form the closed set. With a consistent heuristic, a closed
+// node is never reached by a better path. With a heuristic that is admissible
+// but not consistent, it can be, and the node is reopened so that it is
+// expanded again; Push reports when this happens.
+package astar
+
+import "github.com/gammazero/heap"
+
+// Cost is the type of path costs.
+type Cost interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+// TieBreak chooses between open nodes with equal f.
+type TieBreak int
+
+const (
+	// HighG prefers the node with the greater g, which is the node closer
+	// to the goal by its heuristic. This usually expands the fewest nodes,
+	// and is the default.
+	HighG TieBreak = iota
+	// LowG prefers the node with the smaller g.
+	LowG
+	// FIFO prefers the node that was pushed or updated first.
+	FIFO
+	// LIFO prefers the node that was pushed or updated last.
+	LIFO
+)
+
+// Status is the result of Push.
+type Status int
+
+const (
+	// Ignored means the node already has a path that is no worse, and
+	// nothing changed.
+	Ignored Status = iota
+	// Added means the node was added to the open set for the first time.
+	Added
+	// Updated means the node was in the open set, and its path was
+	// replaced by the better path.
+	Updated
+	// Reopened means the node had been expanded, and was returned to the
+	// open set because a better path was found. This only happens with a
+	// heuristic that is not consistent.
+	Reopened
+)
+
+// OpenSet is the open set of an A* search. It is not safe for concurrent use.
+type OpenSet[K comparable, C Cost] struct {
+	h     *heap.Heap[*node[K, C]]
+	nodes map[K]*node[K, C]
+	seq   uint64
+	// reopened counts the nodes that were reopened.
+	reopened int
+}
+
+type node[K comparable, C Cost] struct {
+	key K
+	g   C
+	f   C
+	seq uint64
+	// pos is the node's index in the heap, or -1 if it is closed.
+	pos int
+}
+
+// New returns a new, empty OpenSet that breaks ties between nodes with equal
+// f as given by tie.
+func New[K comparable, C Cost](tie TieBreak) *OpenSet[K, C] {
+	var less func(a, b *node[K, C]) bool
+	switch tie {
+	case HighG:
+		less = func(a, b *node[K, C]) bool {
+			if a.f != b.f {
+				return a.f < b.f
+			}
+			if a.g != b.g {
+				return a.g > b.g
+			}
+			return a.seq < b.seq
+		}
+	case LowG:
+		less = func(a, b *node[K, C]) bool {
+			if a.f != b.f {
+				return a.f < b.f
+			}
+			if a.g != b.g {
+				return a.g < b.g
+			}
+			return a.seq < b.seq
+		}
+	case FIFO:
+		less = func(a, b *node[K, C]) bool {
+			if a.f != b.f {
+				return a.f < b.f
+			}
+			return a.seq < b.seq
+		}
+	case LIFO:
+		less = func(a, b *node[K, C]) bool {
+			if a.f != b.f {
+				return a.f < b.f
+			}
+			return a.seq > b.seq
+		}
+	default:
+		panic("astar: invalid tie-break")
+	}
+	s := &OpenSet[K, C]{
+		h:     heap.New(less),
+		nodes: make(map[K]*node[K, C]),
+	}
+	s.h.SetOnMove(func(n *node[K, C], i int) {
+		n.pos = i
+	})
+	return s
+}
+
+// Len returns the number of nodes in the open set.
+func (s *OpenSet[K, C]) Len() int {
+	return s.h.Len()
+}
+
+// Push records a path to key with cost g, and heuristic estimate h of the
+// remaining cost to the goal. If key has no recorded path, it is added to the
+// open set. If the path is better than the recorded path, the recorded path
+// is replaced and key is placed in the open set in the order of its new f,
+// reopening it if it was closed. Otherwise nothing changes.
+func (s *OpenSet[K, C]) Push(key K, g, h C) Status {
+	s.seq++
+	n, ok := s.nodes[key]
+	if !ok {
+		n = &node[K, C]{key: key, g: g, f: g + h, seq: s.seq}
+		s.nodes[key] = n
+		s.h.Push(n)
+		return Added
+	}
+	if g >= n.g {
+		return Ignored
+	}
+	n.g, n.f, n.seq = g, g+h, s.seq
+	if n.pos < 0 {
+		s.reopened++
+		s.h.Push(n)
+		return Reopened
+	}
+	s.h.Fix(n.pos)
+	return Updated
+}
+
+// Pop removes the node with the least f from the open set and closes it. It
+// returns the node and the cost of the best path to it. It panics if the open
+// set is empty.
+func (s *OpenSet[K, C]) Pop() (key K, g C) {
+	n := s.h.Pop()
+	return n.key, n.g
+}
+
+// Peek returns the node with the least f and its f, without removing it. It
+// panics if the open set is empty.
+func (s *OpenSet[K, C]) Peek() (key K, f C) {
+	n := s.h.Peek()
+	return n.key, n.f
+}
+
+// G returns the cost of the best path found to key, and whether any path to
+// key has been found. The node may be open or closed.
+func (s *OpenSet[K, C]) G(key K) (C, bool) {
+	n, ok := s.nodes[key]
+	if !ok {
+		return 0, false
+	}
+	return n.g, true
+}
+
+// IsOpen returns whether key is in the open set.
+func (s *OpenSet[K, C]) IsOpen(key K) bool {
+	n, ok := s.nodes[key]
+	return ok && n.pos >= 0
+}
+
+// IsClosed returns whether key has been expanded, by being popped, and not
+// reopened since.
+func (s *OpenSet[K, C]) IsClosed(key K) bool {
+	n, ok := s.nodes[key]
+	return ok && n.pos < 0
+}
+
+// Reopened returns the number of times a closed node has been reopened.
+func (s *OpenSet[K, C]) Reopened() int {
+	return s.reopened
+}
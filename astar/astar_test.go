@@ -0,0 +1,113 @@
+package astar_test
+
+import (
+	"testing"
+
+	"github.com/gammazero/heap/astar"
+)
+
+type point struct{ x, y int }
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func TestGrid(t *testing.T) {
+	// Shortest path on a grid with a wall, using the Manhattan distance.
+	const size = 10
+	wall := func(p point) bool { return p.x == 5 && p.y < 8 }
+	start, goal := point{0, 0}, point{9, 0}
+	h := func(p point) int { return abs(p.x-goal.x) + abs(p.y-goal.y) }
+
+	for _, tie := range []astar.TieBreak{astar.HighG, astar.LowG, astar.FIFO, astar.LIFO} {
+		open := astar.New[point, int](tie)
+		open.Push(start, 0, h(start))
+		var found bool
+		var expanded int
+		for open.Len() != 0 {
+			p, g := open.Pop()
+			expanded++
+			if !open.IsClosed(p) || open.IsOpen(p) {
+				t.Fatal("popped node not closed")
+			}
+			if p == goal {
+				if g != 25 {
+					t.Fatalf("tie-break %d: path cost %d, expected 25", tie, g)
+				}
+				found = true
+				break
+			}
+			for _, d := range []point{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+				q := point{p.x + d.x, p.y + d.y}
+				if q.x < 0 || q.y < 0 || q.x >= size || q.y >= size || wall(q) {
+					continue
+				}
+				open.Push(q, g+1, h(q))
+			}
+		}
+		if !found {
+			t.Fatalf("tie-break %d: goal not found", tie)
+		}
+		if open.Reopened() != 0 {
+			t.Fatal("consistent heuristic reopened nodes")
+		}
+		t.Logf("tie-break %d expanded %d nodes", tie, expanded)
+	}
+}
+
+func TestPushStatus(t *testing.T) {
+	open := astar.New[string, float64](astar.HighG)
+	if s := open.Push("a", 5, 1); s != astar.Added {
+		t.Fatalf("expected Added, got %d", s)
+	}
+	if s := open.Push("a", 6, 1); s != astar.Ignored {
+		t.Fatalf("expected Ignored, got %d", s)
+	}
+	open.Push("b", 4, 3)
+	if k, f := open.Peek(); k != "a" || f != 6 {
+		t.Fatalf("wrong head %s f=%v", k, f)
+	}
+	if s := open.Push("b", 1, 3); s != astar.Updated {
+		t.Fatalf("expected Updated, got %d", s)
+	}
+	if k, g := open.Pop(); k != "b" || g != 1 {
+		t.Fatalf("expected b with g 1, got %s %v", k, g)
+	}
+	if s := open.Push("b", 2, 3); s != astar.Ignored {
+		t.Fatalf("expected Ignored for worse path to closed node, got %d", s)
+	}
+	if s := open.Push("b", 0.5, 3); s != astar.Reopened {
+		t.Fatalf("expected Reopened, got %d", s)
+	}
+	if !open.IsOpen("b") || open.Reopened() != 1 {
+		t.Fatal("node not reopened")
+	}
+	if g, ok := open.G("b"); !ok || g != 0.5 {
+		t.Fatalf("wrong g %v", g)
+	}
+	if _, ok := open.G("z"); ok {
+		t.Fatal("unexpected path to unknown node")
+	}
+}
+
+func TestTieBreak(t *testing.T) {
+	for _, tc := range []struct {
+		tie  astar.TieBreak
+		want string
+	}{
+		{astar.HighG, "deep"},
+		{astar.LowG, "shallow"},
+		{astar.FIFO, "shallow"},
+		{astar.LIFO, "deep"},
+	} {
+		open := astar.New[string, int](tc.tie)
+		open.Push("shallow", 1, 9)
+		open.Push("deep", 8, 2)
+		if k, _ := open.Pop(); k != tc.want {
+			t.Errorf("tie-break %d: expected %s, got %s", tc.tie, tc.want, k)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package heap
+
+// Batch modifies the elements of a heap without maintaining the heap ordering
+// after each change. It is passed to the function given to [Heap.Batch], and
+// is only valid until that function returns.
+type Batch[T any] struct {
+	h     *Heap[T]
+	dirty []int
+}
+
+// Batch calls fn with a Batch that pushes, removes, and replaces elements of
+// the heap without maintaining the heap ordering after each change. When fn
+// returns, or panics, the heap ordering is restored once for all of the
+// changes. When few elements have changed, only the changed elements and
+// their ancestors are sifted, in O(k log n) time for k changes. When many
+// have changed, the whole heap is rebuilt in O(n) time. This is faster than
+// making the changes one at a time, which takes O(k log n) time regardless.
+//
+// The heap itself must not be used during fn. Elements are at the indexes
+// reported to the onMove function, and as returned by the Batch's At method,
+// but the heap is not in heap order until fn returns.
+func (h *Heap[T]) Batch(fn func(b *Batch[T])) {
+	h.ensureOrdered()
+	if h.guard != nil {
+		h.startWrite()
+	}
+	defer h.endOp()
+	b := &Batch[T]{h: h}
+	defer b.commit()
+	h.modify()
+	fn(b)
+}
+
+// commit restores the heap ordering of the elements changed by the batch, and
+// ends the batch.
+func (b *Batch[T]) commit() {
+	h := b.h
+	b.h = nil
+	n := len(h.data)
+	dirty := b.dirty[:0]
+	// Elements that were removed may have left indexes beyond the end.
+	for _, i := range b.dirty {
+		if i < n {
+			dirty = append(dirty, i)
+		}
+	}
+	if len(dirty) != 0 {
+		h.fixIndexes(dirty)
+	}
+	h.watermarksChanged()
+}
+
+func (b *Batch[T]) mustHeap() *Heap[T] {
+	if b.h == nil {
+		panic("heap: Batch used after Batch function returned")
+	}
+	return b.h
+}
+
+// Len returns the number of elements in the heap.
+func (b *Batch[T]) Len() int {
+	return len(b.mustHeap().data)
+}
+
+// At returns the element at index i.
+func (b *Batch[T]) At(i int) T {
+	h := b.mustHeap()
+	if i < 0 || i >= len(h.data) {
+		panic("heap: Batch At index out of range")
+	}
+	return h.data[i]
+}
+
+// Push adds an element to the heap.
+func (b *Batch[T]) Push(x T) {
+	h := b.mustHeap()
+	h.mustHaveLess()
+	if h.stats != nil {
+		h.stats.Pushes++
+		if len(h.data) == cap(h.data) {
+			h.stats.Reallocs++
+		}
+	}
+	c := cap(h.data)
+	h.grow()
+	h.data = append(h.data, x)
+	i := len(h.data) - 1
+	if h.onMove != nil {
+		h.onMove(x, i)
+	}
+	b.dirty = append(b.dirty, i)
+	h.notifyPush(len(h.data), c)
+	if h.journal != nil {
+		h.record(OpPush, x, len(h.data))
+	}
+}
+
+// Remove removes and returns the element at index i. The last element is
+// moved to index i.
+func (b *Batch[T]) Remove(i int) T {
+	h := b.mustHeap()
+	n := len(h.data) - 1
+	if i < 0 || i > n {
+		panic("heap: Batch Remove index out of range")
+	}
+	if h.stats != nil {
+		h.stats.Pops++
+	}
+	x := h.data[i]
+	h.data[i] = h.data[n]
+	h.clearSlot(n)
+	h.data = h.data[:n]
+	if i != n {
+		if h.onMove != nil {
+			h.onMove(h.data[i], i)
+		}
+		b.dirty = append(b.dirty, i)
+	}
+	if h.onMove != nil {
+		h.onMove(x, -1)
+	}
+	h.notifyRemove(1, n)
+	if h.journal != nil {
+		h.record(OpRemove, x, n)
+	}
+	return x
+}
+
+// Set replaces the element at index i.
+func (b *Batch[T]) Set(i int, x T) {
+	h := b.mustHeap()
+	if i < 0 || i >= len(h.data) {
+		panic("heap: Batch Set index out of range")
+	}
+	old := h.data[i]
+	h.data[i] = x
+	if h.onMove != nil {
+		h.onMove(old, -1)
+		h.onMove(x, i)
+	}
+	b.dirty = append(b.dirty, i)
+	if h.journal != nil {
+		h.recordSet(x, old, len(h.data))
+	}
+}
@@ -0,0 +1,100 @@
+package heap_test
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/gammazero/heap"
+)
+
+func TestBatch(t *testing.T) {
+	for _, changes := range []int{1, 5, 500} {
+		h := heap.NewFrom(func(a, b int) bool { return a < b }, rand.Perm(1000)...)
+		h.EnableStats(true)
+		h.Batch(func(b *heap.Batch[int]) {
+			for range changes {
+				b.Push(rand.IntN(2000))
+				b.Set(rand.IntN(b.Len()), rand.IntN(2000))
+				b.Remove(rand.IntN(b.Len()))
+			}
+			if b.Len() != 1000 {
+				t.Fatalf("batch length %d", b.Len())
+			}
+		})
+		stats := h.Stats()
+		if err := h.Verify(); err != nil {
+			t.Fatalf("%d changes: %v", changes, err)
+		}
+		if stats.Pushes != uint64(changes) || stats.Pops != uint64(changes) {
+			t.Fatalf("%d changes: wrong stats %+v", changes, stats)
+		}
+		// A few changes are fixed without rebuilding the heap.
+		if changes == 1 && stats.Compares > 100 {
+			t.Fatalf("one change took %d compares", stats.Compares)
+		}
+	}
+}
+
+func TestBatchOnMove(t *testing.T) {
+	type item struct {
+		val int
+		pos int
+	}
+	h := heap.New(func(a, b *item) bool { return a.val < b.val })
+	h.SetOnMove(func(x *item, i int) { x.pos = i })
+	items := make([]*item, 100)
+	for i := range items {
+		items[i] = &item{val: i}
+		h.Push(items[i])
+	}
+	var removed []*item
+	h.Batch(func(b *heap.Batch[*item]) {
+		for _, x := range items[:50] {
+			if x.val%2 == 0 {
+				removed = append(removed, b.Remove(x.pos))
+			} else {
+				b.Set(x.pos, &item{val: x.val + 1000})
+			}
+		}
+	})
+	if h.Len() != 75 {
+		t.Fatalf("heap has %d elements, expected 75", h.Len())
+	}
+	for _, x := range append(removed, items[:50]...) {
+		if x.pos != -1 {
+			t.Fatalf("item %d removed from heap has index %d", x.val, x.pos)
+		}
+	}
+	for i := range h.Len() {
+		if h.At(i).pos != i {
+			t.Fatalf("item at %d has index %d", i, h.At(i).pos)
+		}
+	}
+	var got []int
+	for x := range h.Drain() {
+		got = append(got, x.val)
+	}
+	if !slices.IsSorted(got) || got[0] != 50 || got[len(got)-1] != 1049 {
+		t.Fatalf("wrong elements after batch: %v", got)
+	}
+}
+
+func TestBatchPanic(t *testing.T) {
+	h := heap.NewFrom(func(a, b int) bool { return a < b }, 5, 6, 7)
+	var saved *heap.Batch[int]
+	assertPanics(t, "Batch", func() {
+		h.Batch(func(b *heap.Batch[int]) {
+			saved = b
+			b.Push(1)
+			panic("failed")
+		})
+	})
+	if h.Peek() != 1 {
+		t.Fatal("heap not in order after panic in Batch")
+	}
+	assertPanics(t, "Batch after return", func() { saved.Push(2) })
+	assertPanics(t, "Batch Remove", func() {
+		h.Batch(func(b *heap.Batch[int]) { b.Remove(4) })
+	})
+}
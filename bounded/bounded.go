@@ -0,0 +1,240 @@
+// Package bounded provides a priority buffer that holds at most a fixed number
+// of elements, or elements of at most a fixed total weight, such as a queue of
+// pending work that must not grow without limit.
+//
+// When an element is pushed onto a full buffer, a Policy decides what is
+// shed: the new element, or the worst elements in the buffer, which are the
+// elements that would be popped last. Elements are kept in two heaps, one
+// ordered from best to worst and one from worst to best, so that both the
+// best and the worst element can be found in O(1) and removed in O(log n).
+package bounded
+
+import "github.com/gammazero/heap"
+
+// Policy determines what happens when an element is pushed onto a full
+// buffer.
+type Policy int
+
+const (
+	// Reject does not add the new element.
+	Reject Policy = iota
+	// EvictWorst always adds the new element, and evicts the worst elements
+	// in the buffer to make room, even if the new element is worse.
+	EvictWorst
+	// KeepBest keeps the best elements. The worst elements in the buffer are
+	// evicted to make room for the new element only if the new element is
+	// better than each of them. Otherwise, the new element is not added.
+	KeepBest
+)
+
+// Bounded is a priority buffer that holds at most a fixed number of elements,
+// or elements of at most a fixed total weight. It is not safe for concurrent
+// use.
+type Bounded[T any] struct {
+	best    *heap.Heap[*entry[T]]
+	worst   *heap.Heap[*entry[T]]
+	less    func(a, b T) bool
+	weigh   func(T) int64
+	budget  int64
+	weight  int64
+	policy  Policy
+	onEvict func(T)
+}
+
+type entry[T any] struct {
+	val      T
+	weight   int64
+	bestPos  int
+	worstPos int
+}
+
+// New returns a new Bounded that holds at most limit elements, ordered by
+// less so that the least element is the best. The policy determines what is
+// shed when an element is pushed onto a full buffer. If onEvict is not nil, it
+// is called with each element evicted from the buffer.
+func New[T any](less func(a, b T) bool, limit int, policy Policy, onEvict func(T)) *Bounded[T] {
+	if limit < 1 {
+		panic("bounded: limit must be positive")
+	}
+	return newBounded(less, int64(limit), nil, policy, onEvict)
+}
+
+// NewWeighted returns a new Bounded that holds elements with a total weight of
+// at most budget, where the weight of each element is given by weigh, such as
+// its size in bytes. The weight of an element is computed once, when it is
+// pushed, and must not be negative. The elements are ordered by less so that
+// the least element is the best. The policy determines what is shed when an
+// element is pushed that does not fit within the budget. If onEvict is not
+// nil, it is called with each element evicted from the buffer.
+func NewWeighted[T any](less func(a, b T) bool, budget int64, weigh func(T) int64, policy Policy, onEvict func(T)) *Bounded[T] {
+	if budget < 0 {
+		panic("bounded: negative budget")
+	}
+	if weigh == nil {
+		panic("bounded: nil weigh function")
+	}
+	return newBounded(less, budget, weigh, policy, onEvict)
+}
+
+func newBounded[T any](less func(a, b T) bool, budget int64, weigh func(T) int64, policy Policy, onEvict func(T)) *Bounded[T] {
+	b := &Bounded[T]{
+		best: heap.New(func(x, y *entry[T]) bool {
+			return less(x.val, y.val)
+		}),
+		worst: heap.New(func(x, y *entry[T]) bool {
+			return less(y.val, x.val)
+		}),
+		less:    less,
+		weigh:   weigh,
+		budget:  budget,
+		policy:  policy,
+		onEvict: onEvict,
+	}
+	b.best.SetOnMove(func(e *entry[T], i int) {
+		e.bestPos = i
+	})
+	b.worst.SetOnMove(func(e *entry[T], i int) {
+		e.worstPos = i
+	})
+	return b
+}
+
+// Len returns the number of elements in the buffer.
+func (b *Bounded[T]) Len() int {
+	return b.best.Len()
+}
+
+// Limit returns the maximum number of elements the buffer holds, or 0 if the
+// buffer is bounded by weight.
+func (b *Bounded[T]) Limit() int {
+	if b.weigh != nil {
+		return 0
+	}
+	return int(b.budget)
+}
+
+// Weight returns the total weight of the elements in the buffer. If the
+// buffer is bounded by the number of elements, each element has weight 1.
+func (b *Bounded[T]) Weight() int64 {
+	return b.weight
+}
+
+// Budget returns the maximum total weight of the elements the buffer holds.
+// If the buffer is bounded by the number of elements, this is the limit.
+func (b *Bounded[T]) Budget() int64 {
+	return b.budget
+}
+
+// Push adds x to the buffer, and returns whether x was added. If x does not
+// fit, the buffer's policy determines whether x is added and whether the
+// worst elements are evicted to make room for it. The elements shed to make
+// room are passed to the eviction callback, worst first. The callback is
+// called only for elements evicted from the buffer, not for x when it is not
+// added. An element heavier than the budget is never added.
+func (b *Bounded[T]) Push(x T) bool {
+	e := &entry[T]{val: x, weight: 1}
+	if b.weigh != nil {
+		e.weight = b.weigh(x)
+		if e.weight < 0 {
+			panic("bounded: negative weight")
+		}
+	}
+	if e.weight > b.budget {
+		return false
+	}
+	if b.weight+e.weight > b.budget {
+		switch b.policy {
+		case EvictWorst:
+			for b.weight+e.weight > b.budget {
+				b.evict(b.removeWorst())
+			}
+		case KeepBest:
+			if !b.evictWorse(e) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	b.best.Push(e)
+	b.worst.Push(e)
+	b.weight += e.weight
+	return true
+}
+
+// evictWorse evicts the worst elements until e fits, if each of them is worse
+// than e, and returns whether it did. Otherwise, it leaves the buffer
+// unchanged.
+func (b *Bounded[T]) evictWorse(e *entry[T]) bool {
+	var shed []*entry[T]
+	for b.weight+e.weight > b.budget {
+		w := b.worst.Peek()
+		// An element equal to e is not worse, so the element already in the
+		// buffer is kept.
+		if !b.less(e.val, w.val) {
+			for _, w := range shed {
+				b.best.Push(w)
+				b.worst.Push(w)
+				b.weight += w.weight
+			}
+			return false
+		}
+		b.removeWorst()
+		shed = append(shed, w)
+	}
+	for _, w := range shed {
+		b.evict(w.val)
+	}
+	return true
+}
+
+func (b *Bounded[T]) evict(x T) {
+	if b.onEvict != nil {
+		b.onEvict(x)
+	}
+}
+
+// Peek returns the best element without removing it. It panics if the buffer
+// is empty.
+func (b *Bounded[T]) Peek() T {
+	if b.best.Len() == 0 {
+		panic("bounded: Peek called on empty buffer")
+	}
+	return b.best.Peek().val
+}
+
+// Pop removes and returns the best element. It panics if the buffer is empty.
+func (b *Bounded[T]) Pop() T {
+	if b.best.Len() == 0 {
+		panic("bounded: Pop called on empty buffer")
+	}
+	e := b.best.Pop()
+	b.worst.Remove(e.worstPos)
+	b.weight -= e.weight
+	return e.val
+}
+
+// Worst returns the worst element without removing it. It panics if the
+// buffer is empty.
+func (b *Bounded[T]) Worst() T {
+	if b.worst.Len() == 0 {
+		panic("bounded: Worst called on empty buffer")
+	}
+	return b.worst.Peek().val
+}
+
+// PopWorst removes and returns the worst element. It panics if the buffer is
+// empty. The eviction callback is not called.
+func (b *Bounded[T]) PopWorst() T {
+	if b.worst.Len() == 0 {
+		panic("bounded: PopWorst called on empty buffer")
+	}
+	return b.removeWorst()
+}
+
+func (b *Bounded[T]) removeWorst() T {
+	e := b.worst.Pop()
+	b.best.Remove(e.bestPos)
+	b.weight -= e.weight
+	return e.val
+}
@@ -0,0 +1,164 @@
+package bounded_test
+
+import (
+	"cmp"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/gammazero/heap/bounded"
+)
+
+func TestPolicies(t *testing.T) {
+	tests := []struct {
+		policy  bounded.Policy
+		push    int
+		added   bool
+		evicted []int
+		want    []int
+	}{
+		{bounded.Reject, 1, false, nil, []int{2, 4, 6}},
+		{bounded.Reject, 9, false, nil, []int{2, 4, 6}},
+		{bounded.EvictWorst, 1, true, []int{6}, []int{1, 2, 4}},
+		{bounded.EvictWorst, 9, true, []int{6}, []int{2, 4, 9}},
+		{bounded.KeepBest, 1, true, []int{6}, []int{1, 2, 4}},
+		{bounded.KeepBest, 6, false, nil, []int{2, 4, 6}},
+		{bounded.KeepBest, 9, false, nil, []int{2, 4, 6}},
+	}
+	for _, tt := range tests {
+		var evicted []int
+		b := bounded.New(cmp.Less[int], 3, tt.policy, func(x int) {
+			evicted = append(evicted, x)
+		})
+		for _, x := range []int{4, 6, 2} {
+			if !b.Push(x) {
+				t.Fatalf("policy %d: push %d to non-full buffer failed", tt.policy, x)
+			}
+		}
+		if added := b.Push(tt.push); added != tt.added {
+			t.Errorf("policy %d: push %d returned %t, expected %t", tt.policy, tt.push, added, tt.added)
+		}
+		if !slices.Equal(evicted, tt.evicted) {
+			t.Errorf("policy %d: push %d evicted %v, expected %v", tt.policy, tt.push, evicted, tt.evicted)
+		}
+		var got []int
+		for b.Len() != 0 {
+			got = append(got, b.Pop())
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("policy %d: push %d left %v, expected %v", tt.policy, tt.push, got, tt.want)
+		}
+	}
+}
+
+func TestKeepBest(t *testing.T) {
+	const limit = 20
+	var evicted int
+	b := bounded.New(cmp.Less[int], limit, bounded.KeepBest, func(int) {
+		evicted++
+	})
+	vals := make([]int, 500)
+	var added int
+	for i := range vals {
+		vals[i] = rand.Intn(1000)
+		if b.Push(vals[i]) {
+			added++
+		}
+		if b.Len() > limit {
+			t.Fatalf("buffer holds %d elements, limit is %d", b.Len(), limit)
+		}
+	}
+	if added-evicted != limit {
+		t.Fatalf("added %d and evicted %d, expected difference of %d", added, evicted, limit)
+	}
+	slices.Sort(vals)
+	if b.Worst() != vals[limit-1] {
+		t.Fatalf("worst is %d, expected %d", b.Worst(), vals[limit-1])
+	}
+	for i := range limit {
+		if x := b.Pop(); x != vals[i] {
+			t.Fatalf("popped %d, expected %d", x, vals[i])
+		}
+	}
+}
+
+func TestPopWorst(t *testing.T) {
+	b := bounded.New(cmp.Less[int], 10, bounded.Reject, nil)
+	for _, x := range rand.Perm(10) {
+		b.Push(x)
+	}
+	if b.Peek() != 0 || b.Worst() != 9 {
+		t.Fatalf("wrong best %d or worst %d", b.Peek(), b.Worst())
+	}
+	for want := 9; want >= 5; want-- {
+		if x := b.PopWorst(); x != want {
+			t.Fatalf("PopWorst returned %d, expected %d", x, want)
+		}
+	}
+	for want := range 5 {
+		if x := b.Pop(); x != want {
+			t.Fatalf("Pop returned %d, expected %d", x, want)
+		}
+	}
+	if b.Len() != 0 {
+		t.Fatal("expected empty buffer")
+	}
+}
+
+func TestWeighted(t *testing.T) {
+	type job struct {
+		pri  int
+		size int64
+	}
+	less := func(a, b job) bool { return a.pri < b.pri }
+	size := func(j job) int64 { return j.size }
+	jobs := []job{{1, 40}, {5, 30}, {3, 20}, {7, 10}}
+
+	tests := []struct {
+		policy  bounded.Policy
+		push    job
+		added   bool
+		evicted []int
+		weight  int64
+	}{
+		{bounded.Reject, job{2, 5}, false, nil, 100},
+		{bounded.Reject, job{2, 101}, false, nil, 100},
+		{bounded.EvictWorst, job{9, 35}, true, []int{7, 5}, 95},
+		{bounded.KeepBest, job{4, 35}, true, []int{7, 5}, 95},
+		{bounded.KeepBest, job{6, 35}, false, nil, 100},
+		{bounded.KeepBest, job{0, 100}, true, []int{7, 5, 3, 1}, 100},
+	}
+	for _, tt := range tests {
+		var evicted []int
+		b := bounded.NewWeighted(less, 100, size, tt.policy, func(j job) {
+			evicted = append(evicted, j.pri)
+		})
+		for _, j := range jobs {
+			b.Push(j)
+		}
+		if b.Weight() != 100 || b.Len() != len(jobs) {
+			t.Fatalf("policy %d: weight %d and length %d after filling", tt.policy, b.Weight(), b.Len())
+		}
+		if added := b.Push(tt.push); added != tt.added {
+			t.Errorf("policy %d: push %v returned %t, expected %t", tt.policy, tt.push, added, tt.added)
+		}
+		if !slices.Equal(evicted, tt.evicted) {
+			t.Errorf("policy %d: push %v evicted %v, expected %v", tt.policy, tt.push, evicted, tt.evicted)
+		}
+		if b.Weight() != tt.weight {
+			t.Errorf("policy %d: push %v left weight %d, expected %d", tt.policy, tt.push, b.Weight(), tt.weight)
+		}
+		// A failed KeepBest push must leave the buffer in order.
+		prev := -1
+		for b.Len() != 0 {
+			j := b.Pop()
+			if j.pri < prev {
+				t.Fatalf("policy %d: popped %d after %d", tt.policy, j.pri, prev)
+			}
+			prev = j.pri
+		}
+		if b.Weight() != 0 {
+			t.Errorf("policy %d: weight %d after popping all", tt.policy, b.Weight())
+		}
+	}
+}
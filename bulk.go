@@ -0,0 +1,126 @@
+package heap
+
+// DeleteFunc removes all elements for which del returns true, and returns the
+// number of elements removed. The elements are removed in a single pass,
+// followed by one O(n) rebuild of the heap ordering, which is much faster than
+// calling Remove for each element when many elements are removed.
+func (h *Heap[T]) DeleteFunc(del func(T) bool) int {
+	if h.guard != nil {
+		h.startWrite()
+	}
+	defer h.endOp()
+	h.modify()
+	var j int
+	for i, x := range h.data {
+		if del(x) {
+			if h.onMove != nil {
+				h.onMove(x, -1)
+			}
+			if h.journal != nil {
+				h.record(OpRemove, x, len(h.data)-(i-j+1))
+			}
+			continue
+		}
+		if i != j {
+			h.data[j] = x
+			if h.onMove != nil {
+				h.onMove(x, j)
+			}
+		}
+		j++
+	}
+	return h.truncate(j, false)
+}
+
+// Truncate removes elements until at most n elements remain, keeping the n
+// smallest elements, and returns the number of elements removed. It takes
+// O(m) time on average, where m = h.Len().
+func (h *Heap[T]) Truncate(n int) int {
+	if n < 0 {
+		panic("heap: Truncate with negative length")
+	}
+	if n >= len(h.data) {
+		return 0
+	}
+	if h.guard != nil {
+		h.startWrite()
+	}
+	defer h.endOp()
+	h.modify()
+	selectSmallest(h.data, n, h.less)
+	if h.onMove != nil {
+		for i, x := range h.data[:n] {
+			h.onMove(x, i)
+		}
+	}
+	return h.truncate(n, true)
+}
+
+// truncate removes the elements after the first n and restores the heap
+// ordering of the first n elements, which do not need to be in heap order. If
+// notify is true, the removed elements are passed to the onMove function. It
+// returns the number of elements removed.
+func (h *Heap[T]) truncate(n int, notify bool) int {
+	removed := len(h.data) - n
+	if removed == 0 {
+		return 0
+	}
+	h.modify()
+	for i := n; i < len(h.data); i++ {
+		if notify && h.onMove != nil {
+			h.onMove(h.data[i], -1)
+		}
+		if notify && h.journal != nil {
+			h.record(OpRemove, h.data[i], len(h.data)-(i-n+1))
+		}
+		h.clearSlot(i)
+	}
+	h.data = h.data[:n]
+	h.heapify()
+	h.watermarksChanged()
+	h.notifyRemove(removed, n)
+	return removed
+}
+
+// selectSmallest reorders data so that the k smallest elements are in
+// data[:k], in no particular order.
+func selectSmallest[T any](data []T, k int, less func(a, b T) bool) {
+	lo, hi := 0, len(data)-1
+	for lo < hi {
+		// Use the median of three elements as the pivot.
+		mid := lo + (hi-lo)/2
+		if less(data[mid], data[lo]) {
+			data[mid], data[lo] = data[lo], data[mid]
+		}
+		if less(data[hi], data[lo]) {
+			data[hi], data[lo] = data[lo], data[hi]
+		}
+		if less(data[hi], data[mid]) {
+			data[hi], data[mid] = data[mid], data[hi]
+		}
+		pivot := data[mid]
+		i, j := lo, hi
+		for i <= j {
+			for less(data[i], pivot) {
+				i++
+			}
+			for less(pivot, data[j]) {
+				j--
+			}
+			if i <= j {
+				data[i], data[j] = data[j], data[i]
+				i++
+				j--
+			}
+		}
+		// Now data[lo:j+1] <= pivot <= data[i:hi+1].
+		switch {
+		case k <= j:
+			hi = j
+		case k >= i:
+			lo = i
+		default:
+			return
+		}
+	}
+}
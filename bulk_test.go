@@ -0,0 +1,74 @@
+package heap_test
+
+import (
+	"cmp"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/gammazero/heap"
+)
+
+func TestDeleteFunc(t *testing.T) {
+	h := heap.New(cmp.Less[int])
+	pos := map[int]int{}
+	h.SetOnMove(func(x, i int) {
+		if i < 0 {
+			delete(pos, x)
+		} else {
+			pos[x] = i
+		}
+	})
+	for _, x := range rand.Perm(1000) {
+		h.Push(x)
+	}
+	if n := h.DeleteFunc(func(x int) bool { return x%3 == 0 }); n != 334 {
+		t.Fatalf("expected 334 removed, got %d", n)
+	}
+	if h.Len() != 666 || len(pos) != 666 {
+		t.Fatalf("wrong length after delete: %d, tracked %d", h.Len(), len(pos))
+	}
+	for x, i := range pos {
+		if h.At(i) != x {
+			t.Fatalf("tracked index of %d is %d, but At(%d) is %d", x, i, i, h.At(i))
+		}
+	}
+	if n := h.DeleteFunc(func(int) bool { return false }); n != 0 {
+		t.Fatalf("expected none removed, got %d", n)
+	}
+	prev := -1
+	for h.Len() != 0 {
+		x := h.Pop()
+		if x%3 == 0 || x < prev {
+			t.Fatalf("unexpected element %d after %d", x, prev)
+		}
+		prev = x
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	for _, n := range []int{0, 1, 10, 500, 999} {
+		data := make([]int, 1000)
+		for i := range data {
+			data[i] = rand.Intn(100)
+		}
+		h := heap.NewFrom(cmp.Less[int], slices.Clone(data)...)
+		if removed := h.Truncate(n); removed != len(data)-n {
+			t.Fatalf("expected %d removed, got %d", len(data)-n, removed)
+		}
+		slices.Sort(data)
+		var out []int
+		for h.Len() != 0 {
+			out = append(out, h.Pop())
+		}
+		if !slices.Equal(out, data[:n]) {
+			t.Fatalf("Truncate(%d) kept wrong elements", n)
+		}
+	}
+
+	h := heap.NewFrom(cmp.Less[int], 3, 1, 2)
+	if h.Truncate(5) != 0 || h.Len() != 3 {
+		t.Fatal("Truncate to larger length removed elements")
+	}
+	assertPanics(t, "negative length", func() { h.Truncate(-1) })
+}
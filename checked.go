@@ -0,0 +1,26 @@
+package heap
+
+import "errors"
+
+// ErrNilLess is returned by NewChecked and NewFromChecked when the less
+// function is nil.
+var ErrNilLess = errors.New("heap: less function is nil")
+
+// NewChecked is like New, but returns ErrNilLess if less is nil instead of
+// returning a heap that panics when it is used. This is useful when the less
+// function is supplied by a caller.
+func NewChecked[T any](less func(a, b T) bool, opts ...Option[T]) (*Heap[T], error) {
+	if less == nil {
+		return nil, ErrNilLess
+	}
+	return New(less, opts...), nil
+}
+
+// NewFromChecked is like NewFrom, but returns ErrNilLess if less is nil
+// instead of panicking.
+func NewFromChecked[T any](less func(a, b T) bool, data ...T) (*Heap[T], error) {
+	if less == nil {
+		return nil, ErrNilLess
+	}
+	return NewFrom(less, data...), nil
+}
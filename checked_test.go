@@ -0,0 +1,36 @@
+package heap_test
+
+import (
+	"cmp"
+	"errors"
+	"testing"
+
+	"github.com/gammazero/heap"
+)
+
+func TestNewChecked(t *testing.T) {
+	if _, err := heap.NewChecked[int](nil); !errors.Is(err, heap.ErrNilLess) {
+		t.Fatalf("expected ErrNilLess, got %v", err)
+	}
+	if _, err := heap.NewFromChecked(nil, 3, 1, 2); !errors.Is(err, heap.ErrNilLess) {
+		t.Fatalf("expected ErrNilLess, got %v", err)
+	}
+
+	h, err := heap.NewChecked(cmp.Less[int], heap.WithCapacity[int](4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Push(2)
+	h.Push(1)
+	if h.Pop() != 1 {
+		t.Fatal("wrong element popped")
+	}
+
+	h, err = heap.NewFromChecked(cmp.Less[int], 3, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Pop() != 1 {
+		t.Fatal("wrong element popped")
+	}
+}
@@ -0,0 +1,140 @@
+package heap
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// maxBlockSize is the largest block of snapshot data covered by one checksum.
+const maxBlockSize = 1 << 16
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// CorruptionError is returned by Load when part of a snapshot does not match
+// its checksum.
+type CorruptionError struct {
+	// Offset is the offset, from the start of the snapshot, of the data that
+	// failed verification.
+	Offset int64
+}
+
+func (e *CorruptionError) Error() string {
+	return fmt.Sprintf("heap: snapshot corrupt at offset %d", e.Offset)
+}
+
+// blockWriter writes data in blocks, each holding the length of the block
+// data, the data, and a checksum of the data. A block with length 0 marks the
+// end of the data.
+type blockWriter struct {
+	w   io.Writer
+	buf []byte
+}
+
+func newBlockWriter(w io.Writer) *blockWriter {
+	return &blockWriter{
+		w:   w,
+		buf: make([]byte, 4, 4+maxBlockSize+4),
+	}
+}
+
+func (bw *blockWriter) Write(p []byte) (int, error) {
+	var n int
+	for len(p) != 0 {
+		c := copy(bw.buf[len(bw.buf):4+maxBlockSize], p)
+		bw.buf = bw.buf[:len(bw.buf)+c]
+		n += c
+		p = p[c:]
+		if len(bw.buf) == 4+maxBlockSize {
+			if err := bw.flush(); err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, nil
+}
+
+func (bw *blockWriter) flush() error {
+	data := bw.buf[4:]
+	binary.BigEndian.PutUint32(bw.buf, uint32(len(data)))
+	bw.buf = binary.BigEndian.AppendUint32(bw.buf, crc32.Checksum(data, crcTable))
+	_, err := bw.w.Write(bw.buf)
+	bw.buf = bw.buf[:4]
+	return err
+}
+
+// Close writes any buffered data and the end marker.
+func (bw *blockWriter) Close() error {
+	if len(bw.buf) > 4 {
+		if err := bw.flush(); err != nil {
+			return err
+		}
+	}
+	_, err := bw.w.Write([]byte{0, 0, 0, 0})
+	return err
+}
+
+// blockReader reads data written by blockWriter, verifying the checksum of
+// each block before returning any of its data.
+type blockReader struct {
+	r    io.Reader
+	off  int64
+	buf  []byte
+	data []byte
+	done bool
+}
+
+func newBlockReader(r io.Reader, off int64) *blockReader {
+	return &blockReader{r: r, off: off}
+}
+
+func (br *blockReader) Read(p []byte) (int, error) {
+	for len(br.data) == 0 {
+		if br.done {
+			return 0, io.EOF
+		}
+		if err := br.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, br.data)
+	br.data = br.data[n:]
+	return n, nil
+}
+
+func (br *blockReader) next() error {
+	var lb [4]byte
+	if _, err := io.ReadFull(br.r, lb[:]); err != nil {
+		return unexpectedEOF(err)
+	}
+	n := binary.BigEndian.Uint32(lb[:])
+	if n == 0 {
+		br.done = true
+		return nil
+	}
+	if n > maxBlockSize {
+		return &CorruptionError{Offset: br.off}
+	}
+	if cap(br.buf) < int(n)+4 {
+		br.buf = make([]byte, int(n)+4)
+	}
+	buf := br.buf[:n+4]
+	if _, err := io.ReadFull(br.r, buf); err != nil {
+		return unexpectedEOF(err)
+	}
+	data := buf[:n]
+	if crc32.Checksum(data, crcTable) != binary.BigEndian.Uint32(buf[n:]) {
+		return &CorruptionError{Offset: br.off}
+	}
+	br.off += int64(n) + 8
+	br.data = data
+	return nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
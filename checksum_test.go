@@ -0,0 +1,71 @@
+package heap_test
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/gammazero/heap"
+)
+
+func TestSnapshotChecksum(t *testing.T) {
+	h := heap.New(cmp.Less[int])
+	for i := range 20000 {
+		h.Push(i)
+	}
+	var buf bytes.Buffer
+	if err := h.Save(&buf, encodeInt); err != nil {
+		t.Fatal(err)
+	}
+	snapshot := buf.Bytes()
+
+	// Load reads exactly the bytes of the snapshot.
+	r := bytes.NewReader(append(bytes.Clone(snapshot), 1, 2, 3))
+	h2 := heap.New(cmp.Less[int])
+	if err := h2.Load(r, decodeInt); err != nil {
+		t.Fatal(err)
+	}
+	if r.Len() != 3 || h2.Len() != h.Len() {
+		t.Fatal("wrong number of bytes read")
+	}
+
+	// Corrupt a byte in the header, the first block, and the second block.
+	const headerSize = 18 + 4
+	const blockSize = 1<<16 + 8
+	for _, tc := range []struct {
+		off  int
+		want int64
+	}{
+		{10, 0},
+		{headerSize + 100, headerSize},
+		{headerSize + blockSize + 100, headerSize + blockSize},
+	} {
+		bad := bytes.Clone(snapshot)
+		bad[tc.off] ^= 0x10
+		err := h2.Load(bytes.NewReader(bad), decodeInt)
+		var cerr *heap.CorruptionError
+		if !errors.As(err, &cerr) {
+			t.Fatalf("expected CorruptionError for offset %d, got %v", tc.off, err)
+		}
+		if cerr.Offset != tc.want {
+			t.Fatalf("expected corruption at offset %d, got %d", tc.want, cerr.Offset)
+		}
+	}
+
+	// Corruption after the last element is detected.
+	bad := bytes.Clone(snapshot)
+	bad[len(bad)-6] ^= 0x01
+	var cerr *heap.CorruptionError
+	if err := h2.Load(bytes.NewReader(bad), decodeInt); !errors.As(err, &cerr) {
+		t.Fatalf("expected CorruptionError, got %v", err)
+	}
+
+	if err := h2.Load(bytes.NewReader(snapshot[:len(snapshot)-2]), decodeInt); err != io.ErrUnexpectedEOF {
+		t.Fatalf("expected ErrUnexpectedEOF, got %v", err)
+	}
+	if h2.Len() != h.Len() {
+		t.Fatal("heap modified by failed load")
+	}
+}
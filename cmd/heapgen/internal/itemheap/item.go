@@ -0,0 +1,11 @@
+// Package itemheap holds a heap generated by heapgen, so that tests can
+// compile and run generated code and compare it with heap.Heap.
+package itemheap
+
+//go:generate go run github.com/gammazero/heap/cmd/heapgen -type Item -name ItemHeap -field Prio -package itemheap -o itemheap.go
+
+// Item is the element type of ItemHeap.
+type Item struct {
+	Prio int
+	ID   int
+}
@@ -0,0 +1,96 @@
+package itemheap_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/gammazero/heap"
+	"github.com/gammazero/heap/cmd/heapgen/internal/itemheap"
+)
+
+func lessItem(a, b itemheap.Item) bool {
+	return a.Prio < b.Prio
+}
+
+func TestPopOrder(t *testing.T) {
+	items := make([]itemheap.Item, 500)
+	for i := range items {
+		items[i] = itemheap.Item{Prio: rand.Intn(100), ID: i}
+	}
+	gen := itemheap.NewItemHeap(append([]itemheap.Item(nil), items...)...)
+	ref := heap.NewFrom(lessItem, append([]itemheap.Item(nil), items...)...)
+	for i := range 500 {
+		x := itemheap.Item{Prio: rand.Intn(100), ID: 500 + i}
+		gen.Push(x)
+		ref.Push(x)
+		if i%5 == 0 {
+			// Elements with equal priorities may be at different indexes
+			// in the two heaps, so remove the minimum from both.
+			gen.Remove(0)
+			ref.Remove(0)
+		}
+	}
+	if gen.Len() != ref.Len() {
+		t.Fatalf("expected %d elements, got %d", ref.Len(), gen.Len())
+	}
+	for ref.Len() != 0 {
+		if g, r := gen.Pop().Prio, ref.Pop().Prio; g != r {
+			t.Fatalf("generated heap popped %d, generic heap popped %d", g, r)
+		}
+	}
+}
+
+func TestRemove(t *testing.T) {
+	h := itemheap.NewItemHeap()
+	for i := range 100 {
+		h.Push(itemheap.Item{Prio: rand.Intn(1000), ID: i})
+	}
+	for range 50 {
+		i := rand.Intn(h.Len())
+		x := h.Remove(i)
+		x.Prio = rand.Intn(1000)
+		h.Push(x)
+	}
+	prev := h.Pop()
+	for h.Len() != 0 {
+		x := h.Pop()
+		if x.Prio < prev.Prio {
+			t.Fatalf("popped %d after %d", x.Prio, prev.Prio)
+		}
+		prev = x
+	}
+}
+
+func benchItems() []itemheap.Item {
+	items := make([]itemheap.Item, 1024)
+	for i := range items {
+		items[i] = itemheap.Item{Prio: rand.Int(), ID: i}
+	}
+	return items
+}
+
+func BenchmarkGenerated(b *testing.B) {
+	items := benchItems()
+	h := itemheap.NewItemHeap()
+	for b.Loop() {
+		for _, x := range items {
+			h.Push(x)
+		}
+		for h.Len() != 0 {
+			h.Pop()
+		}
+	}
+}
+
+func BenchmarkGeneric(b *testing.B) {
+	items := benchItems()
+	h := heap.New(lessItem)
+	for b.Loop() {
+		for _, x := range items {
+			h.Push(x)
+		}
+		for h.Len() != 0 {
+			h.Pop()
+		}
+	}
+}
@@ -0,0 +1,227 @@
+// Command heapgen generates a heap for a specific element type, with the
+// comparison written inline. A generated heap avoids calling a less function
+// through a func value for each comparison, which the compiler cannot inline
+// for a generic Heap.
+//
+// Usage:
+//
+//	heapgen -type T -name Name (-less expr | -field F) [-max] [-package pkg] [-o file]
+//
+// The -less flag gives a Go expression that reports whether a is less than b,
+// where a and b are of type T, such as "a.Deadline.Before(b.Deadline)". The
+// -field flag orders elements by a field of an ordered type instead, and -max
+// reverses the order of -field so that the largest value is removed first.
+//
+// For example, to generate a heap of Job ordered by the Prio field:
+//
+//	//go:generate heapgen -type Job -name JobHeap -field Prio
+//
+// The generated type has the methods Len, Push, Pop, Peek, At, Remove, and
+// Fix, which behave as the methods of the same name of heap.Heap.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"os"
+	"strings"
+	"text/template"
+)
+
+type config struct {
+	Package string
+	Type    string
+	Name    string
+	Less    string
+}
+
+func main() {
+	var cfg config
+	var field, out string
+	var maxHeap bool
+	flag.StringVar(&cfg.Type, "type", "", "element type")
+	flag.StringVar(&cfg.Name, "name", "", "name of the generated heap type")
+	flag.StringVar(&cfg.Less, "less", "", "expression that reports whether a is less than b")
+	flag.StringVar(&field, "field", "", "field of the element type to order by")
+	flag.BoolVar(&maxHeap, "max", false, "remove the element with the largest field first")
+	flag.StringVar(&cfg.Package, "package", os.Getenv("GOPACKAGE"), "package name of the generated file")
+	flag.StringVar(&out, "o", "", "output file; default is <name>_heap.go")
+	flag.Parse()
+
+	if field != "" {
+		if cfg.Less != "" {
+			fatal(errors.New("-less and -field cannot both be given"))
+		}
+		cfg.Less = fieldLess(field, maxHeap)
+	}
+	src, err := generate(cfg)
+	if err != nil {
+		fatal(err)
+	}
+	if out == "" {
+		out = strings.ToLower(cfg.Name) + "_heap.go"
+	}
+	if err = os.WriteFile(out, src, 0o644); err != nil {
+		fatal(err)
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "heapgen:", err)
+	os.Exit(1)
+}
+
+// fieldLess returns a less expression that compares a field.
+func fieldLess(field string, maxHeap bool) string {
+	if maxHeap {
+		return fmt.Sprintf("a.%s > b.%s", field, field)
+	}
+	return fmt.Sprintf("a.%s < b.%s", field, field)
+}
+
+// generate returns the formatted source of a heap type.
+func generate(cfg config) ([]byte, error) {
+	switch {
+	case cfg.Package == "":
+		return nil, errors.New("missing package name")
+	case cfg.Type == "":
+		return nil, errors.New("missing element type")
+	case cfg.Name == "":
+		return nil, errors.New("missing heap type name")
+	case cfg.Less == "":
+		return nil, errors.New("missing less expression or field")
+	}
+	if _, err := parser.ParseExpr(cfg.Less); err != nil {
+		return nil, fmt.Errorf("invalid less expression: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, cfg); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+var tmpl = template.Must(template.New("heap").Parse(`// Code generated by heapgen; DO NOT EDIT.
+
+package {{.Package}}
+
+// {{.Name}} is a binary heap of {{.Type}}.
+type {{.Name}} struct {
+	data []{{.Type}}
+}
+
+// New{{.Name}} returns a new {{.Name}} holding the given elements.
+func New{{.Name}}(data ...{{.Type}}) *{{.Name}} {
+	h := &{{.Name}}{data: data}
+	for i := len(data)/2 - 1; i >= 0; i-- {
+		h.down(i)
+	}
+	return h
+}
+
+func (h *{{.Name}}) less(a, b {{.Type}}) bool {
+	return {{.Less}}
+}
+
+// Len returns the number of elements in the heap.
+func (h *{{.Name}}) Len() int {
+	return len(h.data)
+}
+
+// Push pushes the given element onto the heap.
+func (h *{{.Name}}) Push(x {{.Type}}) {
+	h.data = append(h.data, x)
+	h.up(len(h.data) - 1)
+}
+
+// Pop removes and returns the minimum element from the heap.
+func (h *{{.Name}}) Pop() {{.Type}} {
+	if len(h.data) == 0 {
+		panic("{{.Name}}: Pop called on empty heap")
+	}
+	var zero {{.Type}}
+	x := h.data[0]
+	n := len(h.data) - 1
+	h.data[0] = h.data[n]
+	h.data[n] = zero
+	h.data = h.data[:n]
+	h.down(0)
+	return x
+}
+
+// Peek returns the minimum element from the heap without removing it.
+func (h *{{.Name}}) Peek() {{.Type}} {
+	if len(h.data) == 0 {
+		panic("{{.Name}}: Peek called on empty heap")
+	}
+	return h.data[0]
+}
+
+// At returns the element at index i from the heap.
+func (h *{{.Name}}) At(i int) {{.Type}} {
+	return h.data[i]
+}
+
+// Remove removes and returns the element at index i from the heap.
+func (h *{{.Name}}) Remove(i int) {{.Type}} {
+	var zero {{.Type}}
+	x := h.data[i]
+	n := len(h.data) - 1
+	if n != i {
+		h.data[i] = h.data[n]
+		h.data[n] = zero
+		h.data = h.data[:n]
+		h.Fix(i)
+	} else {
+		h.data[n] = zero
+		h.data = h.data[:n]
+	}
+	return x
+}
+
+// Fix re-establishes the heap ordering after the element at index i has
+// changed its value.
+func (h *{{.Name}}) Fix(i int) {
+	if !h.down(i) {
+		h.up(i)
+	}
+}
+
+func (h *{{.Name}}) down(i int) bool {
+	data := h.data
+	n := len(data)
+	i0 := i
+	for {
+		left := 2*i + 1
+		if left >= n || left < 0 {
+			break
+		}
+		j := left
+		if right := left + 1; right < n && h.less(data[right], data[left]) {
+			j = right
+		}
+		if !h.less(data[j], data[i]) {
+			break
+		}
+		data[i], data[j] = data[j], data[i]
+		i = j
+	}
+	return i > i0
+}
+
+func (h *{{.Name}}) up(i int) {
+	data := h.data
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !h.less(data[i], data[parent]) {
+			break
+		}
+		data[i], data[parent] = data[parent], data[i]
+		i = parent
+	}
+}
+`))
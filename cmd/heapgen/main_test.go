@@ -0,0 +1,114 @@
+package main
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"strings"
+	"testing"
+)
+
+// typeCheck parses and type-checks generated source along with extra
+// declarations.
+func typeCheck(t *testing.T, src []byte, extra string) {
+	t.Helper()
+	fset := token.NewFileSet()
+	gen, err := parser.ParseFile(fset, "gen.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decls, err := parser.ParseFile(fset, "decls.go", "package q\n"+extra, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := types.Config{Importer: importer.Default()}
+	if _, err = conf.Check("q", fset, []*ast.File{gen, decls}, nil); err != nil {
+		t.Fatalf("generated code does not type-check: %v\n%s", err, src)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	src, err := generate(config{
+		Package: "q",
+		Type:    "Job",
+		Name:    "JobHeap",
+		Less:    fieldLess("Prio", false),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(src), "return a.Prio < b.Prio") {
+		t.Fatal("less expression not in generated code")
+	}
+	typeCheck(t, src, `type Job struct{ Prio int }
+
+func use() {
+	h := NewJobHeap(Job{3}, Job{1})
+	h.Push(Job{2})
+	h.Fix(0)
+	_ = h.Remove(h.Len() - 1)
+	_ = h.At(0)
+	_ = h.Peek()
+	_ = h.Pop()
+}`)
+
+	src, err = generate(config{
+		Package: "q",
+		Type:    "*Event",
+		Name:    "eventHeap",
+		Less:    "a.At.Before(b.At)",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	typeCheck(t, src, `import "time"
+
+type Event struct{ At time.Time }`)
+}
+
+func TestGenerateErrors(t *testing.T) {
+	valid := config{Package: "q", Type: "int", Name: "IntHeap", Less: "a < b"}
+	for _, mod := range []func(*config){
+		func(c *config) { c.Package = "" },
+		func(c *config) { c.Type = "" },
+		func(c *config) { c.Name = "" },
+		func(c *config) { c.Less = "" },
+		func(c *config) { c.Less = "a <" },
+	} {
+		cfg := valid
+		mod(&cfg)
+		if _, err := generate(cfg); err == nil {
+			t.Fatalf("expected error for %+v", cfg)
+		}
+	}
+	if _, err := generate(valid); err != nil {
+		t.Fatal(err)
+	}
+	if fieldLess("Prio", true) != "a.Prio > b.Prio" {
+		t.Fatal("wrong max-heap field expression")
+	}
+}
+
+func TestGeneratedUpToDate(t *testing.T) {
+	// The generated heap in internal/itemheap is compiled and tested against
+	// heap.Heap there, so it must match the current output of heapgen.
+	src, err := generate(config{
+		Package: "itemheap",
+		Type:    "Item",
+		Name:    "ItemHeap",
+		Less:    fieldLess("Prio", false),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile("internal/itemheap/itemheap.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(src) != string(want) {
+		t.Fatal("internal/itemheap/itemheap.go is out of date; run go generate")
+	}
+}
@@ -0,0 +1,149 @@
+// Package decay provides a priority queue of elements whose scores decay over
+// time, such as relevance or freshness scores.
+//
+// The queue compares elements by their decayed scores, which it computes only
+// when elements are compared, so nothing needs to be re-heapified as time
+// passes. This requires that decay never changes the relative order of two
+// elements. Exponential decay, in which every score shrinks by the same factor
+// over the same interval, has this property, and is provided by New. Other
+// decay functions that preserve order can be given to NewFunc.
+package decay
+
+import (
+	"math"
+	"time"
+
+	"github.com/gammazero/heap"
+)
+
+// Queue is a priority queue that removes the element with the highest decayed
+// score first. It is not safe for concurrent use.
+type Queue[T any] struct {
+	h      *heap.Heap[entry[T]]
+	lambda float64 // decay constant per second
+	epoch  time.Time
+	seq    uint64
+	decay  Func
+	clock  func() time.Time
+	now    time.Time // time at which elements are compared
+}
+
+type entry[T any] struct {
+	val   T
+	key   float64 // log of the score projected to epoch
+	score float64
+	at    time.Time
+	seq   uint64
+}
+
+// Func returns the value of a score after it has decayed for the given age.
+type Func func(score float64, age time.Duration) float64
+
+// New returns a new Queue in which scores decay to half their value every
+// halfLife.
+func New[T any](halfLife time.Duration) *Queue[T] {
+	if halfLife <= 0 {
+		panic("decay: half-life must be positive")
+	}
+	// Every score decays by the same factor, so elements are ordered by
+	// their scores projected onto a fixed reference time, and the decayed
+	// score is only computed when it is asked for.
+	return &Queue[T]{
+		h: heap.New(func(a, b entry[T]) bool {
+			if a.key != b.key {
+				return a.key > b.key
+			}
+			return a.seq < b.seq
+		}),
+		lambda: math.Ln2 / halfLife.Seconds(),
+		epoch:  time.Now(),
+	}
+}
+
+// NewFunc returns a new Queue in which scores decay as computed by decay.
+// Elements are compared by their scores decayed to the time returned by
+// clock, which is read once for each operation that compares elements. If
+// clock is nil, time.Now is used.
+//
+// The decay function must preserve order: if the decayed score of one element
+// is greater than that of another at some time, it must be greater at every
+// later time, and decayed scores that are equal must stay equal. Exponential
+// decay, and linear decay that subtracts the same amount from every score
+// over the same interval, preserve order. Decay that stops at a floor, such
+// as a linear decay that stops at zero, does not, since it makes unequal
+// scores equal. If decay does not preserve order, elements are removed in the
+// wrong order.
+func NewFunc[T any](decay Func, clock func() time.Time) *Queue[T] {
+	if decay == nil {
+		panic("decay: nil decay function")
+	}
+	if clock == nil {
+		clock = time.Now
+	}
+	q := &Queue[T]{
+		decay: decay,
+		clock: clock,
+	}
+	q.h = heap.New(func(a, b entry[T]) bool {
+		sa, sb := q.decayed(a, q.now), q.decayed(b, q.now)
+		if sa != sb {
+			return sa > sb
+		}
+		return a.seq < b.seq
+	})
+	return q
+}
+
+// decayed returns the score of e decayed to time now.
+func (q *Queue[T]) decayed(e entry[T], now time.Time) float64 {
+	if q.decay == nil {
+		return math.Exp(e.key - q.lambda*now.Sub(q.epoch).Seconds())
+	}
+	return q.decay(e.score, now.Sub(e.at))
+}
+
+// tick reads the clock before an operation that compares elements.
+func (q *Queue[T]) tick() {
+	if q.clock != nil {
+		q.now = q.clock()
+	}
+}
+
+// Len returns the number of elements in the queue.
+func (q *Queue[T]) Len() int {
+	return q.h.Len()
+}
+
+// Push adds an element that had the given score at time at. The score must not
+// be negative.
+func (q *Queue[T]) Push(x T, score float64, at time.Time) {
+	if score < 0 || math.IsNaN(score) {
+		panic("decay: score must not be negative")
+	}
+	q.seq++
+	e := entry[T]{val: x, seq: q.seq}
+	if q.decay == nil {
+		e.key = math.Log(score) + q.lambda*at.Sub(q.epoch).Seconds()
+	} else {
+		e.score, e.at = score, at
+	}
+	q.tick()
+	q.h.Push(e)
+}
+
+// Peek returns the element with the highest decayed score without removing it.
+func (q *Queue[T]) Peek() T {
+	return q.h.Peek().val
+}
+
+// Pop removes and returns the element with the highest decayed score.
+func (q *Queue[T]) Pop() T {
+	q.tick()
+	return q.h.Pop().val
+}
+
+// Score returns the decayed score, at time now, of the element that Peek
+// returns.
+func (q *Queue[T]) Score(now time.Time) float64 {
+	return q.decayed(q.h.Peek(), now)
+}
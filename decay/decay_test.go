@@ -0,0 +1,85 @@
+package decay_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/gammazero/heap/decay"
+)
+
+func TestDecay(t *testing.T) {
+	q := decay.New[string](time.Hour)
+	start := time.Now()
+
+	// Score 8 seen two hours ago has decayed to 2 by start+2h, which is less
+	// than score 3 seen at start+2h.
+	q.Push("stale", 8, start)
+	q.Push("fresh", 3, start.Add(2*time.Hour))
+	q.Push("zero", 0, start.Add(2*time.Hour))
+
+	now := start.Add(2 * time.Hour)
+	if q.Peek() != "fresh" {
+		t.Fatalf("expected fresh at head, got %s", q.Peek())
+	}
+	if s := q.Score(now); math.Abs(s-3) > 1e-9 {
+		t.Fatalf("expected score 3, got %f", s)
+	}
+	q.Pop()
+	if s := q.Score(now); math.Abs(s-2) > 1e-9 {
+		t.Fatalf("expected score 2, got %f", s)
+	}
+	if q.Pop() != "stale" || q.Pop() != "zero" {
+		t.Fatal("unexpected order")
+	}
+	if q.Len() != 0 {
+		t.Fatalf("expected empty queue, got length %d", q.Len())
+	}
+}
+
+func TestNegativeScorePanics(t *testing.T) {
+	q := decay.New[int](time.Minute)
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for negative score")
+		}
+	}()
+	q.Push(1, -1, time.Now())
+}
+
+func TestDecayFunc(t *testing.T) {
+	// Linear decay of one point per hour preserves order.
+	linear := func(score float64, age time.Duration) float64 {
+		return score - age.Hours()
+	}
+	start := time.Now()
+	now := start
+	var reads int
+	q := decay.NewFunc[string](linear, func() time.Time {
+		reads++
+		return now
+	})
+
+	q.Push("old", 10, start)
+	q.Push("low", 5, start)
+	now = start.Add(2 * time.Hour)
+	// Score 9 seen at start+2h is greater than score 10 seen two hours
+	// earlier, which has decayed to 8.
+	q.Push("new", 9, now)
+	if reads == 0 {
+		t.Fatal("expected clock to be read")
+	}
+
+	now = start.Add(3 * time.Hour)
+	if q.Peek() != "new" {
+		t.Fatalf("expected new at head, got %s", q.Peek())
+	}
+	if s := q.Score(now); s != 8 {
+		t.Fatalf("expected score 8, got %f", s)
+	}
+	for _, want := range []string{"new", "old", "low"} {
+		if x := q.Pop(); x != want {
+			t.Fatalf("expected %s, got %s", want, x)
+		}
+	}
+}
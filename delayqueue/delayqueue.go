@@ -0,0 +1,129 @@
+// Package delayqueue provides a queue that releases values only once the
+// time given for each value has arrived.
+//
+// A single goroutine owns the queue's heap and one timer. The timer is only
+// re-armed when the earliest ready time changes, such as when a value with an
+// earlier ready time is offered, so offering many values does not cause timer
+// churn.
+package delayqueue
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gammazero/heap"
+)
+
+// DelayQueue holds values until their ready times arrive, and then delivers
+// them in ready-time order. It is safe for concurrent use.
+type DelayQueue[T any] struct {
+	in        chan item[T]
+	out       chan T
+	done      chan struct{}
+	closeOnce sync.Once
+	length    atomic.Int64
+}
+
+type item[T any] struct {
+	val T
+	at  time.Time
+	seq uint64
+}
+
+// New creates a new DelayQueue.
+func New[T any]() *DelayQueue[T] {
+	q := &DelayQueue[T]{
+		in:   make(chan item[T]),
+		out:  make(chan T),
+		done: make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+// Offer adds a value to the queue that is released no earlier than readyAt.
+// Values with the same ready time are released in the order they were offered.
+// Offer does nothing if the queue is closed.
+func (q *DelayQueue[T]) Offer(x T, readyAt time.Time) {
+	q.length.Add(1)
+	select {
+	case q.in <- item[T]{val: x, at: readyAt}:
+	case <-q.done:
+		q.length.Add(-1)
+	}
+}
+
+// Recv returns the channel from which values are received once they are
+// ready. The channel is closed when the queue is closed.
+func (q *DelayQueue[T]) Recv() <-chan T {
+	return q.out
+}
+
+// Len returns the number of values that have been offered but not received.
+func (q *DelayQueue[T]) Len() int {
+	return int(q.length.Load())
+}
+
+// Close stops the queue, discarding any values that have not been received,
+// and closes the channel returned by Recv.
+func (q *DelayQueue[T]) Close() {
+	q.closeOnce.Do(func() {
+		close(q.done)
+	})
+}
+
+func (q *DelayQueue[T]) run() {
+	defer close(q.out)
+
+	h := heap.New(func(a, b item[T]) bool {
+		if !a.at.Equal(b.at) {
+			return a.at.Before(b.at)
+		}
+		return a.seq < b.seq
+	})
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+	defer timer.Stop()
+
+	var seq uint64
+	var armed time.Time // deadline the timer is set for, zero if stopped
+	for {
+		var out chan T
+		var head T
+		var wait <-chan time.Time
+		if h.Len() == 0 {
+			if !armed.IsZero() {
+				timer.Stop()
+				armed = time.Time{}
+			}
+		} else {
+			it := h.Peek()
+			if d := time.Until(it.at); d > 0 {
+				if !it.at.Equal(armed) {
+					timer.Reset(d)
+					armed = it.at
+				}
+				wait = timer.C
+			} else {
+				out = q.out
+				head = it.val
+			}
+		}
+
+		select {
+		case it := <-q.in:
+			seq++
+			it.seq = seq
+			h.Push(it)
+		case <-wait:
+			armed = time.Time{}
+		case out <- head:
+			h.Pop()
+			q.length.Add(-1)
+		case <-q.done:
+			q.length.Add(-int64(h.Len()))
+			return
+		}
+	}
+}
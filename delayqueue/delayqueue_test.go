@@ -0,0 +1,104 @@
+package delayqueue_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gammazero/heap/delayqueue"
+)
+
+// waitLen waits for the length of a queue to become want. The length is
+// updated just after a value is received, so it may not be updated yet when
+// the receiver checks it.
+func waitLen(t *testing.T, length func() int, want int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for length() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected length %d, got %d", want, length())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestReadyOrder(t *testing.T) {
+	q := delayqueue.New[string]()
+	defer q.Close()
+
+	start := time.Now()
+	q.Offer("c", start.Add(30*time.Millisecond))
+	q.Offer("a", start.Add(10*time.Millisecond))
+	q.Offer("b", start.Add(20*time.Millisecond))
+	q.Offer("now", start)
+	if q.Len() != 4 {
+		t.Fatalf("expected length 4, got %d", q.Len())
+	}
+
+	var got []string
+	for range 4 {
+		got = append(got, <-q.Recv())
+	}
+	if fmt.Sprint(got) != "[now a b c]" {
+		t.Fatalf("unexpected order %v", got)
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Fatalf("values released too early, after %s", elapsed)
+	}
+	waitLen(t, q.Len, 0)
+}
+
+func TestEarlierOfferRearms(t *testing.T) {
+	q := delayqueue.New[int]()
+	defer q.Close()
+
+	start := time.Now()
+	q.Offer(2, start.Add(time.Hour))
+	q.Offer(1, start.Add(10*time.Millisecond))
+
+	select {
+	case x := <-q.Recv():
+		if x != 1 {
+			t.Fatalf("expected 1, got %d", x)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for earlier value")
+	}
+	waitLen(t, q.Len, 1)
+}
+
+func TestNotReady(t *testing.T) {
+	q := delayqueue.New[int]()
+	q.Offer(1, time.Now().Add(time.Hour))
+	select {
+	case x := <-q.Recv():
+		t.Fatalf("received %d before it was ready", x)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	q.Close()
+	if _, ok := <-q.Recv(); ok {
+		t.Fatal("expected closed channel")
+	}
+	q.Offer(2, time.Now())
+	if q.Len() != 0 {
+		t.Fatalf("expected length 0 after close, got %d", q.Len())
+	}
+	q.Close()
+}
+
+func Example() {
+	q := delayqueue.New[string]()
+	defer q.Close()
+
+	now := time.Now()
+	q.Offer("world", now.Add(20*time.Millisecond))
+	q.Offer("hello", now.Add(10*time.Millisecond))
+
+	fmt.Println(<-q.Recv())
+	fmt.Println(<-q.Recv())
+
+	// Output:
+	// hello
+	// world
+}
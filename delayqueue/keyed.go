@@ -0,0 +1,156 @@
+package delayqueue
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gammazero/heap"
+)
+
+// Mode determines how a Keyed queue handles a value offered with a key that
+// is already waiting in the queue.
+type Mode int
+
+const (
+	// Debounce moves the key's ready time to the ready time of the new
+	// offer, so a key that keeps being offered is not released until offers
+	// stop.
+	Debounce Mode = iota
+	// Throttle keeps the earlier of the key's current and new ready times,
+	// so a key that keeps being offered is still released on schedule.
+	Throttle
+)
+
+// Keyed is a delay queue that holds at most one value per key. Offering a
+// value for a key that is already waiting replaces the waiting value, and
+// adjusts its ready time according to the queue's Mode. This coalesces bursts
+// of events, such as file change notifications, into a single value. It is
+// safe for concurrent use.
+type Keyed[K comparable, V any] struct {
+	mode      Mode
+	in        chan keyedItem[K, V]
+	out       chan V
+	done      chan struct{}
+	closeOnce sync.Once
+	length    atomic.Int64
+}
+
+type keyedItem[K comparable, V any] struct {
+	key K
+	val V
+	at  time.Time
+	seq uint64
+	pos int
+}
+
+// NewKeyed creates a new Keyed queue with the given mode.
+func NewKeyed[K comparable, V any](mode Mode) *Keyed[K, V] {
+	q := &Keyed[K, V]{
+		mode: mode,
+		in:   make(chan keyedItem[K, V]),
+		out:  make(chan V),
+		done: make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+// Offer adds a value for the key that is released no earlier than readyAt. If
+// a value for the key is already waiting, it is replaced by this value. Offer
+// does nothing if the queue is closed.
+func (q *Keyed[K, V]) Offer(key K, val V, readyAt time.Time) {
+	select {
+	case q.in <- keyedItem[K, V]{key: key, val: val, at: readyAt}:
+	case <-q.done:
+	}
+}
+
+// Recv returns the channel from which values are received once they are
+// ready. The channel is closed when the queue is closed.
+func (q *Keyed[K, V]) Recv() <-chan V {
+	return q.out
+}
+
+// Len returns the number of keys waiting in the queue.
+func (q *Keyed[K, V]) Len() int {
+	return int(q.length.Load())
+}
+
+// Close stops the queue, discarding any values that have not been received,
+// and closes the channel returned by Recv.
+func (q *Keyed[K, V]) Close() {
+	q.closeOnce.Do(func() {
+		close(q.done)
+	})
+}
+
+func (q *Keyed[K, V]) run() {
+	defer close(q.out)
+
+	h := heap.New(func(a, b *keyedItem[K, V]) bool {
+		if !a.at.Equal(b.at) {
+			return a.at.Before(b.at)
+		}
+		return a.seq < b.seq
+	})
+	h.SetOnMove(func(it *keyedItem[K, V], i int) {
+		it.pos = i
+	})
+	index := make(map[K]*keyedItem[K, V])
+
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+	defer timer.Stop()
+
+	var seq uint64
+	var armed time.Time
+	for {
+		var out chan V
+		var head V
+		var wait <-chan time.Time
+		if h.Len() == 0 {
+			if !armed.IsZero() {
+				timer.Stop()
+				armed = time.Time{}
+			}
+		} else {
+			it := h.Peek()
+			if d := time.Until(it.at); d > 0 {
+				if !it.at.Equal(armed) {
+					timer.Reset(d)
+					armed = it.at
+				}
+				wait = timer.C
+			} else {
+				out = q.out
+				head = it.val
+			}
+		}
+
+		select {
+		case it := <-q.in:
+			if cur, ok := index[it.key]; ok {
+				cur.val = it.val
+				if q.mode == Debounce || it.at.Before(cur.at) {
+					cur.at = it.at
+					h.Fix(cur.pos)
+				}
+				break
+			}
+			seq++
+			it.seq = seq
+			index[it.key] = &it
+			h.Push(&it)
+			q.length.Store(int64(h.Len()))
+		case <-wait:
+			armed = time.Time{}
+		case out <- head:
+			delete(index, h.Pop().key)
+			q.length.Store(int64(h.Len()))
+		case <-q.done:
+			q.length.Store(0)
+			return
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package delayqueue_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gammazero/heap/delayqueue"
+)
+
+func TestDebounce(t *testing.T) {
+	q := delayqueue.NewKeyed[string, string](delayqueue.Debounce)
+	defer q.Close()
+
+	start := time.Now()
+	q.Offer("config", "v1", start.Add(20*time.Millisecond))
+	q.Offer("other", "other", start.Add(40*time.Millisecond))
+	q.Offer("config", "v2", start.Add(60*time.Millisecond))
+	time.Sleep(time.Millisecond)
+	if q.Len() != 2 {
+		t.Fatalf("expected 2 keys, got %d", q.Len())
+	}
+
+	if v := <-q.Recv(); v != "other" {
+		t.Fatalf("expected other, got %s", v)
+	}
+	if v := <-q.Recv(); v != "v2" {
+		t.Fatalf("expected v2, got %s", v)
+	}
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Fatalf("debounced value released too early, after %s", elapsed)
+	}
+	waitLen(t, q.Len, 0)
+
+	// A delivered key can be offered again.
+	q.Offer("config", "v3", time.Now())
+	if v := <-q.Recv(); v != "v3" {
+		t.Fatalf("expected v3, got %s", v)
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	q := delayqueue.NewKeyed[string, int](delayqueue.Throttle)
+	defer q.Close()
+
+	start := time.Now()
+	q.Offer("k", 1, start.Add(20*time.Millisecond))
+	q.Offer("k", 2, start.Add(time.Hour))
+	q.Offer("k", 3, start.Add(time.Hour))
+
+	select {
+	case v := <-q.Recv():
+		if v != 3 {
+			t.Fatalf("expected latest value 3, got %d", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("throttled value not released at earliest time")
+	}
+
+	q.Offer("k", 4, start.Add(time.Hour))
+	q.Offer("k", 5, time.Now())
+	select {
+	case v := <-q.Recv():
+		if v != 5 {
+			t.Fatalf("expected 5, got %d", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("earlier offer did not move ready time")
+	}
+}
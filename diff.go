@@ -0,0 +1,103 @@
+package heap
+
+import "slices"
+
+// DiffResult holds the differences between two heaps found by Diff. Each
+// slice is sorted from minimum to maximum.
+type DiffResult[T any] struct {
+	// OnlyA holds the elements whose keys are only in the first heap.
+	OnlyA []T
+	// OnlyB holds the elements whose keys are only in the second heap.
+	OnlyB []T
+	// Changed holds the pairs of elements with the same key whose
+	// priorities differ.
+	Changed []Change[T]
+}
+
+// Change is a pair of elements with the same key and different priorities.
+type Change[T any] struct {
+	A, B T
+}
+
+// Equal returns true if no differences were found.
+func (d DiffResult[T]) Equal() bool {
+	return len(d.OnlyA) == 0 && len(d.OnlyB) == 0 && len(d.Changed) == 0
+}
+
+// Diff compares the elements of two heaps, matching elements by the key
+// returned by key, such as an ID. Matched elements have different priorities
+// if one is less than the other according to the less function of heap a.
+// This can verify that a heap restored from a snapshot or write-ahead log, or
+// kept by a replica, holds the same elements as the original, without
+// draining either heap. The heaps are not modified.
+//
+// If a heap holds more than one element with the same key, the elements with
+// that key are matched in the order they are stored in each heap, and any
+// left over are reported as only in that heap.
+//
+// Diff takes O(n log n) time to sort the differences, and O(n) time and space
+// otherwise.
+func Diff[T any, K comparable](a, b *Heap[T], key func(T) K) DiffResult[T] {
+	if a.guard != nil {
+		a.checkRead()
+	}
+	if b.guard != nil {
+		b.checkRead()
+	}
+	a.mustHaveLess()
+	less := a.less
+
+	inB := make(map[K][]T, len(b.data))
+	for _, x := range b.data {
+		k := key(x)
+		inB[k] = append(inB[k], x)
+	}
+	var d DiffResult[T]
+	for _, x := range a.data {
+		k := key(x)
+		ys := inB[k]
+		if len(ys) == 0 {
+			d.OnlyA = append(d.OnlyA, x)
+			continue
+		}
+		y := ys[0]
+		if len(ys) == 1 {
+			delete(inB, k)
+		} else {
+			inB[k] = ys[1:]
+		}
+		if less(x, y) || less(y, x) {
+			d.Changed = append(d.Changed, Change[T]{x, y})
+		}
+	}
+	// Collect the unmatched elements of b in the order they are stored.
+	for _, y := range b.data {
+		k := key(y)
+		ys := inB[k]
+		if len(ys) == 0 {
+			continue
+		}
+		d.OnlyB = append(d.OnlyB, ys[0])
+		if len(ys) == 1 {
+			delete(inB, k)
+		} else {
+			inB[k] = ys[1:]
+		}
+	}
+
+	cmp := func(x, y T) int {
+		switch {
+		case less(x, y):
+			return -1
+		case less(y, x):
+			return 1
+		}
+		return 0
+	}
+	slices.SortStableFunc(d.OnlyA, cmp)
+	slices.SortStableFunc(d.OnlyB, cmp)
+	slices.SortStableFunc(d.Changed, func(x, y Change[T]) int {
+		return cmp(x.A, y.A)
+	})
+	return d
+}
@@ -0,0 +1,41 @@
+package heap_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/gammazero/heap"
+)
+
+func TestDiff(t *testing.T) {
+	type job struct {
+		id  string
+		pri int
+	}
+	less := func(a, b job) bool { return a.pri < b.pri }
+	id := func(j job) string { return j.id }
+
+	a := heap.NewFrom(less, job{"a", 1}, job{"b", 2}, job{"c", 3}, job{"d", 4}, job{"dup", 5}, job{"dup", 5})
+	b := heap.NewFrom(less, job{"e", 0}, job{"d", 4}, job{"c", 7}, job{"a", 1}, job{"dup", 5})
+
+	d := heap.Diff(a, b, id)
+	if d.Equal() {
+		t.Fatal("expected differences")
+	}
+	if !slices.Equal(d.OnlyA, []job{{"b", 2}, {"dup", 5}}) {
+		t.Errorf("wrong elements only in a: %v", d.OnlyA)
+	}
+	if !slices.Equal(d.OnlyB, []job{{"e", 0}}) {
+		t.Errorf("wrong elements only in b: %v", d.OnlyB)
+	}
+	if !slices.Equal(d.Changed, []heap.Change[job]{{job{"c", 3}, job{"c", 7}}}) {
+		t.Errorf("wrong changed elements: %v", d.Changed)
+	}
+	if a.Len() != 6 || b.Len() != 5 {
+		t.Fatal("Diff modified the heaps")
+	}
+
+	if d = heap.Diff(a, a, id); !d.Equal() {
+		t.Fatalf("expected no differences between a heap and itself: %+v", d)
+	}
+}
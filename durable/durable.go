@@ -0,0 +1,124 @@
+// Package durable provides a persistent priority queue. The queue keeps its
+// elements in a heap in memory, and stores them using a Storage so that the
+// queue can be restored after a restart.
+//
+// A Storage only needs to store entries in the order they were added and
+// remember which have been consumed; ordering by priority is done by the
+// heap. Storage can be implemented on top of a file, as FileStorage is, or a
+// database.
+package durable
+
+import (
+	"iter"
+
+	"github.com/gammazero/heap"
+)
+
+// ID identifies an entry in a Storage.
+type ID uint64
+
+// Entry is an entry stored in a Storage.
+type Entry struct {
+	ID   ID
+	Data []byte
+}
+
+// Storage stores the entries of a Queue.
+type Storage interface {
+	// Append stores an entry and returns its ID. IDs increase with each
+	// entry appended.
+	Append(data []byte) (ID, error)
+	// Consume marks the entry with the given ID as consumed. Consumed
+	// entries are not returned by Entries. Consume must only return an
+	// error if the entry was not marked as consumed.
+	Consume(id ID) error
+	// Entries returns an iterator over the entries that have not been
+	// consumed, in the order they were appended.
+	Entries() iter.Seq2[Entry, error]
+}
+
+// Queue is a priority queue whose elements are stored in a Storage. It is not
+// safe for concurrent use.
+type Queue[T any] struct {
+	s         Storage
+	h         *heap.Heap[item[T]]
+	marshal   func(T) ([]byte, error)
+	unmarshal func([]byte) (T, error)
+}
+
+type item[T any] struct {
+	val T
+	id  ID
+}
+
+// Open returns a Queue that stores its elements in s, loading any elements
+// that are already stored. Elements are ordered by less, and elements that
+// are equal are removed in the order they were pushed. Elements are converted
+// to and from stored entries by marshal and unmarshal.
+func Open[T any](s Storage, less func(a, b T) bool, marshal func(T) ([]byte, error), unmarshal func([]byte) (T, error)) (*Queue[T], error) {
+	// The stored elements are pushed one at a time rather than passed to
+	// heap.NewFrom, which may call less from several goroutines.
+	h := heap.New(func(a, b item[T]) bool {
+		if less(a.val, b.val) {
+			return true
+		}
+		if less(b.val, a.val) {
+			return false
+		}
+		return a.id < b.id
+	})
+	for e, err := range s.Entries() {
+		if err != nil {
+			return nil, err
+		}
+		x, err := unmarshal(e.Data)
+		if err != nil {
+			return nil, err
+		}
+		h.Push(item[T]{val: x, id: e.ID})
+	}
+	return &Queue[T]{
+		s:         s,
+		h:         h,
+		marshal:   marshal,
+		unmarshal: unmarshal,
+	}, nil
+}
+
+// Len returns the number of elements in the queue.
+func (q *Queue[T]) Len() int {
+	return q.h.Len()
+}
+
+// Push stores an element and adds it to the queue. If the element cannot be
+// stored, the queue is not modified.
+func (q *Queue[T]) Push(x T) error {
+	data, err := q.marshal(x)
+	if err != nil {
+		return err
+	}
+	id, err := q.s.Append(data)
+	if err != nil {
+		return err
+	}
+	q.h.Push(item[T]{val: x, id: id})
+	return nil
+}
+
+// Peek returns the minimum element in the queue without removing it.
+func (q *Queue[T]) Peek() T {
+	return q.h.Peek().val
+}
+
+// Pop marks the minimum element as consumed in storage and removes it from
+// the queue. If the element cannot be marked as consumed, the queue is not
+// modified.
+func (q *Queue[T]) Pop() (T, error) {
+	it := q.h.Peek()
+	if err := q.s.Consume(it.id); err != nil {
+		var zero T
+		return zero, err
+	}
+	q.h.Pop()
+	return it.val, nil
+}
@@ -0,0 +1,156 @@
+package durable_test
+
+import (
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gammazero/heap/durable"
+)
+
+func marshalInt(x int) ([]byte, error) {
+	return binary.AppendVarint(nil, int64(x)), nil
+}
+
+func unmarshalInt(b []byte) (int, error) {
+	x, n := binary.Varint(b)
+	if n <= 0 {
+		return 0, errors.New("bad int")
+	}
+	return int(x), nil
+}
+
+func TestQueueMemStorage(t *testing.T) {
+	s := durable.NewMemStorage()
+	q, err := durable.Open(s, cmp.Less[int], marshalInt, unmarshalInt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, x := range []int{5, 1, 4, 2, 3} {
+		if err = q.Push(x); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if x, _ := q.Pop(); x != 1 {
+		t.Fatalf("expected 1, got %d", x)
+	}
+
+	q, err = durable.Open(s, cmp.Less[int], marshalInt, unmarshalInt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Len() != 4 {
+		t.Fatalf("expected 4 elements after reopen, got %d", q.Len())
+	}
+	for i := 2; i <= 5; i++ {
+		if q.Peek() != i {
+			t.Fatalf("expected peek %d, got %d", i, q.Peek())
+		}
+		if x, _ := q.Pop(); x != i {
+			t.Fatalf("expected %d, got %d", i, x)
+		}
+	}
+}
+
+func TestQueueFileStorage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue")
+	s, err := durable.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := durable.Open(s, cmp.Less[int], marshalInt, unmarshalInt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Push and pop enough to cause compaction.
+	for i := range 3000 {
+		if err = q.Push(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for range 2900 {
+		if _, err = q.Pop(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a crash part way through writing a record.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = f.Write([]byte{1, 0x80}); err != nil {
+		t.Fatal(err)
+	}
+	if err = f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = durable.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := s.Close(); err != nil {
+			t.Error(err)
+		}
+	}()
+	if s.Truncated() != 2 {
+		t.Fatalf("expected 2 bytes truncated, got %d", s.Truncated())
+	}
+	q, err = durable.Open(s, cmp.Less[int], marshalInt, unmarshalInt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Len() != 100 {
+		t.Fatalf("expected 100 elements after reopen, got %d", q.Len())
+	}
+	if err := q.Push(-1); err != nil {
+		t.Fatal(err)
+	}
+	if x, _ := q.Pop(); x != -1 {
+		t.Fatalf("expected -1, got %d", x)
+	}
+	for i := 2900; i < 3000; i++ {
+		if x, _ := q.Pop(); x != i {
+			t.Fatalf("expected %d, got %d", i, x)
+		}
+	}
+}
+
+func TestFIFOForEqual(t *testing.T) {
+	type job struct {
+		prio int
+		name string
+	}
+	q, _ := durable.Open(durable.NewMemStorage(),
+		func(a, b job) bool { return a.prio < b.prio },
+		func(j job) ([]byte, error) { return append([]byte{byte(j.prio)}, j.name...), nil },
+		func(b []byte) (job, error) { return job{int(b[0]), string(b[1:])}, nil })
+	if err := q.Push(job{1, "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Push(job{1, "b"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Push(job{0, "c"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Push(job{1, "d"}); err != nil {
+		t.Fatal(err)
+	}
+	var names string
+	for q.Len() != 0 {
+		j, _ := q.Pop()
+		names += j.name
+	}
+	if names != "cabd" {
+		t.Fatalf("expected cabd, got %s", names)
+	}
+}
@@ -0,0 +1,392 @@
+package durable
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"iter"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+var (
+	// ErrNotFound is returned when consuming an entry that is not stored.
+	ErrNotFound = errors.New("durable: entry not found")
+	// ErrCorrupt is returned by OpenFile when a record in the storage file,
+	// other than a partially written last record, is damaged.
+	ErrCorrupt = errors.New("durable: corrupt storage file")
+)
+
+// MemStorage is a Storage that keeps entries in memory. It is useful for
+// testing.
+type MemStorage struct {
+	entries map[ID][]byte
+	next    ID
+}
+
+// NewMemStorage returns a new, empty MemStorage.
+func NewMemStorage() *MemStorage {
+	return &MemStorage{entries: map[ID][]byte{}}
+}
+
+// Append stores a copy of data and returns its ID.
+func (s *MemStorage) Append(data []byte) (ID, error) {
+	s.next++
+	s.entries[s.next] = slices.Clone(data)
+	return s.next, nil
+}
+
+// Consume removes the entry with the given ID.
+func (s *MemStorage) Consume(id ID) error {
+	if _, ok := s.entries[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.entries, id)
+	return nil
+}
+
+// Entries returns an iterator over the stored entries in the order they were
+// appended.
+func (s *MemStorage) Entries() iter.Seq2[Entry, error] {
+	return func(yield func(Entry, error) bool) {
+		for _, id := range slices.Sorted(maps.Keys(s.entries)) {
+			if !yield(Entry{ID: id, Data: s.entries[id]}, nil) {
+				return
+			}
+		}
+	}
+}
+
+const (
+	recAppend byte = iota + 1
+	recConsume
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// minCompact is the number of consumed entries a FileStorage holds before it
+// is compacted.
+const minCompact = 1024
+
+// FileStorage is a Storage that keeps entries in an append-only file. Each
+// append and consume is written to the file, as a record holding its length
+// and a checksum, and synced before returning. The file is compacted when it
+// holds more consumed entries than entries that have not been consumed. Entry
+// data is read from the file when needed and is not kept in memory.
+type FileStorage struct {
+	f         *os.File
+	path      string
+	live      map[ID]span
+	size      int64
+	next      ID
+	dead      int
+	buf       []byte
+	nosync    bool
+	truncated int64
+	err       error
+	broken    bool // a partial record could not be removed; see write
+}
+
+// span locates entry data in the file.
+type span struct {
+	off int64
+	n   int
+}
+
+// OpenFile opens the storage file at path, creating it if it does not exist.
+// A partially written record at the end of the file, left by a crash, is
+// removed, and the number of bytes removed is reported by Truncated. If any
+// other record is damaged, OpenFile returns ErrCorrupt.
+func OpenFile(path string) (*FileStorage, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	s := &FileStorage{
+		f:    f,
+		path: path,
+		live: map[ID]span{},
+	}
+	if err = s.scan(); err != nil {
+		// The error from scan is the one reported.
+		_ = f.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// scan reads the records in the file to find the entries that have not been
+// consumed.
+func (s *FileStorage) scan() error {
+	r := bufio.NewReader(s.f)
+	var off int64
+	var buf bytes.Buffer
+	for {
+		payload, n, err := readRecord(r, &buf)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		op := payload[0]
+		id, m := binary.Uvarint(payload[1:])
+		if m <= 0 {
+			return ErrCorrupt
+		}
+		switch op {
+		case recAppend:
+			// The entry data is the rest of the payload.
+			hdr := 1 + m
+			s.live[ID(id)] = span{
+				off: off + int64(uvarintLen(uint64(len(payload)))+hdr),
+				n:   len(payload) - hdr,
+			}
+			s.next = max(s.next, ID(id))
+		case recConsume:
+			if _, ok := s.live[ID(id)]; ok {
+				delete(s.live, ID(id))
+				s.dead++
+			}
+		default:
+			return ErrCorrupt
+		}
+		off += n
+	}
+	fi, err := s.f.Stat()
+	if err != nil {
+		return err
+	}
+	s.size = off
+	s.truncated = fi.Size() - off
+	if s.truncated != 0 {
+		if err = s.f.Truncate(off); err != nil {
+			return err
+		}
+	}
+	_, err = s.f.Seek(off, io.SeekStart)
+	return err
+}
+
+// readRecord reads the next record from r into buf, verifies its checksum,
+// and returns its payload and the number of bytes it took in the file. It
+// returns io.EOF at the end of the file, including when the file ends with a
+// partially written record, and ErrCorrupt if a record before the end of the
+// file is damaged.
+func readRecord(r *bufio.Reader, buf *bytes.Buffer) ([]byte, int64, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, 0, io.EOF
+		}
+		return nil, 0, atEnd(r)
+	}
+	buf.Reset()
+	// Copying, rather than allocating size bytes up front, avoids a huge
+	// allocation for a damaged length.
+	if _, err = io.CopyN(buf, r, int64(size)+4); err != nil {
+		if err == io.EOF {
+			return nil, 0, io.EOF
+		}
+		return nil, 0, err
+	}
+	b := buf.Bytes()
+	payload := b[:size]
+	if size == 0 || crc32.Checksum(payload, crcTable) != binary.BigEndian.Uint32(b[size:]) {
+		return nil, 0, atEnd(r)
+	}
+	return payload, int64(uvarintLen(size)) + int64(size) + 4, nil
+}
+
+// atEnd returns io.EOF if r has no more data, so that a damaged record at the
+// end of the file is taken to be partially written, and ErrCorrupt otherwise.
+func atEnd(r *bufio.Reader) error {
+	if _, err := r.Peek(1); err == io.EOF {
+		return io.EOF
+	}
+	return ErrCorrupt
+}
+
+// Truncated returns the number of bytes of a partially written record that
+// OpenFile removed from the end of the file, or 0 if there was none.
+func (s *FileStorage) Truncated() int64 {
+	return s.truncated
+}
+
+// Err returns the error that caused the most recent compaction of the file to
+// fail, or nil if it succeeded. A failed compaction does not affect the
+// entries stored, and is tried again by a later Consume.
+//
+// If a failed write leaves part of a record in the file that cannot be
+// removed, Err returns the error that prevented its removal, and every later
+// Append and Consume fails with that error. Reopening the file removes the
+// partial record.
+func (s *FileStorage) Err() error {
+	return s.err
+}
+
+func uvarintLen(x uint64) int {
+	var b [binary.MaxVarintLen64]byte
+	return binary.PutUvarint(b[:], x)
+}
+
+// Close closes the storage file.
+func (s *FileStorage) Close() error {
+	return s.f.Close()
+}
+
+// Append writes an entry to the file and returns its ID.
+func (s *FileStorage) Append(data []byte) (ID, error) {
+	id := s.next + 1
+	hdr := 1 + uvarintLen(uint64(id))
+	s.buf = binary.AppendUvarint(s.buf[:0], uint64(hdr+len(data)))
+	off := s.size + int64(len(s.buf)+hdr)
+	s.buf = append(s.buf, recAppend)
+	s.buf = binary.AppendUvarint(s.buf, uint64(id))
+	s.buf = append(s.buf, data...)
+	if err := s.write(s.buf); err != nil {
+		return 0, err
+	}
+	s.next = id
+	s.live[id] = span{off: off, n: len(data)}
+	return id, nil
+}
+
+// Consume writes a record to the file that marks the entry as consumed. Once
+// the record is written, Consume does not return an error, even if the
+// compaction that may follow fails; see Err.
+func (s *FileStorage) Consume(id ID) error {
+	if _, ok := s.live[id]; !ok {
+		return ErrNotFound
+	}
+	s.buf = binary.AppendUvarint(s.buf[:0], uint64(1+uvarintLen(uint64(id))))
+	s.buf = append(s.buf, recConsume)
+	s.buf = binary.AppendUvarint(s.buf, uint64(id))
+	if err := s.write(s.buf); err != nil {
+		return err
+	}
+	delete(s.live, id)
+	s.dead++
+	if s.dead >= minCompact && s.dead > len(s.live) {
+		s.err = s.compact()
+	}
+	return nil
+}
+
+// Entries returns an iterator over the entries that have not been consumed,
+// in the order they were appended.
+func (s *FileStorage) Entries() iter.Seq2[Entry, error] {
+	return func(yield func(Entry, error) bool) {
+		for _, id := range slices.Sorted(maps.Keys(s.live)) {
+			sp := s.live[id]
+			data := make([]byte, sp.n)
+			if _, err := s.f.ReadAt(data, sp.off); err != nil {
+				yield(Entry{}, err)
+				return
+			}
+			if !yield(Entry{ID: id, Data: data}, nil) {
+				return
+			}
+		}
+	}
+}
+
+// write appends the checksum to the record in rec, which begins with the
+// length of its payload, and writes it to the file.
+func (s *FileStorage) write(rec []byte) error {
+	if s.broken {
+		return s.err
+	}
+	_, n := binary.Uvarint(rec)
+	rec = binary.BigEndian.AppendUint32(rec, crc32.Checksum(rec[n:], crcTable))
+	s.buf = rec
+	if _, err := s.f.Write(rec); err != nil {
+		// Remove any part of the record that was written. If that fails, a
+		// record written after it would be lost when the file is next
+		// opened, so no more records are written.
+		if terr := s.f.Truncate(s.size); terr != nil {
+			s.err, s.broken = terr, true
+		} else if _, serr := s.f.Seek(s.size, io.SeekStart); serr != nil {
+			s.err, s.broken = serr, true
+		}
+		return err
+	}
+	s.size += int64(len(rec))
+	if s.nosync {
+		return nil
+	}
+	return s.f.Sync()
+}
+
+// compact rewrites the file with only the entries that have not been
+// consumed. The new file is written alongside the old file and then renamed
+// over it, so a crash during compaction leaves the old file in place. The
+// directory is synced after the rename, so that the rename is durable.
+func (s *FileStorage) compact() error {
+	tmp := s.path + ".compact"
+	// Remove any file left by an earlier compaction that did not finish.
+	if err := os.Remove(tmp); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	t, err := OpenFile(tmp)
+	if err != nil {
+		return err
+	}
+	// fail discards the new file. The error that stopped the compaction is
+	// the one reported, and the old file is still used, so errors from
+	// closing and removing the new file are not.
+	fail := func(err error) error {
+		_ = t.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	t.nosync = true
+	for e, err := range s.Entries() {
+		if err == nil {
+			err = t.appendID(e.ID, e.Data)
+		}
+		if err != nil {
+			return fail(err)
+		}
+	}
+	if err = t.f.Sync(); err == nil {
+		err = os.Rename(tmp, s.path)
+	}
+	if err != nil {
+		return fail(err)
+	}
+	// The old file has been replaced, so the new file is used even if the
+	// rename cannot be synced. Nothing is written to the old file after the
+	// last sync, so an error closing it is not reported.
+	_ = s.f.Close()
+	t.path = s.path
+	t.next = max(t.next, s.next)
+	t.nosync = false
+	*s = *t
+	return syncDir(filepath.Dir(s.path))
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = d.Sync()
+	if cerr := d.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// appendID writes an entry with the given ID.
+func (s *FileStorage) appendID(id ID, data []byte) error {
+	s.next = id - 1
+	_, err := s.Append(data)
+	return err
+}
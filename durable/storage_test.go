@@ -0,0 +1,126 @@
+package durable_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gammazero/heap/durable"
+)
+
+func TestConsumeNotFound(t *testing.T) {
+	s := durable.NewMemStorage()
+	if err := s.Consume(1); err != durable.ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	fs, err := durable.OpenFile(filepath.Join(t.TempDir(), "queue"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := fs.Close(); err != nil {
+			t.Error(err)
+		}
+	}()
+	if err = fs.Consume(1); err != durable.ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestOpenFileCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue")
+	s, err := durable.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range 3 {
+		if _, err := s.Append([]byte{byte(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Damage the first record, which is followed by the others.
+	data[3] ^= 0x01
+	if err = os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = durable.OpenFile(path); err != durable.ErrCorrupt {
+		t.Fatalf("expected ErrCorrupt, got %v", err)
+	}
+}
+
+func TestCompactError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue")
+	s, err := durable.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := s.Close(); err != nil {
+			t.Error(err)
+		}
+	}()
+	// A directory in place of the temporary file makes compaction fail.
+	if err = os.MkdirAll(filepath.Join(path+".compact", "x"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	q, err := durable.Open(s, func(a, b int) bool { return a < b }, marshalInt, unmarshalInt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range 1100 {
+		if err := q.Push(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The consume records are written even though compaction fails, so the
+	// elements are removed.
+	for i := range 1100 {
+		x, err := q.Pop()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if x != i {
+			t.Fatalf("expected %d, got %d", i, x)
+		}
+	}
+	if s.Err() == nil {
+		t.Fatal("expected compaction error")
+	}
+}
+
+func TestWriteRollbackError(t *testing.T) {
+	s, err := durable.OpenFile(filepath.Join(t.TempDir(), "queue"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := s.Append([]byte{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Closing the file makes both the write and the removal of any part of
+	// the record that was written fail.
+	if err = s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = s.Append([]byte{2}); err == nil {
+		t.Fatal("expected write error")
+	}
+	rerr := s.Err()
+	if rerr == nil {
+		t.Fatal("expected rollback error")
+	}
+	if _, err = s.Append([]byte{3}); err != rerr {
+		t.Fatalf("expected later append to fail with %v, got %v", rerr, err)
+	}
+	if err = s.Consume(id); err != rerr {
+		t.Fatalf("expected later consume to fail with %v, got %v", rerr, err)
+	}
+}
@@ -0,0 +1,173 @@
+// Package edf provides an earliest-deadline-first task scheduler.
+//
+// Each time a worker becomes available it runs the pending task with the
+// earliest deadline. Tasks that start after their deadline has already passed
+// are reported to a late callback, which can be used to detect overload.
+package edf
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gammazero/heap"
+)
+
+// Scheduler runs submitted tasks in deadline order on a fixed number of
+// workers. It is safe for concurrent use.
+type Scheduler struct {
+	mu       sync.Mutex
+	cond     sync.Cond
+	tasks    *heap.Heap[*Task]
+	pending  int
+	seq      uint64
+	onLate   func(deadline time.Time, lateness time.Duration)
+	stopping bool
+	wg       sync.WaitGroup
+}
+
+// Task is a handle to a submitted task.
+type Task struct {
+	s        *Scheduler
+	fn       func(context.Context)
+	deadline time.Time
+	seq      uint64
+	state    taskState
+}
+
+type taskState int
+
+const (
+	statePending taskState = iota
+	stateStarted
+	stateCanceled
+)
+
+// New creates a Scheduler and starts its workers. The workers parameter is the
+// number of tasks that can run concurrently; if less than 1, then 1 is used.
+//
+// If onLate is not nil, it is called by a worker whenever it starts a task
+// after the task's deadline, with the deadline and how late the task is.
+func New(workers int, onLate func(deadline time.Time, lateness time.Duration)) *Scheduler {
+	if workers < 1 {
+		workers = 1
+	}
+	s := &Scheduler{
+		tasks: heap.New(func(a, b *Task) bool {
+			if !a.deadline.Equal(b.deadline) {
+				return a.deadline.Before(b.deadline)
+			}
+			return a.seq < b.seq
+		}),
+		onLate: onLate,
+	}
+	s.cond.L = &s.mu
+	s.wg.Add(workers)
+	for range workers {
+		go s.worker()
+	}
+	return s
+}
+
+// Submit schedules fn to run with the given deadline. The context passed to fn
+// has the task's deadline. Submit returns nil if the scheduler is stopped.
+func (s *Scheduler) Submit(fn func(ctx context.Context), deadline time.Time) *Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopping {
+		return nil
+	}
+	s.seq++
+	t := &Task{
+		s:        s,
+		fn:       fn,
+		deadline: deadline,
+		seq:      s.seq,
+	}
+	s.tasks.Push(t)
+	s.pending++
+	s.cond.Signal()
+	return t
+}
+
+// Len returns the number of tasks waiting to run.
+func (s *Scheduler) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pending
+}
+
+// Shape returns the size and shape of the scheduler's heap of waiting tasks.
+// Canceled tasks stay in the heap until they reach its top, and are counted
+// as tombstones.
+func (s *Scheduler) Shape() heap.Shape {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	shape := s.tasks.Shape()
+	shape.Tombstones = shape.Len - s.pending
+	return shape
+}
+
+// Stop stops the scheduler, discarding all waiting tasks, and waits for
+// running tasks to finish.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	s.stopping = true
+	for s.tasks.Len() != 0 {
+		s.tasks.Pop().state = stateCanceled
+	}
+	s.pending = 0
+	s.mu.Unlock()
+	s.cond.Broadcast()
+	s.wg.Wait()
+}
+
+// Deadline returns the task's deadline.
+func (t *Task) Deadline() time.Time {
+	return t.deadline
+}
+
+// Cancel prevents the task from running if it has not already started. It
+// returns true if the task was canceled by this call.
+func (t *Task) Cancel() bool {
+	t.s.mu.Lock()
+	defer t.s.mu.Unlock()
+	if t.state != statePending {
+		return false
+	}
+	// The canceled task is discarded when it reaches the head of the heap.
+	t.state = stateCanceled
+	t.s.pending--
+	return true
+}
+
+func (s *Scheduler) worker() {
+	defer s.wg.Done()
+	for {
+		s.mu.Lock()
+		for s.pending == 0 && !s.stopping {
+			s.cond.Wait()
+		}
+		if s.stopping {
+			s.mu.Unlock()
+			return
+		}
+		t := s.tasks.Pop()
+		if t.state == stateCanceled {
+			s.mu.Unlock()
+			continue
+		}
+		t.state = stateStarted
+		s.pending--
+		s.mu.Unlock()
+
+		if s.onLate != nil {
+			if late := time.Since(t.deadline); late > 0 {
+				s.onLate(t.deadline, late)
+			}
+		}
+		ctx, cancel := context.WithDeadline(context.Background(), t.deadline)
+		t.fn(ctx)
+		cancel()
+	}
+}
@@ -0,0 +1,108 @@
+package edf_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gammazero/heap/edf"
+)
+
+func TestDeadlineOrder(t *testing.T) {
+	s := edf.New(1, nil)
+	defer s.Stop()
+
+	release := make(chan struct{})
+	s.Submit(func(context.Context) { <-release }, time.Now())
+
+	var mu sync.Mutex
+	var order []int
+	var wg sync.WaitGroup
+	now := time.Now()
+	for _, n := range []int{3, 1, 4, 2} {
+		wg.Add(1)
+		s.Submit(func(context.Context) {
+			mu.Lock()
+			order = append(order, n)
+			mu.Unlock()
+			wg.Done()
+		}, now.Add(time.Duration(n)*time.Hour))
+	}
+	close(release)
+	wg.Wait()
+
+	if fmt.Sprint(order) != "[1 2 3 4]" {
+		t.Fatalf("unexpected order %v", order)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	s := edf.New(1, nil)
+	defer s.Stop()
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	first := s.Submit(func(context.Context) {
+		close(started)
+		<-release
+	}, time.Now())
+	<-started
+
+	ran := make(chan int, 2)
+	task := s.Submit(func(context.Context) { ran <- 1 }, time.Now())
+	s.Submit(func(context.Context) { ran <- 2 }, time.Now().Add(time.Hour))
+	if s.Len() != 2 {
+		t.Fatalf("expected 2 waiting tasks, got %d", s.Len())
+	}
+
+	if first.Cancel() {
+		t.Fatal("should not cancel started task")
+	}
+	if !task.Cancel() {
+		t.Fatal("expected task to be canceled")
+	}
+	if task.Cancel() {
+		t.Fatal("should not cancel task twice")
+	}
+	if s.Len() != 1 {
+		t.Fatalf("expected 1 waiting task, got %d", s.Len())
+	}
+	if shape := s.Shape(); shape.Len != 2 || shape.Tombstones != 1 {
+		t.Fatalf("expected 1 of 2 tasks in heap to be a tombstone: %v", shape)
+	}
+	close(release)
+
+	if x := <-ran; x != 2 {
+		t.Fatalf("canceled task ran")
+	}
+}
+
+func TestLate(t *testing.T) {
+	late := make(chan time.Duration, 1)
+	s := edf.New(1, func(_ time.Time, lateness time.Duration) {
+		late <- lateness
+	})
+	defer s.Stop()
+
+	done := make(chan bool)
+	s.Submit(func(ctx context.Context) {
+		done <- ctx.Err() != nil
+	}, time.Now().Add(-time.Second))
+
+	if d := <-late; d < time.Second {
+		t.Fatalf("expected lateness of at least 1s, got %s", d)
+	}
+	if !<-done {
+		t.Fatal("expected late task's context to be done")
+	}
+}
+
+func TestStop(t *testing.T) {
+	s := edf.New(2, nil)
+	s.Stop()
+	if s.Submit(func(context.Context) {}, time.Now()) != nil {
+		t.Fatal("expected nil task after stop")
+	}
+}
@@ -0,0 +1,125 @@
+package heap
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"slices"
+)
+
+var errNoLess = errors.New("heap: cannot decode into heap without less function")
+
+// ErrNotHeapOrder is returned by ImportLevelOrder and LoadPage when the data
+// is not in heap order.
+var ErrNotHeapOrder = errors.New("heap: data not in heap order")
+
+// Export returns a copy of the heap's elements in level order, which is the
+// exact layout of the heap. Passing the result to ImportLevelOrder restores
+// the same layout, so that the heap pops elements in the same order, even
+// when elements compare as equal.
+func (h *Heap[T]) Export() []T {
+	if h.guard != nil {
+		h.checkRead()
+	}
+	return slices.Clone(h.heapOrder())
+}
+
+// ImportLevelOrder replaces the heap's elements with a copy of data, which
+// must be in heap order, such as data returned by Export. The layout of data
+// is kept exactly. If data is not in heap order, ErrNotHeapOrder is returned
+// and the heap is not modified.
+func (h *Heap[T]) ImportLevelOrder(data []T) error {
+	if h.less == nil {
+		return errNoLess
+	}
+	for i := 1; i < len(data); i++ {
+		if h.less(data[i], data[(i-1)/2]) {
+			return ErrNotHeapOrder
+		}
+	}
+	h.replace(slices.Clone(data), false)
+	return nil
+}
+
+// MarshalJSON encodes the heap's elements as a JSON array in heap order.
+func (h *Heap[T]) MarshalJSON() ([]byte, error) {
+	if h.guard != nil {
+		h.checkRead()
+	}
+	if h.data == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(h.heapOrder())
+}
+
+// UnmarshalJSON replaces the heap's elements with the elements of a JSON
+// array, and then restores the heap ordering using the heap's less function.
+// The heap must have been created with a less function, such as by New.
+func (h *Heap[T]) UnmarshalJSON(b []byte) error {
+	if h.less == nil {
+		return errNoLess
+	}
+	var data []T
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	h.replace(data, true)
+	return nil
+}
+
+// GobEncode encodes the heap's elements in heap order. The less function is
+// not encoded.
+func (h *Heap[T]) GobEncode() ([]byte, error) {
+	if h.guard != nil {
+		h.checkRead()
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(h.heapOrder()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode replaces the heap's elements with decoded elements, and then
+// restores the heap ordering using the heap's less function. The heap must
+// have been created with a less function, such as by New.
+func (h *Heap[T]) GobDecode(b []byte) error {
+	if h.less == nil {
+		return errNoLess
+	}
+	var data []T
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&data); err != nil {
+		return err
+	}
+	h.replace(data, true)
+	return nil
+}
+
+// replace replaces all of the heap's elements with data. If heapify is true,
+// the heap ordering is restored; otherwise data must already be in heap
+// order.
+func (h *Heap[T]) replace(data []T, heapify bool) {
+	if h.guard != nil {
+		h.startWrite()
+	}
+	defer h.endOp()
+	if h.onMove != nil {
+		for _, x := range h.data {
+			h.onMove(x, -1)
+		}
+		for i, x := range data {
+			h.onMove(x, i)
+		}
+	}
+	h.modify()
+	h.data = data
+	h.unordered = false
+	if heapify {
+		h.heapify()
+	}
+	h.watermarksChanged()
+	if h.journal != nil {
+		h.recordOp(OpReplace, len(h.data))
+	}
+}
@@ -0,0 +1,157 @@
+package heap_test
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/gob"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/gammazero/heap"
+)
+
+func TestJSON(t *testing.T) {
+	less := cmp.Less[int]
+	h := heap.NewFrom(less, 5, 3, 8, 1, 9)
+	b, err := json.Marshal(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h2 := heap.New(less)
+	h2.Push(100)
+	if err = json.Unmarshal(b, h2); err != nil {
+		t.Fatal(err)
+	}
+	if h2.Len() != 5 {
+		t.Fatalf("expected 5 elements, got %d", h2.Len())
+	}
+	verifyIntHeap(t, h2, 0, less)
+	for _, want := range []int{1, 3, 5, 8, 9} {
+		if x := h2.Pop(); x != want {
+			t.Fatalf("expected %d, got %d", want, x)
+		}
+	}
+
+	// Unordered input is heapified.
+	if err = json.Unmarshal([]byte("[7, 2, 4]"), h2); err != nil {
+		t.Fatal(err)
+	}
+	if h2.Peek() != 2 {
+		t.Fatalf("expected 2 at head, got %d", h2.Peek())
+	}
+
+	b, err = json.Marshal(heap.New(less))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "[]" {
+		t.Fatalf("expected empty array, got %s", b)
+	}
+
+	if err = json.Unmarshal([]byte(`{"a": 1}`), h2); err == nil {
+		t.Fatal("expected error decoding object")
+	}
+	var noLess heap.Heap[int]
+	if err = json.Unmarshal([]byte("[1]"), &noLess); err == nil {
+		t.Fatal("expected error decoding into heap without less function")
+	}
+}
+
+func TestJSONField(t *testing.T) {
+	type state struct {
+		Name  string
+		Queue *heap.Heap[string]
+	}
+	s := state{Name: "jobs", Queue: heap.NewFrom(cmp.Less[string], "b", "a")}
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored := state{Queue: heap.New(cmp.Less[string])}
+	if err = json.Unmarshal(b, &restored); err != nil {
+		t.Fatal(err)
+	}
+	if restored.Queue.Pop() != "a" || restored.Queue.Pop() != "b" {
+		t.Fatal("unexpected restored queue")
+	}
+}
+
+func TestGob(t *testing.T) {
+	type job struct {
+		Name     string
+		Priority int
+	}
+	less := func(a, b job) bool { return a.Priority < b.Priority }
+	type checkpoint struct {
+		Version int
+		Queue   *heap.Heap[job]
+	}
+
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(checkpoint{
+		Version: 1,
+		Queue:   heap.NewFrom(less, job{"c", 3}, job{"a", 1}, job{"b", 2}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored := checkpoint{Queue: heap.New(less)}
+	if err = gob.NewDecoder(&buf).Decode(&restored); err != nil {
+		t.Fatal(err)
+	}
+	if restored.Version != 1 || restored.Queue.Len() != 3 {
+		t.Fatalf("unexpected checkpoint %+v", restored)
+	}
+	for _, want := range []string{"a", "b", "c"} {
+		if j := restored.Queue.Pop(); j.Name != want {
+			t.Fatalf("expected %s, got %s", want, j.Name)
+		}
+	}
+
+	var noLess heap.Heap[job]
+	b, _ := heap.New(less).GobEncode()
+	if err = noLess.GobDecode(b); err == nil {
+		t.Fatal("expected error decoding into heap without less function")
+	}
+}
+
+func TestExportImportLevelOrder(t *testing.T) {
+	type item struct {
+		prio int
+		name string
+	}
+	less := func(a, b item) bool { return a.prio < b.prio }
+	h := heap.New(less)
+	for i, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		h.Push(item{i % 2, name})
+	}
+	layout := h.Export()
+
+	h2 := heap.New(less)
+	if err := h2.ImportLevelOrder(layout); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(h2.Export(), layout) {
+		t.Fatal("layout not kept")
+	}
+	for h.Len() != 0 {
+		if a, b := h.Pop(), h2.Pop(); a != b {
+			t.Fatalf("pop order differs: %v != %v", a, b)
+		}
+	}
+	if !slices.Equal(h.Export(), nil) || len(layout) != 6 {
+		t.Fatal("exported layout shares storage with heap")
+	}
+
+	h3 := heap.NewFrom(cmp.Less[int], 7)
+	if err := h3.ImportLevelOrder([]int{1, 3, 2, 0}); err != heap.ErrNotHeapOrder {
+		t.Fatalf("expected ErrNotHeapOrder, got %v", err)
+	}
+	if h3.Len() != 1 || h3.Peek() != 7 {
+		t.Fatal("heap modified by failed import")
+	}
+}
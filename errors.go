@@ -0,0 +1,61 @@
+package heap
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrEmpty is returned by PopE and PeekE when the heap is empty.
+	ErrEmpty = errors.New("heap: heap is empty")
+	// ErrIndexOutOfRange is returned by RemoveE and AtE when the index is
+	// not in the heap.
+	ErrIndexOutOfRange = errors.New("heap: index out of range")
+)
+
+// PopE is like Pop, but returns ErrEmpty if the heap is empty instead of
+// panicking.
+func (h *Heap[T]) PopE() (T, error) {
+	if len(h.data) == 0 {
+		var zero T
+		return zero, ErrEmpty
+	}
+	return h.Pop(), nil
+}
+
+// PeekE is like Peek, but returns ErrEmpty if the heap is empty instead of
+// panicking.
+func (h *Heap[T]) PeekE() (T, error) {
+	if len(h.data) == 0 {
+		var zero T
+		return zero, ErrEmpty
+	}
+	return h.Peek(), nil
+}
+
+// RemoveE is like Remove, but returns an error that wraps ErrIndexOutOfRange
+// if i is not an index in the heap instead of panicking.
+func (h *Heap[T]) RemoveE(i int) (T, error) {
+	if err := h.checkIndex(i); err != nil {
+		var zero T
+		return zero, err
+	}
+	return h.Remove(i), nil
+}
+
+// AtE is like At, but returns an error that wraps ErrIndexOutOfRange if i is
+// not an index in the heap instead of panicking.
+func (h *Heap[T]) AtE(i int) (T, error) {
+	if err := h.checkIndex(i); err != nil {
+		var zero T
+		return zero, err
+	}
+	return h.At(i), nil
+}
+
+func (h *Heap[T]) checkIndex(i int) error {
+	if i < 0 || i >= len(h.data) {
+		return fmt.Errorf("%w: index %d with length %d", ErrIndexOutOfRange, i, len(h.data))
+	}
+	return nil
+}
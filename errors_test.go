@@ -0,0 +1,48 @@
+package heap_test
+
+import (
+	"cmp"
+	"errors"
+	"testing"
+
+	"github.com/gammazero/heap"
+)
+
+func TestErrorVariants(t *testing.T) {
+	h := heap.New(cmp.Less[int])
+	if _, err := h.PopE(); !errors.Is(err, heap.ErrEmpty) {
+		t.Fatalf("expected ErrEmpty from PopE, got %v", err)
+	}
+	if _, err := h.PeekE(); !errors.Is(err, heap.ErrEmpty) {
+		t.Fatalf("expected ErrEmpty from PeekE, got %v", err)
+	}
+
+	for _, x := range []int{4, 2, 3, 1} {
+		h.Push(x)
+	}
+	for _, i := range []int{-1, 4} {
+		if _, err := h.AtE(i); !errors.Is(err, heap.ErrIndexOutOfRange) {
+			t.Fatalf("expected ErrIndexOutOfRange from AtE(%d), got %v", i, err)
+		}
+		if _, err := h.RemoveE(i); !errors.Is(err, heap.ErrIndexOutOfRange) {
+			t.Fatalf("expected ErrIndexOutOfRange from RemoveE(%d), got %v", i, err)
+		}
+	}
+	if h.Len() != 4 {
+		t.Fatal("failed call modified heap")
+	}
+
+	if x, err := h.PeekE(); err != nil || x != 1 {
+		t.Fatalf("PeekE returned %d, %v", x, err)
+	}
+	if x, err := h.AtE(0); err != nil || x != 1 {
+		t.Fatalf("AtE returned %d, %v", x, err)
+	}
+	if x, err := h.PopE(); err != nil || x != 1 {
+		t.Fatalf("PopE returned %d, %v", x, err)
+	}
+	x, err := h.RemoveE(0)
+	if err != nil || x != 2 {
+		t.Fatalf("RemoveE returned %d, %v", x, err)
+	}
+}
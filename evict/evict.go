@@ -0,0 +1,155 @@
+// Package evict provides the eviction policy of a cache in which each entry
+// has a priority and a weight, such as a size in bytes. Entries with the
+// lowest priority are evicted first until the total weight of the entries
+// fits within a budget.
+//
+// Priorities are computed by the caller, so any cost-aware policy can be
+// used. For example, Greedy-Dual-Size-Frequency (GDSF) gives each entry the
+// priority L + frequency*cost/size, where L is the priority of the entry last
+// evicted, which the eviction callback receives.
+//
+// Entries are kept in a heap ordered by priority, with a map from key to
+// entry, so setting, touching, and evicting an entry are O(log n).
+package evict
+
+import "github.com/gammazero/heap"
+
+// Evictor holds entries by key and evicts those with the lowest priority. It
+// is not safe for concurrent use.
+type Evictor[K comparable, V any] struct {
+	h       *heap.Heap[*entry[K, V]]
+	index   map[K]*entry[K, V]
+	weight  int64
+	seq     uint64
+	onEvict func(K, V, float64)
+}
+
+type entry[K comparable, V any] struct {
+	key    K
+	val    V
+	pri    float64
+	weight int64
+	seq    uint64
+	pos    int
+}
+
+// New returns a new Evictor. If onEvict is not nil, it is called by EvictUntil
+// for each evicted entry, with the entry's priority.
+func New[K comparable, V any](onEvict func(key K, val V, priority float64)) *Evictor[K, V] {
+	e := &Evictor[K, V]{
+		// Entries with equal priority are evicted least recently set or
+		// touched first.
+		h: heap.New(func(a, b *entry[K, V]) bool {
+			if a.pri != b.pri {
+				return a.pri < b.pri
+			}
+			return a.seq < b.seq
+		}),
+		index:   make(map[K]*entry[K, V]),
+		onEvict: onEvict,
+	}
+	e.h.SetOnMove(func(x *entry[K, V], i int) {
+		x.pos = i
+	})
+	return e
+}
+
+// Len returns the number of entries.
+func (e *Evictor[K, V]) Len() int {
+	return e.h.Len()
+}
+
+// Weight returns the total weight of all entries.
+func (e *Evictor[K, V]) Weight() int64 {
+	return e.weight
+}
+
+// Set adds an entry with the given priority and weight. If the key already
+// exists, its value, priority, and weight are replaced. Set does not evict
+// entries; call EvictUntil to bring the total weight within a budget.
+func (e *Evictor[K, V]) Set(key K, val V, priority float64, weight int64) {
+	if weight < 0 {
+		panic("evict: negative weight")
+	}
+	e.seq++
+	if ent, ok := e.index[key]; ok {
+		e.weight += weight - ent.weight
+		ent.val, ent.pri, ent.weight, ent.seq = val, priority, weight, e.seq
+		e.h.Fix(ent.pos)
+		return
+	}
+	ent := &entry[K, V]{
+		key:    key,
+		val:    val,
+		pri:    priority,
+		weight: weight,
+		seq:    e.seq,
+	}
+	e.index[key] = ent
+	e.h.Push(ent)
+	e.weight += weight
+}
+
+// Get returns the value for the key. The second return value is false if the
+// key does not exist. Get does not change the entry's priority.
+func (e *Evictor[K, V]) Get(key K) (V, bool) {
+	ent, ok := e.index[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	return ent.val, true
+}
+
+// Priority returns the priority of the key's entry. The second return value is
+// false if the key does not exist.
+func (e *Evictor[K, V]) Priority(key K) (float64, bool) {
+	ent, ok := e.index[key]
+	if !ok {
+		return 0, false
+	}
+	return ent.pri, true
+}
+
+// Touch sets the priority of the key's entry, such as when the entry is used.
+// It returns false if the key does not exist.
+func (e *Evictor[K, V]) Touch(key K, priority float64) bool {
+	ent, ok := e.index[key]
+	if !ok {
+		return false
+	}
+	e.seq++
+	ent.pri, ent.seq = priority, e.seq
+	e.h.Fix(ent.pos)
+	return true
+}
+
+// Remove removes the key's entry without calling the eviction callback. It
+// returns false if the key does not exist.
+func (e *Evictor[K, V]) Remove(key K) bool {
+	ent, ok := e.index[key]
+	if !ok {
+		return false
+	}
+	e.h.Remove(ent.pos)
+	delete(e.index, key)
+	e.weight -= ent.weight
+	return true
+}
+
+// EvictUntil evicts entries, lowest priority first, until the total weight is
+// no more than budget, calling the eviction callback for each. It returns the
+// number of entries evicted.
+func (e *Evictor[K, V]) EvictUntil(budget int64) int {
+	var n int
+	for e.weight > budget && e.h.Len() != 0 {
+		ent := e.h.Pop()
+		delete(e.index, ent.key)
+		e.weight -= ent.weight
+		n++
+		if e.onEvict != nil {
+			e.onEvict(ent.key, ent.val, ent.pri)
+		}
+	}
+	return n
+}
@@ -0,0 +1,99 @@
+package evict_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gammazero/heap/evict"
+)
+
+func TestEvictUntil(t *testing.T) {
+	var evicted []string
+	e := evict.New(func(key string, val int, pri float64) {
+		evicted = append(evicted, fmt.Sprint(key, "=", val))
+	})
+	e.Set("a", 1, 5, 100)
+	e.Set("b", 2, 1, 300)
+	e.Set("c", 3, 3, 200)
+	e.Set("d", 4, 3, 50)
+	if e.Len() != 4 || e.Weight() != 650 {
+		t.Fatalf("wrong length %d or weight %d", e.Len(), e.Weight())
+	}
+
+	if n := e.EvictUntil(650); n != 0 {
+		t.Fatalf("expected nothing evicted, got %d", n)
+	}
+	// Equal priorities are evicted least recently set first.
+	if n := e.EvictUntil(100); n != 3 {
+		t.Fatalf("expected 3 evicted, got %d", n)
+	}
+	if fmt.Sprint(evicted) != "[b=2 c=3 d=4]" {
+		t.Fatalf("wrong eviction order %v", evicted)
+	}
+	if _, ok := e.Get("b"); ok {
+		t.Fatal("evicted entry still present")
+	}
+	if v, ok := e.Get("a"); !ok || v != 1 || e.Weight() != 100 {
+		t.Fatal("wrong remaining entry")
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	var evicted []string
+	e := evict.New(func(key string, _ int, _ float64) {
+		evicted = append(evicted, key)
+	})
+	e.Set("a", 1, 1, 10)
+	e.Set("b", 2, 2, 10)
+	e.Set("c", 3, 3, 10)
+
+	if !e.Touch("a", 10) || e.Touch("z", 1) {
+		t.Fatal("wrong result from Touch")
+	}
+	if p, _ := e.Priority("a"); p != 10 {
+		t.Fatalf("wrong priority %v", p)
+	}
+	e.Set("c", 30, 0, 25)
+	if e.Weight() != 45 {
+		t.Fatalf("weight not updated by Set: %d", e.Weight())
+	}
+	if !e.Remove("b") || e.Remove("b") {
+		t.Fatal("wrong result from Remove")
+	}
+	e.EvictUntil(10)
+	if fmt.Sprint(evicted) != "[c]" || e.Len() != 1 {
+		t.Fatalf("wrong evictions %v", evicted)
+	}
+	e.EvictUntil(0)
+	if e.Len() != 0 || e.Weight() != 0 {
+		t.Fatal("expected all entries evicted")
+	}
+}
+
+func Example() {
+	// Greedy-Dual-Size-Frequency: priority is L + frequency*cost/size, where
+	// L is the priority of the last evicted entry.
+	var inflation float64
+	e := evict.New(func(key string, _ []byte, pri float64) {
+		inflation = pri
+		fmt.Println("evicted", key)
+	})
+	freq := map[string]int{}
+	access := func(key string, size int64) {
+		freq[key]++
+		pri := inflation + float64(freq[key])/float64(size)
+		if !e.Touch(key, pri) {
+			e.Set(key, make([]byte, size), pri, size)
+		}
+		e.EvictUntil(1000)
+	}
+	access("small", 100)
+	access("large", 800)
+	access("small", 100)
+	access("medium", 400)
+	access("large", 800)
+
+	// Output:
+	// evicted large
+	// evicted medium
+}
@@ -0,0 +1,138 @@
+// Package expiry provides a keyed collection of values that expire after a
+// time-to-live, with a callback for evicted entries.
+//
+// Entries are kept in a heap ordered by expiration time, and a map from key to
+// entry lets an entry's expiration be changed or the entry removed in
+// O(log n). Expired entries are evicted by calling Sweep, for example from a
+// timer set to NextExpiry.
+package expiry
+
+import (
+	"time"
+
+	"github.com/gammazero/heap"
+)
+
+// Expiry holds values by key until they expire. It is not safe for concurrent
+// use.
+type Expiry[K comparable, V any] struct {
+	h       *heap.Heap[*entry[K, V]]
+	index   map[K]*entry[K, V]
+	onEvict func(K, V)
+}
+
+type entry[K comparable, V any] struct {
+	key     K
+	val     V
+	ttl     time.Duration
+	expires time.Time
+	pos     int
+}
+
+// New returns a new Expiry. If onEvict is not nil, it is called by Sweep for
+// each expired entry.
+func New[K comparable, V any](onEvict func(key K, val V)) *Expiry[K, V] {
+	e := &Expiry[K, V]{
+		h: heap.New(func(a, b *entry[K, V]) bool {
+			return a.expires.Before(b.expires)
+		}),
+		index:   make(map[K]*entry[K, V]),
+		onEvict: onEvict,
+	}
+	e.h.SetOnMove(func(x *entry[K, V], i int) {
+		x.pos = i
+	})
+	return e
+}
+
+// Len returns the number of entries, including expired entries that have not
+// been swept.
+func (e *Expiry[K, V]) Len() int {
+	return e.h.Len()
+}
+
+// Add adds an entry that expires after ttl. If the key already exists, its
+// value and ttl are replaced and its expiration is reset.
+func (e *Expiry[K, V]) Add(key K, val V, ttl time.Duration) {
+	expires := time.Now().Add(ttl)
+	if ent, ok := e.index[key]; ok {
+		ent.val = val
+		ent.ttl = ttl
+		ent.expires = expires
+		e.h.Fix(ent.pos)
+		return
+	}
+	ent := &entry[K, V]{
+		key:     key,
+		val:     val,
+		ttl:     ttl,
+		expires: expires,
+	}
+	e.index[key] = ent
+	e.h.Push(ent)
+}
+
+// Get returns the value for the key. The second return value is false if the
+// key does not exist or has expired.
+func (e *Expiry[K, V]) Get(key K) (V, bool) {
+	ent, ok := e.index[key]
+	if !ok || !ent.expires.After(time.Now()) {
+		var zero V
+		return zero, false
+	}
+	return ent.val, true
+}
+
+// Touch resets the expiration of the key's entry to its ttl from now. It
+// returns false if the key does not exist or has expired. An expired entry is
+// not revived, and is evicted by the next Sweep.
+func (e *Expiry[K, V]) Touch(key K) bool {
+	ent, ok := e.index[key]
+	if !ok {
+		return false
+	}
+	now := time.Now()
+	if !ent.expires.After(now) {
+		return false
+	}
+	ent.expires = now.Add(ent.ttl)
+	e.h.Fix(ent.pos)
+	return true
+}
+
+// Remove removes the key's entry without calling the eviction callback. It
+// returns false if the key does not exist.
+func (e *Expiry[K, V]) Remove(key K) bool {
+	ent, ok := e.index[key]
+	if !ok {
+		return false
+	}
+	e.h.Remove(ent.pos)
+	delete(e.index, key)
+	return true
+}
+
+// NextExpiry returns the earliest expiration time of all entries. The second
+// return value is false if there are no entries.
+func (e *Expiry[K, V]) NextExpiry() (time.Time, bool) {
+	if e.h.Len() == 0 {
+		return time.Time{}, false
+	}
+	return e.h.Peek().expires, true
+}
+
+// Sweep removes all entries that have expired by now, in order of expiration,
+// calling the eviction callback for each. It returns the number of entries
+// removed.
+func (e *Expiry[K, V]) Sweep(now time.Time) int {
+	var n int
+	for e.h.Len() != 0 && !e.h.Peek().expires.After(now) {
+		ent := e.h.Pop()
+		delete(e.index, ent.key)
+		n++
+		if e.onEvict != nil {
+			e.onEvict(ent.key, ent.val)
+		}
+	}
+	return n
+}
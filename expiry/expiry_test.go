@@ -0,0 +1,87 @@
+package expiry_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gammazero/heap/expiry"
+)
+
+func TestSweep(t *testing.T) {
+	var evicted []string
+	e := expiry.New(func(key string, val int) {
+		evicted = append(evicted, fmt.Sprint(key, "=", val))
+	})
+	e.Add("b", 2, 2*time.Minute)
+	e.Add("a", 1, time.Minute)
+	e.Add("c", 3, 3*time.Minute)
+
+	if v, ok := e.Get("a"); !ok || v != 1 {
+		t.Fatalf("expected a=1, got %d %v", v, ok)
+	}
+	next, ok := e.NextExpiry()
+	if !ok || time.Until(next) > time.Minute {
+		t.Fatalf("unexpected next expiry %v", next)
+	}
+
+	if n := e.Sweep(time.Now()); n != 0 {
+		t.Fatalf("expected nothing swept, got %d", n)
+	}
+	if n := e.Sweep(time.Now().Add(150 * time.Second)); n != 2 {
+		t.Fatalf("expected 2 swept, got %d", n)
+	}
+	if fmt.Sprint(evicted) != "[a=1 b=2]" {
+		t.Fatalf("unexpected evictions %v", evicted)
+	}
+	if _, ok := e.Get("a"); ok {
+		t.Fatal("expected a to be gone")
+	}
+	if e.Len() != 1 {
+		t.Fatalf("expected 1 entry, got %d", e.Len())
+	}
+}
+
+func TestTouchAndReplace(t *testing.T) {
+	var evicted []string
+	e := expiry.New(func(key string, _ int) {
+		evicted = append(evicted, key)
+	})
+	e.Add("a", 1, time.Minute)
+	e.Add("b", 2, 2*time.Minute)
+	e.Add("c", 3, 3*time.Minute)
+
+	// Replacing a pushes its expiration past b's.
+	e.Add("a", 10, 5*time.Minute)
+	if v, _ := e.Get("a"); v != 10 {
+		t.Fatalf("expected replaced value 10, got %d", v)
+	}
+	if !e.Touch("b") {
+		t.Fatal("expected to touch b")
+	}
+	if e.Touch("missing") {
+		t.Fatal("should not touch missing key")
+	}
+	if !e.Remove("c") || e.Remove("c") {
+		t.Fatal("expected to remove c once")
+	}
+
+	e.Sweep(time.Now().Add(time.Hour))
+	if fmt.Sprint(evicted) != "[b a]" {
+		t.Fatalf("unexpected evictions %v", evicted)
+	}
+}
+
+func TestExpiredGet(t *testing.T) {
+	e := expiry.New[string, int](nil)
+	e.Add("gone", 1, -time.Second)
+	if _, ok := e.Get("gone"); ok {
+		t.Fatal("expected expired entry to be hidden")
+	}
+	if e.Touch("gone") {
+		t.Fatal("expected expired entry not to be touched")
+	}
+	if e.Sweep(time.Now()) != 1 {
+		t.Fatal("expected expired entry to be swept")
+	}
+}
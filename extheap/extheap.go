@@ -0,0 +1,292 @@
+// Package extheap provides an external-memory priority queue, for holding more
+// elements than fit in memory.
+//
+// Elements are pushed into a bounded in-memory heap. When the in-memory heap
+// is full, its elements are written to a temporary file as a sorted run. The
+// smallest element is found by comparing the head of the in-memory heap with
+// the heads of the runs, so only the head element of each run is held in
+// memory. When there are too many runs, the smallest runs are merged into one.
+package extheap
+
+import (
+	"bufio"
+	"cmp"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/gammazero/heap"
+)
+
+// maxRuns is the number of runs above which runs are merged, to limit the
+// number of open files.
+const maxRuns = 64
+
+// ExtHeap is a priority queue that spills elements to temporary files. It is
+// not safe for concurrent use.
+type ExtHeap[T any] struct {
+	less  func(a, b T) bool
+	enc   func(io.Writer, T) error
+	dec   func(io.Reader) (T, error)
+	dir   string
+	limit int
+	mem   *heap.Heap[T]
+	runs  *heap.Heap[*run[T]]
+	n     int
+}
+
+// run is a sorted sequence of elements stored in a file. The head element is
+// held in memory and the remaining elements are read from the file as needed.
+type run[T any] struct {
+	f    *os.File
+	r    *bufio.Reader
+	head T
+	left int
+}
+
+// New returns a new ExtHeap that holds up to memLimit elements in memory.
+// Elements are written to run files by enc and read back by dec. Run files
+// are created in dir, or in the default directory for temporary files if dir
+// is empty.
+func New[T any](less func(a, b T) bool, enc func(io.Writer, T) error, dec func(io.Reader) (T, error), memLimit int, dir string) *ExtHeap[T] {
+	if memLimit < 1 {
+		panic("extheap: memory limit must be positive")
+	}
+	return &ExtHeap[T]{
+		less:  less,
+		enc:   enc,
+		dec:   dec,
+		dir:   dir,
+		limit: memLimit,
+		mem:   heap.New(less),
+		runs:  heap.New(runLess(less)),
+	}
+}
+
+func runLess[T any](less func(a, b T) bool) func(a, b *run[T]) bool {
+	return func(a, b *run[T]) bool {
+		return less(a.head, b.head)
+	}
+}
+
+// Len returns the number of elements in the heap.
+func (h *ExtHeap[T]) Len() int {
+	return h.n
+}
+
+// Runs returns the number of runs that are currently stored in files.
+func (h *ExtHeap[T]) Runs() int {
+	return h.runs.Len()
+}
+
+// Push adds an element to the heap. If the in-memory heap is full, its
+// elements are written to a new run file. If the run cannot be written, the
+// elements stay in memory and the error is returned. An error returned while
+// merging runs leaves the heap in an unknown state, and the heap should only
+// be closed.
+func (h *ExtHeap[T]) Push(x T) error {
+	h.mem.Push(x)
+	h.n++
+	if h.mem.Len() < h.limit {
+		return nil
+	}
+	return h.spill()
+}
+
+// Peek returns the minimum element in the heap without removing it.
+func (h *ExtHeap[T]) Peek() T {
+	if h.n == 0 {
+		panic("extheap: Peek called on empty heap")
+	}
+	if h.fromMem() {
+		return h.mem.Peek()
+	}
+	return h.runs.Peek().head
+}
+
+// Pop removes and returns the minimum element in the heap. An error is
+// returned if the next element of a run cannot be read, after which the heap
+// should only be closed.
+func (h *ExtHeap[T]) Pop() (T, error) {
+	if h.n == 0 {
+		panic("extheap: Pop called on empty heap")
+	}
+	h.n--
+	if h.fromMem() {
+		return h.mem.Pop(), nil
+	}
+	r := h.runs.Peek()
+	x := r.head
+	ok, err := r.next(h.dec)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	if ok {
+		h.runs.Fix(0)
+	} else {
+		h.runs.Pop()
+		if err = r.close(); err != nil {
+			return x, err
+		}
+	}
+	return x, nil
+}
+
+// Close removes all elements from the heap and deletes all run files.
+func (h *ExtHeap[T]) Close() error {
+	var err error
+	for h.runs.Len() != 0 {
+		if cerr := h.runs.Pop().close(); err == nil {
+			err = cerr
+		}
+	}
+	h.mem = heap.New(h.less)
+	h.n = 0
+	return err
+}
+
+// fromMem returns true if the minimum element is in the in-memory heap.
+func (h *ExtHeap[T]) fromMem() bool {
+	if h.runs.Len() == 0 {
+		return true
+	}
+	return h.mem.Len() != 0 && !h.less(h.runs.Peek().head, h.mem.Peek())
+}
+
+// spill writes the contents of the in-memory heap to a new run.
+func (h *ExtHeap[T]) spill() error {
+	f, w, err := h.create()
+	if err != nil {
+		return err
+	}
+	sorted := make([]T, 0, h.mem.Len())
+	for h.mem.Len() != 0 {
+		x := h.mem.Pop()
+		sorted = append(sorted, x)
+		if err = h.enc(w, x); err != nil {
+			break
+		}
+	}
+	r, err := h.finish(f, w, len(sorted), err)
+	if err != nil {
+		for _, x := range sorted {
+			h.mem.Push(x)
+		}
+		return err
+	}
+	h.runs.Push(r)
+	if h.runs.Len() > maxRuns {
+		return h.mergeRuns()
+	}
+	return nil
+}
+
+// mergeRuns merges the smaller half of the runs into a single run. Merging
+// the smallest runs keeps the total amount of data rewritten by merges
+// proportional to the log of the number of runs.
+func (h *ExtHeap[T]) mergeRuns() error {
+	runs := make([]*run[T], h.runs.Len())
+	for i := range runs {
+		runs[i] = h.runs.At(i)
+	}
+	slices.SortFunc(runs, func(a, b *run[T]) int {
+		return cmp.Compare(a.left, b.left)
+	})
+	small, keep := runs[:len(runs)/2], runs[len(runs)/2:]
+
+	f, w, err := h.create()
+	if err != nil {
+		return err
+	}
+	var n int
+	for _, r := range small {
+		n += r.left + 1
+	}
+	merge := heap.NewFrom(runLess(h.less), small...)
+	for merge.Len() != 0 {
+		r := merge.Peek()
+		if err = h.enc(w, r.head); err != nil {
+			break
+		}
+		var ok bool
+		if ok, err = r.next(h.dec); err != nil {
+			break
+		}
+		if ok {
+			merge.Fix(0)
+		} else {
+			merge.Pop()
+			if err = r.close(); err != nil {
+				break
+			}
+		}
+	}
+	r, err := h.finish(f, w, n, err)
+	if err != nil {
+		return err
+	}
+	h.runs = heap.NewFrom(runLess(h.less), append(keep, r)...)
+	return nil
+}
+
+// create creates a new run file and returns a buffered writer for it.
+func (h *ExtHeap[T]) create() (*os.File, *bufio.Writer, error) {
+	f, err := os.CreateTemp(h.dir, "extheap-*")
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, bufio.NewWriter(f), nil
+}
+
+// finish completes writing n elements to a run file and opens the run for
+// reading. If err is not nil, the file is removed and err is returned.
+func (h *ExtHeap[T]) finish(f *os.File, w *bufio.Writer, n int, err error) (*run[T], error) {
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+	r := &run[T]{
+		f:    f,
+		r:    bufio.NewReader(f),
+		left: n,
+	}
+	if err == nil {
+		_, err = r.next(h.dec)
+	}
+	if err != nil {
+		// The error that stopped the run being written is the one reported,
+		// rather than any error from discarding it.
+		_ = r.close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// next reads the next element of the run into head. It returns false if
+// there are no more elements.
+func (r *run[T]) next(dec func(io.Reader) (T, error)) (bool, error) {
+	if r.left == 0 {
+		return false, nil
+	}
+	x, err := dec(r.r)
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return false, err
+	}
+	r.head = x
+	r.left--
+	return true, nil
+}
+
+func (r *run[T]) close() error {
+	err := r.f.Close()
+	if rerr := os.Remove(r.f.Name()); err == nil {
+		err = rerr
+	}
+	return err
+}
@@ -0,0 +1,146 @@
+package extheap_test
+
+import (
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/gammazero/heap/extheap"
+)
+
+func encodeInt(w io.Writer, x int) error {
+	return binary.Write(w, binary.BigEndian, int64(x))
+}
+
+func decodeInt(r io.Reader) (int, error) {
+	var x int64
+	err := binary.Read(r, binary.BigEndian, &x)
+	return int(x), err
+}
+
+func TestExtHeap(t *testing.T) {
+	dir := t.TempDir()
+	h := extheap.New(cmp.Less[int], encodeInt, decodeInt, 50, dir)
+
+	const n = 20000
+	for range n {
+		if err := h.Push(rand.Intn(n)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if h.Len() != n {
+		t.Fatalf("expected length %d, got %d", n, h.Len())
+	}
+	if h.Runs() == 0 || h.Runs() > 64 {
+		t.Fatalf("unexpected number of runs: %d", h.Runs())
+	}
+
+	prev := -1
+	for i := range n {
+		peek := h.Peek()
+		x, err := h.Pop()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if x != peek {
+			t.Fatalf("Pop returned %d, Peek returned %d", x, peek)
+		}
+		if x < prev {
+			t.Fatalf("element %d out of order: %d < %d", i, x, prev)
+		}
+		prev = x
+		// Interleave pushes of larger elements with pops.
+		if i%3 == 0 && i < n/2 {
+			if err = h.Push(n + i); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	for h.Len() != 0 {
+		x, err := h.Pop()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if x < prev {
+			t.Fatalf("element out of order: %d < %d", x, prev)
+		}
+		prev = x
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 0 {
+		t.Fatalf("expected run files to be removed, found %d", len(files))
+	}
+}
+
+func TestClose(t *testing.T) {
+	dir := t.TempDir()
+	h := extheap.New(cmp.Less[int], encodeInt, decodeInt, 10, dir)
+	for i := range 100 {
+		if err := h.Push(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if h.Len() != 0 || h.Runs() != 0 {
+		t.Fatal("heap not empty after Close")
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 0 {
+		t.Fatalf("expected run files to be removed, found %d", len(files))
+	}
+}
+
+func TestSpillError(t *testing.T) {
+	errEnc := errors.New("encode failed")
+	enc := func(w io.Writer, x int) error {
+		if x == 3 {
+			return errEnc
+		}
+		return encodeInt(w, x)
+	}
+	dir := t.TempDir()
+	h := extheap.New(cmp.Less[int], enc, decodeInt, 5, dir)
+	for i := range 4 {
+		if err := h.Push(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := h.Push(4); err != errEnc {
+		t.Fatalf("expected encode error, got %v", err)
+	}
+	if h.Len() != 5 || h.Runs() != 0 {
+		t.Fatal("elements not kept in memory after failed spill")
+	}
+	for i := range 5 {
+		if x, _ := h.Pop(); x != i {
+			t.Fatalf("expected %d, got %d", i, x)
+		}
+	}
+	files, _ := os.ReadDir(dir)
+	if len(files) != 0 {
+		t.Fatal("run file not removed after failed spill")
+	}
+}
+
+func TestEmptyPanics(t *testing.T) {
+	h := extheap.New(cmp.Less[int], encodeInt, decodeInt, 10, "")
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+	_, _ = h.Pop()
+}
@@ -0,0 +1,70 @@
+package heap
+
+// FanIn presents several heaps as a single logical priority queue. Popping
+// from a FanIn removes the minimum element across all of its heaps, without
+// merging the heaps together. The heaps can still be used individually, for
+// example to push elements onto a per-tenant queue.
+//
+// Finding the minimum element examines the head of each heap, so the
+// complexity of Peek and Pop is O(k + log n), where k is the number of heaps.
+type FanIn[T any] struct {
+	heaps []*Heap[T]
+	less  func(a, b T) bool
+}
+
+// NewFanIn returns a FanIn over the given heaps. The less function is used to
+// compare elements from different heaps and must order elements the same way
+// as each heap's own less function.
+func NewFanIn[T any](less func(a, b T) bool, heaps ...*Heap[T]) *FanIn[T] {
+	return &FanIn[T]{
+		heaps: heaps,
+		less:  less,
+	}
+}
+
+// Add adds another heap to the FanIn.
+func (f *FanIn[T]) Add(h *Heap[T]) {
+	f.heaps = append(f.heaps, h)
+}
+
+// Len returns the total number of elements in all heaps.
+func (f *FanIn[T]) Len() int {
+	var n int
+	for _, h := range f.heaps {
+		n += h.Len()
+	}
+	return n
+}
+
+// Peek returns the minimum element across all heaps without removing it.
+func (f *FanIn[T]) Peek() T {
+	i := f.min()
+	if i < 0 {
+		panic("heap: Peek called on empty FanIn")
+	}
+	return f.heaps[i].Peek()
+}
+
+// Pop removes and returns the minimum element across all heaps.
+func (f *FanIn[T]) Pop() T {
+	i := f.min()
+	if i < 0 {
+		panic("heap: Pop called on empty FanIn")
+	}
+	return f.heaps[i].Pop()
+}
+
+// min returns the index of the heap with the minimum head, or -1 if all heaps
+// are empty.
+func (f *FanIn[T]) min() int {
+	m := -1
+	for i, h := range f.heaps {
+		if h.Len() == 0 {
+			continue
+		}
+		if m < 0 || f.less(h.Peek(), f.heaps[m].Peek()) {
+			m = i
+		}
+	}
+	return m
+}
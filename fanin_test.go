@@ -0,0 +1,59 @@
+package heap_test
+
+import (
+	"cmp"
+	"fmt"
+	"testing"
+
+	"github.com/gammazero/heap"
+)
+
+func TestFanIn(t *testing.T) {
+	less := cmp.Less[int]
+	a := heap.NewFrom(less, 9, 1, 5)
+	b := heap.NewFrom(less, 4, 8)
+	c := heap.New(less)
+	f := heap.NewFanIn(less, a, b, c)
+
+	if f.Len() != 5 {
+		t.Fatalf("expected length 5, got %d", f.Len())
+	}
+	if f.Peek() != 1 {
+		t.Fatalf("expected 1 at head, got %d", f.Peek())
+	}
+
+	c.Push(3)
+	f.Add(heap.NewFrom(less, 6, 2))
+
+	var got []int
+	for f.Len() != 0 {
+		got = append(got, f.Pop())
+	}
+	want := []int{1, 2, 3, 4, 5, 6, 8, 9}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	assertPanics(t, "should panic when popping empty FanIn", func() {
+		f.Pop()
+	})
+	assertPanics(t, "should panic when peeking empty FanIn", func() {
+		f.Peek()
+	})
+}
+
+func ExampleFanIn() {
+	less := func(a, b int) bool { return a < b }
+	tenantA := heap.NewFrom(less, 30, 10)
+	tenantB := heap.NewFrom(less, 20)
+
+	queue := heap.NewFanIn(less, tenantA, tenantB)
+	for queue.Len() != 0 {
+		fmt.Println(queue.Pop())
+	}
+
+	// Output:
+	// 10
+	// 20
+	// 30
+}
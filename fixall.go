@@ -0,0 +1,71 @@
+package heap
+
+import (
+	"math/bits"
+	"slices"
+)
+
+// FixAll re-establishes the heap ordering after the elements at the given
+// indexes have changed their values. Unlike calling Fix for each index, which
+// requires that only one element is out of place, FixAll gives the correct
+// result for any number of changed elements.
+//
+// When few elements have changed, only the changed elements and their
+// ancestors are sifted, in O(k log n) time for k changed elements. When many
+// have changed, the whole heap is rebuilt in O(n) time.
+func (h *Heap[T]) FixAll(indexes ...int) {
+	n := len(h.data)
+	for _, i := range indexes {
+		if i < 0 || i >= n {
+			panic("heap: FixAll index out of range")
+		}
+	}
+	h.ensureOrdered()
+	if h.guard != nil {
+		h.startWrite()
+	}
+	defer h.endOp()
+	h.modify()
+	if h.journal != nil {
+		for _, i := range indexes {
+			h.record(OpFix, h.data[i], n)
+		}
+	}
+	h.fixIndexes(indexes)
+}
+
+// fixIndexes re-establishes the heap ordering after the elements at the given
+// indexes have changed.
+func (h *Heap[T]) fixIndexes(indexes []int) {
+	if len(indexes)*bits.Len(uint(len(h.data))) >= len(h.data) {
+		h.heapify()
+		return
+	}
+
+	// Collect the changed nodes and their ancestors. Each of these nodes is
+	// the root of a subtree that may not be in heap order. All other nodes
+	// are roots of subtrees that are still in heap order.
+	nodes := make(map[int]struct{}, 2*len(indexes))
+	for _, i := range indexes {
+		for {
+			if _, ok := nodes[i]; ok {
+				break
+			}
+			nodes[i] = struct{}{}
+			if i == 0 {
+				break
+			}
+			i = (i - 1) / 2
+		}
+	}
+	order := make([]int, 0, len(nodes))
+	for i := range nodes {
+		order = append(order, i)
+	}
+	// Sift down from the bottom up, as heapify does, so that the subtrees
+	// of each node are in heap order when it is sifted.
+	slices.Sort(order)
+	for _, i := range slices.Backward(order) {
+		h.down(i)
+	}
+}
@@ -0,0 +1,50 @@
+package heap_test
+
+import (
+	"cmp"
+	"math/rand"
+	"testing"
+
+	"github.com/gammazero/heap"
+)
+
+func TestFixAll(t *testing.T) {
+	for _, changes := range []int{1, 5, 50, 1000} {
+		type item struct {
+			prio int
+			pos  int
+		}
+		h := heap.New(func(a, b *item) bool { return a.prio < b.prio })
+		h.SetOnMove(func(x *item, i int) { x.pos = i })
+		for range 1000 {
+			h.Push(&item{prio: rand.Intn(1000)})
+		}
+
+		// Change the priorities of several elements, then fix them all.
+		indexes := make([]int, changes)
+		for k := range indexes {
+			i := rand.Intn(h.Len())
+			h.At(i).prio = rand.Intn(2000) - 500
+			indexes[k] = i
+		}
+		h.FixAll(indexes...)
+
+		for i := range h.Len() {
+			if x := h.At(i); x.pos != i {
+				t.Fatalf("element at %d has tracked index %d", i, x.pos)
+			}
+		}
+		prev := -1 << 31
+		for h.Len() != 0 {
+			x := h.Pop()
+			if x.prio < prev {
+				t.Fatalf("%d changes: element out of order: %d < %d", changes, x.prio, prev)
+			}
+			prev = x.prio
+		}
+	}
+
+	h := heap.NewFrom(cmp.Less[int], 1, 2, 3)
+	h.FixAll()
+	assertPanics(t, "index out of range", func() { h.FixAll(0, 3) })
+}
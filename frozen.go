@@ -0,0 +1,92 @@
+package heap
+
+import "iter"
+
+// Frozen is a read-only view of a heap. It has no methods that modify the
+// heap, so it can be given to code that must be able to inspect a heap but
+// not change it. The view is not a copy: it shows the current elements of the
+// heap as the heap is modified through the Heap itself.
+//
+// Reading the view never modifies the heap. If the heap's elements are kept
+// unordered, because of a scan threshold or lazy ordering, methods that need
+// them in heap order order a copy instead.
+type Frozen[T any] struct {
+	h *Heap[T]
+}
+
+// Freeze returns a read-only view of the heap. The heap ordering is
+// established first, so that reading the view does not need to copy the
+// elements.
+func (h *Heap[T]) Freeze() Frozen[T] {
+	h.ensureOrdered()
+	return Frozen[T]{h: h}
+}
+
+// view returns the heap's elements in heap order without modifying the heap.
+func (f Frozen[T]) view() []T {
+	h := f.h
+	if h.guard != nil {
+		h.checkRead()
+	}
+	return h.heapOrder()
+}
+
+// Len returns the number of elements in the heap.
+func (f Frozen[T]) Len() int {
+	return f.h.Len()
+}
+
+// Peek returns the minimum element in the heap. It panics if the heap is
+// empty.
+func (f Frozen[T]) Peek() T {
+	if len(f.h.data) == 0 {
+		panic("heap: Peek called on empty heap")
+	}
+	return f.view()[0]
+}
+
+// PeekN returns up to n of the smallest elements in the heap, in order from
+// minimum to maximum, as by [Heap.PeekN].
+func (f Frozen[T]) PeekN(n int) []T {
+	if n < 0 {
+		panic("heap: PeekN count is negative")
+	}
+	return f.h.peekN(f.view(), n)
+}
+
+// At returns the element at index i in the heap.
+func (f Frozen[T]) At(i int) T {
+	if i < 0 || i >= len(f.h.data) {
+		panic("heap: At index out of range")
+	}
+	return f.view()[i]
+}
+
+// Sorted returns a copy of the heap's elements in order from minimum to
+// maximum.
+func (f Frozen[T]) Sorted() []T {
+	return f.h.peekN(f.view(), len(f.h.data))
+}
+
+// All returns an iterator over the elements of the heap in heap order.
+func (f Frozen[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		h := f.h
+		data := f.view()
+		v := h.version
+		for _, x := range data {
+			if !yield(x) {
+				return
+			}
+			h.checkVersion(v)
+		}
+	}
+}
+
+// Ascend returns an iterator over the elements of the heap in order from
+// minimum to maximum, as by [Heap.Ascend].
+func (f Frozen[T]) Ascend() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		f.h.ascend(f.view(), yield)
+	}
+}
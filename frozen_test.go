@@ -0,0 +1,69 @@
+package heap_test
+
+import (
+	"cmp"
+	"slices"
+	"testing"
+
+	"github.com/gammazero/heap"
+)
+
+func TestFreeze(t *testing.T) {
+	h := heap.NewFrom(cmp.Less[int], 5, 2, 8, 1)
+	f := h.Freeze()
+	if f.Len() != 4 || f.Peek() != 1 || f.At(0) != 1 {
+		t.Fatal("wrong view of heap")
+	}
+	if s := f.Sorted(); !slices.Equal(s, []int{1, 2, 5, 8}) {
+		t.Fatalf("wrong sorted elements %v", s)
+	}
+	if top := f.PeekN(2); !slices.Equal(top, []int{1, 2}) {
+		t.Fatalf("wrong top elements %v", top)
+	}
+	if n := len(slices.Collect(f.All())); n != 4 {
+		t.Fatalf("expected 4 elements, got %d", n)
+	}
+
+	// The view follows changes made to the heap.
+	h.Pop()
+	if f.Len() != 3 || f.Peek() != 2 {
+		t.Fatal("view does not show change to heap")
+	}
+	if h.Len() != 3 {
+		t.Fatal("reading view modified heap")
+	}
+}
+
+func TestFreezeUnordered(t *testing.T) {
+	var moves int
+	h := heap.New(cmp.Less[int],
+		heap.WithScanThreshold[int](8),
+		heap.WithOnMove(func(int, int) { moves++ }))
+	h.Push(5)
+	h.Push(3)
+	f := h.Freeze()
+	// Pushing below the scan threshold leaves the elements unordered again.
+	h.Push(1)
+	h.Push(4)
+
+	// Reading the view must not reorder the heap.
+	moves = 0
+	if f.Peek() != 1 || f.At(0) != 1 {
+		t.Fatal("wrong minimum element")
+	}
+	if s := f.Sorted(); !slices.Equal(s, []int{1, 3, 4, 5}) {
+		t.Fatalf("wrong sorted elements %v", s)
+	}
+	if top := f.PeekN(2); !slices.Equal(top, []int{1, 3}) {
+		t.Fatalf("wrong top elements %v", top)
+	}
+	if s := slices.Collect(f.Ascend()); !slices.Equal(s, []int{1, 3, 4, 5}) {
+		t.Fatalf("wrong ascending elements %v", s)
+	}
+	if n := len(slices.Collect(f.All())); n != 4 {
+		t.Fatalf("expected 4 elements, got %d", n)
+	}
+	if moves != 0 {
+		t.Fatalf("reading view moved %d elements", moves)
+	}
+}
@@ -0,0 +1,50 @@
+package heap
+
+// Growth returns the new capacity for the heap's storage when the storage is
+// full, holding n elements. The returned capacity must be greater than n.
+type Growth func(n int) int
+
+// GrowBy returns a Growth that adds room for a fixed number of elements each
+// time the storage is full.
+func GrowBy(increment int) Growth {
+	if increment < 1 {
+		panic("heap: growth increment must be positive")
+	}
+	return func(n int) int {
+		return n + increment
+	}
+}
+
+// GrowFactor returns a Growth that multiplies the capacity by factor each time
+// the storage is full. Factor must be greater than 1.
+func GrowFactor(factor float64) Growth {
+	if !(factor > 1) {
+		panic("heap: growth factor must be greater than 1")
+	}
+	return func(n int) int {
+		return max(int(float64(n)*factor), n+1)
+	}
+}
+
+// SetGrowth sets how the heap's storage grows when an element is pushed and
+// the storage is full. By default, the storage grows as it does when using
+// append. Setting growth to nil restores the default.
+func (h *Heap[T]) SetGrowth(growth Growth) {
+	h.growth = growth
+}
+
+// grow makes room for one more element in the heap's storage, if the storage
+// is full and a Growth is set.
+func (h *Heap[T]) grow() {
+	n := len(h.data)
+	if h.growth == nil || n < cap(h.data) {
+		return
+	}
+	newCap := h.growth(n)
+	if newCap <= n {
+		panic("heap: Growth returned capacity too small")
+	}
+	data := make([]T, n, newCap)
+	copy(data, h.data)
+	h.data = data
+}
@@ -0,0 +1,51 @@
+package heap_test
+
+import (
+	"cmp"
+	"testing"
+
+	"github.com/gammazero/heap"
+)
+
+// storageCap returns the capacity of an int64 heap's storage.
+func storageCap(h *heap.Heap[int64]) int {
+	empty := heap.New(cmp.Less[int64]).SizeOf(nil)
+	return int((h.SizeOf(nil) - empty) / 8)
+}
+
+func TestGrowth(t *testing.T) {
+	h := heap.New(cmp.Less[int64])
+	h.SetGrowth(heap.GrowBy(10))
+	for i := range 25 {
+		h.Push(int64(25 - i))
+	}
+	if c := storageCap(h); c != 30 {
+		t.Fatalf("expected capacity 30, got %d", c)
+	}
+
+	h.SetGrowth(heap.GrowFactor(1.25))
+	for i := range 6 {
+		h.Push(int64(i))
+	}
+	// Full at 30, grows to 37.
+	if c := storageCap(h); c != 37 {
+		t.Fatalf("expected capacity 37, got %d", c)
+	}
+	prev := int64(-1)
+	for h.Len() != 0 {
+		x := h.Pop()
+		if x < prev {
+			t.Fatalf("element out of order: %d < %d", x, prev)
+		}
+		prev = x
+	}
+
+	h.SetGrowth(func(n int) int { return n })
+	assertPanics(t, "capacity too small", func() {
+		for i := range 100 {
+			h.Push(int64(i))
+		}
+	})
+	assertPanics(t, "zero increment", func() { heap.GrowBy(0) })
+	assertPanics(t, "factor 1", func() { heap.GrowFactor(1) })
+}
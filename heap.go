@@ -9,42 +9,178 @@
 // items and `Pop` to remove the item with the greatest precedence.
 package heap
 
+import "sync/atomic"
+
 // Heap implements a binary heap.
+//
+// The zero value of Heap is an empty heap with no less function. Its Len can
+// be called, but [Heap.SetLess] must be called before elements are pushed.
+// This allows a Heap to be embedded by value in another struct.
 type Heap[T any] struct {
-	data []T
-	less func(a, b T) bool
+	data    []T
+	less    func(a, b T) bool
+	onMove  func(x T, i int)
+	wm      *watermarks
+	guard   *atomic.Int32
+	stats   *heapStats[T]
+	metrics Metrics
+
+	growth  Growth
+	ordered *orderedOps[T]
+	// scanMax is the number of elements up to which elements are kept
+	// unordered, and unordered is true when they are.
+	scanMax   int
+	unordered bool
+	lazy      bool
+	// pointerFree is true if T contains no pointers, so vacated slots do
+	// not need to be zeroed.
+	pointerFree bool
+	// version is incremented each time the heap is modified, so that
+	// iterators can detect modification during iteration.
+	version uint64
+
+	journal  *Journal
+	describe func(T) string
+	undo     *undoState[T]
+	// shared is true if the array holding data is shared with a Pages
+	// iterator, so it must be copied before it is modified.
+	shared bool
+
+	// notices holds the notifications queued by the current operation, to
+	// be delivered when it completes, and flushing is true while they are.
+	notices  []notice[T]
+	flushing bool
 }
 
 // New returns a new heap with the given less function. The less function
-// returns whether 'a' is less than 'b'.
-func New[T any](less func(a, b T) bool) *Heap[T] {
-	return &Heap[T]{
-		less: less,
+// returns whether 'a' is less than 'b'. Options can be given to configure the
+// heap.
+func New[T any](less func(a, b T) bool, opts ...Option[T]) *Heap[T] {
+	h := &Heap[T]{
+		less:        less,
+		pointerFree: !hasPointers[T](),
 	}
+	h.apply(opts)
+	return h
 }
 
 // NewFrom returns a new heap with the given less function and initial data.
+//
+// Very large inputs are heapified using multiple goroutines, so the less
+// function must be safe to call concurrently.
 func NewFrom[T any](less func(a, b T) bool, data ...T) *Heap[T] {
-	n := len(data)
 	h := &Heap[T]{
-		less: less,
-		data: data,
-	}
-	for i := n/2 - 1; i >= 0; i-- {
-		h.down(i)
+		less:        less,
+		data:        data,
+		pointerFree: !hasPointers[T](),
 	}
+	h.heapifyNew()
 	return h
 }
 
+// SetLess sets the heap's less function, replacing the one given to New, and
+// restores the heap ordering of any elements in the heap using the new
+// function. SetLess must be called before using the zero value of Heap.
+func (h *Heap[T]) SetLess(less func(a, b T) bool) {
+	if less == nil {
+		panic("heap: nil less function")
+	}
+	if h.guard != nil {
+		h.startWrite()
+	}
+	defer h.endOp()
+	if h.stats != nil {
+		h.stats.less = less
+		h.less = h.stats.compare
+	} else {
+		h.less = less
+	}
+	// The specialized sift functions of an ordered heap do not use less.
+	h.ordered = nil
+	if h.undo != nil {
+		h.undo.lessChanged = true
+	}
+	if h.data == nil {
+		h.pointerFree = !hasPointers[T]()
+	}
+	h.heapify()
+}
+
+// mustHaveLess panics if the heap has no less function, so that using a zero
+// Heap without calling SetLess fails with a clear message.
+func (h *Heap[T]) mustHaveLess() {
+	if h.less == nil {
+		panic("heap: less function not set, call SetLess before use")
+	}
+}
+
+// SetOnMove sets a function that is called with an element and its new index
+// each time the element is placed at a different index in the heap, and with
+// index -1 when the element is removed from the heap. This lets elements keep
+// track of their own index, so they can later be passed to [Heap.Fix],
+// [Heap.Remove], or [Heap.Set]. Setting fn to nil stops the calls.
+//
+// The function is not called for elements that are already in the heap when
+// SetOnMove is called. It is called while the heap is being modified, so it
+// must not call any methods of the heap.
+func (h *Heap[T]) SetOnMove(fn func(x T, i int)) {
+	h.onMove = fn
+}
+
 // Len returns the number of elements in the heap.
 func (h *Heap[T]) Len() int {
+	if h.guard != nil {
+		h.checkRead()
+	}
 	return len(h.data)
 }
 
+// Cap returns the number of elements the heap's storage can hold before it
+// must grow.
+func (h *Heap[T]) Cap() int {
+	if h.guard != nil {
+		h.checkRead()
+	}
+	return cap(h.data)
+}
+
 // Push pushes the given element onto the heap.
 func (h *Heap[T]) Push(x T) {
-	h.data = append(h.data, x)
-	h.up(len(h.data) - 1)
+	h.mustHaveLess()
+	if h.guard != nil {
+		h.startWrite()
+	}
+	defer h.endOp()
+	if h.stats != nil {
+		h.stats.Pushes++
+		if len(h.data) == cap(h.data) {
+			h.stats.Reallocs++
+		}
+	}
+	h.modify()
+	c := cap(h.data)
+	h.grow()
+	switch {
+	case h.lazy && (h.unordered || len(h.data) == 0):
+		h.pushUnordered(x)
+		h.unordered = true
+	case len(h.data) < h.scanMax:
+		h.pushUnordered(x)
+	default:
+		if h.unordered {
+			h.heapify()
+		}
+		h.data = append(h.data, x)
+		if h.onMove != nil {
+			h.onMove(x, len(h.data)-1)
+		}
+		h.up(len(h.data) - 1)
+	}
+	h.watermarksChanged()
+	h.notifyPush(len(h.data), c)
+	if h.journal != nil {
+		h.record(OpPush, x, len(h.data))
+	}
 }
 
 // Pop removes and returns the minimum element from the heap. If the heap is
@@ -53,14 +189,43 @@ func (h *Heap[T]) Pop() T {
 	if len(h.data) == 0 {
 		panic("heap: Pop called on empty heap")
 	}
+	if h.guard != nil {
+		h.startWrite()
+	}
+	defer h.endOp()
+	var x T
+	if h.unordered && len(h.data) <= h.scanMax {
+		x = h.popScan()
+	} else {
+		if h.unordered {
+			h.heapify()
+		}
+		x = h.pop()
+	}
+	h.notifyPop(len(h.data))
+	if h.journal != nil {
+		h.record(OpPop, x, len(h.data))
+	}
+	return x
+}
 
-	var zero T
+func (h *Heap[T]) pop() T {
+	if h.stats != nil {
+		h.stats.Pops++
+	}
+	h.modify()
 	x := h.data[0]
 	n := len(h.data) - 1
 	h.data[0] = h.data[n]
-	h.data[n] = zero
+	h.clearSlot(n)
 	h.data = h.data[:n]
-	h.down(0)
+	if h.onMove != nil {
+		h.onMove(x, -1)
+	}
+	if n != 0 {
+		h.downBottomUp(0)
+	}
+	h.watermarksChanged()
 
 	return x
 }
@@ -71,7 +236,14 @@ func (h *Heap[T]) Peek() T {
 	if len(h.data) == 0 {
 		panic("heap: Peek called on empty heap")
 	}
-
+	if h.guard != nil {
+		h.checkRead()
+	}
+	// Reading the heap never establishes the heap ordering, so the minimum
+	// of unordered elements is found by scanning them.
+	if h.unordered {
+		return h.data[h.minIndex()]
+	}
 	return h.data[0]
 }
 
@@ -82,23 +254,49 @@ func (h *Heap[T]) Remove(i int) T {
 	if i < 0 || i > n {
 		panic("heap: Remove index out of range")
 	}
+	if h.guard != nil {
+		h.startWrite()
+	}
+	defer h.endOp()
+	if h.unordered {
+		h.heapify()
+	}
 	if i == 0 {
-		return h.Pop()
+		x := h.pop()
+		h.notifyRemove(1, len(h.data))
+		if h.journal != nil {
+			h.record(OpRemove, x, len(h.data))
+		}
+		return x
 	}
 
-	var zero T
+	if h.stats != nil {
+		h.stats.Pops++
+	}
+	h.modify()
 	x := h.data[i]
 	if n != i {
 		h.data[i] = h.data[n]
-		h.data[n] = zero
+		h.clearSlot(n)
 		h.data = h.data[:n]
+		if h.onMove != nil {
+			h.onMove(h.data[i], i)
+		}
 		if !h.down(i) {
 			h.up(i)
 		}
 	} else {
-		h.data[n] = zero
+		h.clearSlot(n)
 		h.data = h.data[:n]
 	}
+	if h.onMove != nil {
+		h.onMove(x, -1)
+	}
+	h.watermarksChanged()
+	h.notifyRemove(1, n)
+	if h.journal != nil {
+		h.record(OpRemove, x, n)
+	}
 	return x
 }
 
@@ -107,7 +305,10 @@ func (h *Heap[T]) At(i int) T {
 	if i < 0 || i >= len(h.data) {
 		panic("heap: At index out of range")
 	}
-	return h.data[i]
+	if h.guard != nil {
+		h.checkRead()
+	}
+	return h.heapOrder()[i]
 }
 
 // Set replaces the element at index i in the heap and then calls [Fix] to
@@ -116,8 +317,24 @@ func (h *Heap[T]) Set(i int, x T) {
 	if i < 0 || i >= len(h.data) {
 		panic("heap: Set index out of range")
 	}
+	if h.guard != nil {
+		h.startWrite()
+	}
+	defer h.endOp()
+	if h.unordered {
+		h.heapify()
+	}
+	h.modify()
+	old := h.data[i]
 	h.data[i] = x
-	h.Fix(i)
+	if h.onMove != nil {
+		h.onMove(old, -1)
+		h.onMove(x, i)
+	}
+	if h.journal != nil {
+		h.recordSet(x, old, len(h.data))
+	}
+	h.fix(i)
 }
 
 // Fix re-establishes the heap ordering after the element at index i has changed its value.
@@ -128,12 +345,31 @@ func (h *Heap[T]) Fix(i int) {
 	if i < 0 || i >= len(h.data) {
 		panic("heap: Fix index out of range")
 	}
+	if h.guard != nil {
+		h.startWrite()
+	}
+	defer h.endOp()
+	if h.unordered {
+		h.heapify()
+	}
+	h.modify()
+	if h.journal != nil {
+		h.record(OpFix, h.data[i], len(h.data))
+	}
+	h.fix(i)
+}
+
+func (h *Heap[T]) fix(i int) {
 	if !h.down(i) {
 		h.up(i)
 	}
 }
 
 func (h *Heap[T]) down(i int) bool {
+	if h.useOrdered() {
+		return h.ordered.down(h.data, i)
+	}
+	h.mustHaveLess()
 	data := h.data
 	n := len(data)
 	less := h.less
@@ -152,14 +388,80 @@ func (h *Heap[T]) down(i int) bool {
 			break
 		}
 		data[i], data[j] = data[j], data[i]
+		if h.onMove != nil {
+			h.onMove(data[i], i)
+			h.onMove(data[j], j)
+		}
 		i = j
 	}
+	if h.stats != nil {
+		h.addSift(i0, i)
+	}
 	return i > i0
 }
 
+// downBottomUp moves the element at index i down to its place in the heap,
+// like down, using fewer comparisons when the element belongs near the bottom
+// of the heap, as the element moved to the root by Pop usually does. It first
+// moves the hole left by the element down the path of smaller children to a
+// leaf, taking one comparison per level, and then moves the element up from
+// the leaf to its place.
+func (h *Heap[T]) downBottomUp(i int) {
+	if h.useOrdered() {
+		h.ordered.down(h.data, i)
+		return
+	}
+	h.mustHaveLess()
+	data := h.data
+	n := len(data)
+	less := h.less
+	x := data[i]
+	j := i
+	for {
+		left := 2*j + 1
+		if left >= n || left < 0 { // left < 0 after int overflow
+			break
+		}
+		c := left
+		if right := left + 1; right < n && less(data[right], data[left]) {
+			c = right
+		}
+		data[j] = data[c]
+		if h.onMove != nil {
+			h.onMove(data[j], j)
+		}
+		j = c
+	}
+	leaf := j
+	for j > i {
+		parent := (j - 1) / 2
+		if !less(x, data[parent]) {
+			break
+		}
+		data[j] = data[parent]
+		if h.onMove != nil {
+			h.onMove(data[j], j)
+		}
+		j = parent
+	}
+	data[j] = x
+	if h.onMove != nil {
+		h.onMove(x, j)
+	}
+	if h.stats != nil {
+		h.addSift(i, leaf)
+		h.addSift(j, leaf)
+	}
+}
+
 func (h *Heap[T]) up(i int) {
+	if h.useOrdered() {
+		h.ordered.up(h.data, i)
+		return
+	}
 	data := h.data
 	less := h.less
+	i0 := i
 	for {
 		parent := (i - 1) / 2
 		if i == 0 || !less(data[i], data[parent]) {
@@ -167,6 +469,13 @@ func (h *Heap[T]) up(i int) {
 		}
 
 		data[i], data[parent] = data[parent], data[i]
+		if h.onMove != nil {
+			h.onMove(data[i], i)
+			h.onMove(data[parent], parent)
+		}
 		i = parent
 	}
+	if h.stats != nil {
+		h.addSift(i, i0)
+	}
 }
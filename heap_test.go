@@ -450,3 +450,129 @@ func ExampleHeap_Set() {
 	// Peek before: 100
 	// Peek after: 200
 }
+
+func TestSetOnMove(t *testing.T) {
+	type item struct {
+		val   int
+		index int
+	}
+	h := heap.New(func(a, b *item) bool { return a.val < b.val })
+	h.SetOnMove(func(x *item, i int) { x.index = i })
+
+	checkIndexes := func() {
+		t.Helper()
+		for i := 0; i < h.Len(); i++ {
+			if x := h.At(i); x.index != i {
+				t.Fatalf("element %d has index %d, expected %d", x.val, x.index, i)
+			}
+		}
+	}
+
+	items := make([]*item, 20)
+	for i := range items {
+		items[i] = &item{val: rand.Intn(100)}
+		h.Push(items[i])
+		checkIndexes()
+	}
+
+	items[5].val = -1
+	h.Fix(items[5].index)
+	checkIndexes()
+	if h.Peek() != items[5] {
+		t.Fatal("expected fixed element at head")
+	}
+
+	removed := h.Remove(items[7].index)
+	if removed != items[7] || removed.index != -1 {
+		t.Fatalf("unexpected removed element %+v", removed)
+	}
+	checkIndexes()
+
+	replaced := h.At(items[3].index)
+	h.Set(items[3].index, &item{val: 1000})
+	if replaced.index != -1 {
+		t.Fatal("expected replaced element to have index -1")
+	}
+	checkIndexes()
+
+	for h.Len() != 0 {
+		x := h.Pop()
+		if x.index != -1 {
+			t.Fatalf("popped element has index %d", x.index)
+		}
+		checkIndexes()
+	}
+}
+
+func TestPopComparisons(t *testing.T) {
+	const n = 1 << 12
+	var count int
+	h := heap.New(func(a, b int) bool {
+		count++
+		return a < b
+	})
+	for range n {
+		h.Push(rand.Intn(n))
+	}
+	count = 0
+	prev := -1
+	for h.Len() != 0 {
+		x := h.Pop()
+		if x < prev {
+			t.Fatalf("element out of order: %d < %d", x, prev)
+		}
+		prev = x
+	}
+	// Sifting down with one comparison per level takes about n*log2(n)
+	// comparisons, compared to about 2*n*log2(n) for a standard sift.
+	if limit := n * 12 * 5 / 4; count > limit {
+		t.Fatalf("too many comparisons: %d > %d", count, limit)
+	}
+}
+
+func TestZeroValue(t *testing.T) {
+	var q struct {
+		name string
+		h    heap.Heap[int]
+	}
+	if q.h.Len() != 0 {
+		t.Fatal("expected empty heap")
+	}
+	assertPanics(t, "should panic when pushing without less function", func() {
+		q.h.Push(1)
+	})
+
+	q.h.SetLess(cmp.Less[int])
+	for _, x := range []int{5, 3, 8, 1} {
+		q.h.Push(x)
+	}
+	for _, want := range []int{1, 3, 5, 8} {
+		if x := q.h.Pop(); x != want {
+			t.Fatalf("expected %d, got %d", want, x)
+		}
+	}
+}
+
+func TestSetLess(t *testing.T) {
+	h := heap.NewOrdered(5, 3, 8, 1)
+	h.SetLess(func(a, b int) bool { return a > b })
+	if x := h.Pop(); x != 8 {
+		t.Fatalf("expected 8 after reversing order, got %d", x)
+	}
+	h.Push(10)
+	if x := h.Peek(); x != 10 {
+		t.Fatalf("expected 10, got %d", x)
+	}
+
+	h.EnableStats(true)
+	h.SetLess(cmp.Less[int])
+	if x := h.Pop(); x != 1 {
+		t.Fatalf("expected 1, got %d", x)
+	}
+	if h.Stats().Compares == 0 {
+		t.Fatal("comparisons not counted after SetLess")
+	}
+	assertPanics(t, "should panic when setting nil less function", func() {
+		h.SetLess(nil)
+	})
+}
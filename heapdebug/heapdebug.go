@@ -0,0 +1,160 @@
+// Package heapdebug provides an HTTP handler that reports the state of a heap
+// or priority queue, for inspecting a running program. The handler can be
+// mounted alongside the net/http/pprof handlers:
+//
+//	var mu sync.Mutex
+//	h := heap.New(lessTask)
+//	http.Handle("/debug/prioq", heapdebug.New(heapdebug.Heap(h, &mu, taskCreated)))
+//
+// The handler renders HTML, or JSON if the request has the query parameter
+// format=json or accepts application/json. The query parameter n sets the
+// number of top entries shown, which defaults to DefaultTop.
+package heapdebug
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gammazero/heap"
+)
+
+const (
+	// DefaultTop is the number of top entries shown when the request does not
+	// give a number.
+	DefaultTop = 10
+	// MaxTop is the largest number of top entries shown.
+	MaxTop = 1000
+)
+
+// State is the state of a queue at one point in time.
+type State struct {
+	// Len is the number of entries in the queue.
+	Len int `json:"len"`
+	// Cap is the number of entries the queue can hold without allocating.
+	Cap int `json:"cap"`
+	// Head is the entry at the head of the queue, or nil if it is empty.
+	Head any `json:"head,omitempty"`
+	// HeadAge is how long the head entry has been waiting, or 0 if unknown.
+	HeadAge time.Duration `json:"head_age,omitempty"`
+	// Top holds the entries at the head of the queue, in order.
+	Top []any `json:"top"`
+}
+
+// Source returns the current state of a queue, with up to n top entries.
+type Source func(n int) State
+
+// Heap returns a Source for h. If mu is not nil, it is held while h is read,
+// and must be the lock that guards all other uses of h. If created is not nil,
+// it returns the time an element was created, and is used to report the age
+// of the head element.
+func Heap[T any](h *heap.Heap[T], mu sync.Locker, created func(T) time.Time) Source {
+	return func(n int) State {
+		if mu != nil {
+			mu.Lock()
+			defer mu.Unlock()
+		}
+		s := State{
+			Len: h.Len(),
+			Cap: h.Cap(),
+		}
+		if s.Len == 0 {
+			return s
+		}
+		head := h.Peek()
+		s.Head = head
+		if created != nil {
+			s.HeadAge = time.Since(created(head))
+		}
+		for _, x := range h.PeekN(n) {
+			s.Top = append(s.Top, x)
+		}
+		return s
+	}
+}
+
+type handler struct {
+	src Source
+}
+
+// New returns an HTTP handler that reports the state returned by src.
+func New(src Source) http.Handler {
+	return handler{src: src}
+}
+
+func (hd handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := DefaultTop
+	if v := r.FormValue("n"); v != "" {
+		var err error
+		n, err = strconv.Atoi(v)
+		if err != nil || n < 0 {
+			http.Error(w, "heapdebug: invalid n", http.StatusBadRequest)
+			return
+		}
+		n = min(n, MaxTop)
+	}
+	s := hd.src(n)
+	if s.Top == nil {
+		s.Top = []any{}
+	}
+
+	// The response is rendered into a buffer so that an encoding error can
+	// still be reported with an error status.
+	var buf bytes.Buffer
+	var err error
+	contentType := "text/html; charset=utf-8"
+	if r.FormValue("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json") {
+		contentType = "application/json"
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		js := jsonState{State: s}
+		if s.HeadAge != 0 {
+			js.HeadAge = s.HeadAge.String()
+		}
+		err = enc.Encode(js)
+	} else {
+		err = page.Execute(&buf, s)
+	}
+	if err != nil {
+		http.Error(w, "heapdebug: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Content-Type", contentType)
+	// An error writing the response means the client has gone away, and
+	// there is no one left to report it to.
+	_, _ = buf.WriteTo(w)
+}
+
+// jsonState encodes the head age as a string, such as "1.5s", rather than a
+// number of nanoseconds.
+type jsonState struct {
+	State
+	HeadAge string `json:"head_age,omitempty"`
+}
+
+var page = template.Must(template.New("heapdebug").Funcs(template.FuncMap{
+	"str": func(x any) string { return fmt.Sprint(x) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head><title>heap</title></head>
+<body>
+<table>
+<tr><th align="left">length</th><td>{{.Len}}</td></tr>
+<tr><th align="left">capacity</th><td>{{.Cap}}</td></tr>
+{{if .Len}}<tr><th align="left">head</th><td>{{str .Head}}</td></tr>
+{{if .HeadAge}}<tr><th align="left">head age</th><td>{{.HeadAge}}</td></tr>
+{{end}}{{end}}</table>
+<h3>top {{len .Top}}</h3>
+<ol start="0">
+{{range .Top}}<li>{{str .}}</li>
+{{end}}</ol>
+</body>
+</html>
+`))
@@ -0,0 +1,140 @@
+package heapdebug_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gammazero/heap"
+	"github.com/gammazero/heap/heapdebug"
+)
+
+type task struct {
+	Name     string
+	Priority int
+	created  time.Time
+}
+
+func newHandler() (*heap.Heap[task], *sync.Mutex, *httptest.Server) {
+	var mu sync.Mutex
+	h := heap.New(func(a, b task) bool { return a.Priority < b.Priority })
+	src := heapdebug.Heap(h, &mu, func(t task) time.Time { return t.created })
+	return h, &mu, httptest.NewServer(heapdebug.New(src))
+}
+
+// get requests srv with the given query and returns the response and its
+// body.
+func get(t *testing.T, srv *httptest.Server, query string) (*http.Response, []byte) {
+	t.Helper()
+	resp, err := srv.Client().Get(srv.URL + query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if cerr := resp.Body.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, body
+}
+
+func TestJSON(t *testing.T) {
+	h, mu, srv := newHandler()
+	defer srv.Close()
+
+	mu.Lock()
+	for i := range 20 {
+		h.Push(task{Name: string(rune('a' + i)), Priority: 20 - i, created: time.Now().Add(-time.Minute)})
+	}
+	mu.Unlock()
+
+	resp, body := get(t, srv, "?format=json&n=3")
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("wrong content type %q", ct)
+	}
+	var s struct {
+		Len     int    `json:"len"`
+		Cap     int    `json:"cap"`
+		Head    task   `json:"head"`
+		HeadAge string `json:"head_age"`
+		Top     []task `json:"top"`
+	}
+	if err := json.Unmarshal(body, &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Len != 20 || s.Cap < 20 {
+		t.Fatalf("wrong length %d or capacity %d", s.Len, s.Cap)
+	}
+	if s.Head.Name != "t" {
+		t.Fatalf("wrong head %+v", s.Head)
+	}
+	if age, err := time.ParseDuration(s.HeadAge); err != nil || age < time.Minute {
+		t.Fatalf("wrong head age %q", s.HeadAge)
+	}
+	if len(s.Top) != 3 || s.Top[0].Name != "t" || s.Top[1].Name != "s" || s.Top[2].Name != "r" {
+		t.Fatalf("wrong top entries %+v", s.Top)
+	}
+}
+
+func TestHTML(t *testing.T) {
+	h, mu, srv := newHandler()
+	defer srv.Close()
+
+	mu.Lock()
+	h.Push(task{Name: "<script>", Priority: 1, created: time.Now()})
+	mu.Unlock()
+
+	resp, b := get(t, srv, "")
+	body := string(b)
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		t.Fatal("expected html response")
+	}
+	if strings.Contains(body, "<script>") || !strings.Contains(body, "&lt;script&gt;") {
+		t.Fatal("element not escaped in html")
+	}
+}
+
+func TestEmptyAndBadRequest(t *testing.T) {
+	_, _, srv := newHandler()
+	defer srv.Close()
+
+	_, body := get(t, srv, "?format=json")
+	var s map[string]any
+	if err := json.Unmarshal(body, &s); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s["head"]; ok {
+		t.Fatal("expected no head for empty heap")
+	}
+	if top, ok := s["top"].([]any); !ok || len(top) != 0 {
+		t.Fatalf("expected empty top list, got %v", s["top"])
+	}
+
+	resp, _ := get(t, srv, "?n=x")
+	if resp.StatusCode != 400 {
+		t.Fatalf("expected status 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestEncodeError(t *testing.T) {
+	src := func(n int) heapdebug.State {
+		return heapdebug.State{Len: 1, Top: []any{func() {}}}
+	}
+	srv := httptest.NewServer(heapdebug.New(src))
+	defer srv.Close()
+
+	resp, body := get(t, srv, "?format=json")
+	if resp.StatusCode != 500 {
+		t.Fatalf("expected status 500, got %d", resp.StatusCode)
+	}
+	if !strings.HasPrefix(string(body), "heapdebug: ") {
+		t.Fatalf("expected error message, got %q", body)
+	}
+}
@@ -0,0 +1,78 @@
+package heap
+
+import (
+	"math/bits"
+	"runtime"
+	"sync"
+)
+
+const (
+	// parallelHeapifyMin is the number of elements at which a heap built by
+	// NewFrom is heapified using multiple goroutines.
+	parallelHeapifyMin = 1 << 17
+	// parallelChunkMin is the minimum number of nodes sifted by each
+	// goroutine during a parallel heapify.
+	parallelChunkMin = 1 << 10
+)
+
+// heapify establishes the heap ordering over all of the heap's data in O(n).
+// The less function is only called from the calling goroutine.
+func (h *Heap[T]) heapify() {
+	h.modify()
+	h.unordered = false
+	for i := len(h.data)/2 - 1; i >= 0; i-- {
+		h.down(i)
+	}
+}
+
+// heapifyNew is heapify for a heap being created by NewFrom, whose less
+// function is documented to be called concurrently, or by a constructor that
+// compares elements with built-in operators. Very large heaps are heapified
+// using multiple goroutines. All other methods use heapify, so that a less
+// function that is not safe for concurrent use is only a problem for NewFrom.
+func (h *Heap[T]) heapifyNew() {
+	if len(h.data) >= parallelHeapifyMin && h.onMove == nil && h.stats == nil {
+		if workers := runtime.GOMAXPROCS(0); workers > 1 {
+			h.modify()
+			h.unordered = false
+			h.heapifyParallel(workers)
+			return
+		}
+	}
+	h.heapify()
+}
+
+// heapifyParallel heapifies the lower levels of the tree across multiple
+// goroutines. Nodes on the same level are roots of disjoint subtrees, so they
+// can be sifted down concurrently once all levels below them are done. The
+// top levels, which have too few nodes to split up, are done sequentially.
+func (h *Heap[T]) heapifyParallel(workers int) {
+	last := len(h.data)/2 - 1 // last node that has a child
+	level := bits.Len(uint(last+1)) - 1
+
+	var wg sync.WaitGroup
+	for ; level >= 0; level-- {
+		start := 1<<level - 1
+		end := min(2*start+1, last+1)
+		count := end - start
+		if count < 2*parallelChunkMin {
+			break
+		}
+		chunk := max((count+workers-1)/workers, parallelChunkMin)
+		for lo := start; lo < end; lo += chunk {
+			hi := min(lo+chunk, end)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := hi - 1; i >= lo; i-- {
+					h.down(i)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	for i := min(1<<(level+1)-1, last+1) - 1; i >= 0; i-- {
+		h.down(i)
+	}
+}
@@ -0,0 +1,79 @@
+package heap_test
+
+import (
+	"cmp"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gammazero/heap"
+)
+
+func TestNewFromLarge(t *testing.T) {
+	// Large enough to be heapified in parallel.
+	const n = 300_000
+	data := make([]int, n)
+	for i := range data {
+		data[i] = rand.Intn(n)
+	}
+	less := cmp.Less[int]
+	h := heap.NewFrom(less, data...)
+	if h.Len() != n {
+		t.Fatalf("expected length %d, got %d", n, h.Len())
+	}
+	for i := 1; i < n; i++ {
+		if less(h.At(i), h.At((i-1)/2)) {
+			t.Fatalf("heap invariant invalidated at index %d", i)
+		}
+	}
+	prev := h.Pop()
+	for h.Len() != 0 {
+		x := h.Pop()
+		if x < prev {
+			t.Fatalf("popped %d after %d", x, prev)
+		}
+		prev = x
+	}
+}
+
+func BenchmarkNewFrom1M(b *testing.B) {
+	const n = 1 << 20
+	src := make([]int, n)
+	for i := range src {
+		src[i] = rand.Int()
+	}
+	data := make([]int, n)
+	for b.Loop() {
+		copy(data, src)
+		heap.NewFrom(cmp.Less[int], data...)
+	}
+}
+
+func TestRebuildLargeSequential(t *testing.T) {
+	const n = 300_000
+	data := make([]int, n)
+	for i := range data {
+		data[i] = rand.Intn(n)
+	}
+	h := heap.New(cmp.Less[int])
+	for _, x := range data {
+		h.Push(x)
+	}
+	// Methods other than NewFrom must not call less concurrently.
+	var calls atomic.Int32
+	var concurrent atomic.Bool
+	h.SetLess(func(a, b int) bool {
+		if calls.Add(1) > 1 {
+			concurrent.Store(true)
+		}
+		defer calls.Add(-1)
+		return a > b
+	})
+	h.DeleteFunc(func(x int) bool { return x%2 == 0 })
+	if concurrent.Load() {
+		t.Fatal("less function called concurrently")
+	}
+	if err := h.Verify(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,229 @@
+// Package heaptest provides a conformance test suite for heap
+// implementations. It checks, using random sequences of operations, that a
+// heap keeps its ordering invariant, pops elements in sorted order, and
+// removes any element correctly.
+//
+// A heap of ints that pops the smallest value first is tested by calling Run
+// from a test:
+//
+//	func TestConformance(t *testing.T) {
+//		heaptest.Run(t, heaptest.Config{
+//			New: func() heaptest.Heap[int] { return heap.NewOrdered[int]() },
+//		})
+//	}
+package heaptest
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// Heap is the interface tested by Run.
+type Heap[T any] interface {
+	Len() int
+	Push(x T)
+	Pop() T
+	Peek() T
+}
+
+// Indexed is implemented by heaps that expose the layout of their elements.
+// If the heap being tested implements Indexed, Run also checks the heap
+// invariant after each operation, and that removing any element works.
+type Indexed[T any] interface {
+	Heap[T]
+	At(i int) T
+	Remove(i int) T
+}
+
+// Config configures Run.
+type Config struct {
+	// New returns a new, empty min-heap of ints.
+	New func() Heap[int]
+	// Arity is the number of children of each node, used to check the heap
+	// invariant of Indexed heaps. If 0, the heap is assumed to be binary.
+	Arity int
+	// Ops is the number of random operations in each randomized test. If 0,
+	// 2000 are done.
+	Ops int
+	// Seed seeds the random operations, so that failures can be reproduced.
+	Seed uint64
+}
+
+// Run runs the conformance tests against the heaps returned by cfg.New, each
+// as a subtest of t.
+func Run(t *testing.T, cfg Config) {
+	if cfg.New == nil {
+		panic("heaptest: Config.New is nil")
+	}
+	if cfg.Arity == 0 {
+		cfg.Arity = 2
+	}
+	if cfg.Ops == 0 {
+		cfg.Ops = 2000
+	}
+	t.Run("Empty", cfg.testEmpty)
+	t.Run("PopSorted", cfg.testPopSorted)
+	t.Run("Duplicates", cfg.testDuplicates)
+	t.Run("Random", cfg.testRandom)
+	if _, ok := cfg.New().(Indexed[int]); ok {
+		t.Run("RemoveAny", cfg.testRemoveAny)
+	}
+}
+
+func (cfg Config) rand() *rand.Rand {
+	return rand.New(rand.NewPCG(cfg.Seed, 0x9e3779b97f4a7c15))
+}
+
+func (cfg Config) testEmpty(t *testing.T) {
+	h := cfg.New()
+	if n := h.Len(); n != 0 {
+		t.Fatalf("new heap has length %d", n)
+	}
+	h.Push(1)
+	h.Pop()
+	if n := h.Len(); n != 0 {
+		t.Fatalf("emptied heap has length %d", n)
+	}
+	checkPanics(t, "Pop on empty heap", func() { h.Pop() })
+	checkPanics(t, "Peek on empty heap", func() { h.Peek() })
+}
+
+func (cfg Config) testPopSorted(t *testing.T) {
+	r := cfg.rand()
+	for _, n := range []int{1, 2, 3, 7, 8, 9, 100, 1000} {
+		h := cfg.New()
+		want := make([]int, n)
+		for i := range want {
+			want[i] = r.IntN(1 << 20)
+			h.Push(want[i])
+			cfg.verify(t, h)
+		}
+		slices.Sort(want)
+		checkDrain(t, h, want)
+	}
+}
+
+func (cfg Config) testDuplicates(t *testing.T) {
+	h := cfg.New()
+	var want []int
+	for i := range 200 {
+		h.Push(i % 3)
+		want = append(want, i%3)
+	}
+	cfg.verify(t, h)
+	slices.Sort(want)
+	checkDrain(t, h, want)
+}
+
+// testRandom compares a random sequence of pushes and pops against a sorted
+// slice.
+func (cfg Config) testRandom(t *testing.T) {
+	r := cfg.rand()
+	h := cfg.New()
+	var model []int
+	for op := range cfg.Ops {
+		if len(model) == 0 || r.IntN(3) != 0 {
+			x := r.IntN(1000)
+			h.Push(x)
+			i, _ := slices.BinarySearch(model, x)
+			model = slices.Insert(model, i, x)
+		} else {
+			if x := h.Peek(); x != model[0] {
+				t.Fatalf("op %d: Peek returned %d, expected %d", op, x, model[0])
+			}
+			if x := h.Pop(); x != model[0] {
+				t.Fatalf("op %d: Pop returned %d, expected %d", op, x, model[0])
+			}
+			model = model[1:]
+		}
+		if h.Len() != len(model) {
+			t.Fatalf("op %d: length is %d, expected %d", op, h.Len(), len(model))
+		}
+		cfg.verify(t, h)
+	}
+	checkDrain(t, h, model)
+}
+
+// testRemoveAny removes elements at random indexes, checking that the
+// removed element is the one at that index and that the remaining elements
+// are intact.
+func (cfg Config) testRemoveAny(t *testing.T) {
+	r := cfg.rand()
+	h := cfg.New().(Indexed[int])
+	var model []int
+	for range 300 {
+		x := r.IntN(100)
+		h.Push(x)
+		model = append(model, x)
+	}
+	for h.Len() > 0 {
+		i := r.IntN(h.Len())
+		want := h.At(i)
+		if x := h.Remove(i); x != want {
+			t.Fatalf("Remove(%d) returned %d, expected element %d at that index", i, x, want)
+		}
+		j := slices.Index(model, want)
+		if j < 0 {
+			t.Fatalf("Remove(%d) returned %d, which was not in the heap", i, want)
+		}
+		model = slices.Delete(model, j, j+1)
+		cfg.verify(t, h)
+		if h.Len() != len(model) {
+			t.Fatalf("length is %d after Remove, expected %d", h.Len(), len(model))
+		}
+		if len(model) == 150 {
+			slices.Sort(model)
+			checkDrain(t, h, model)
+			return
+		}
+	}
+}
+
+// verify checks the heap invariant of an Indexed heap.
+func (cfg Config) verify(t *testing.T, h Heap[int]) {
+	t.Helper()
+	ih, ok := h.(Indexed[int])
+	if !ok {
+		return
+	}
+	if err := Verify(ih.Len(), ih.At, cfg.Arity); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Verify checks that the n elements returned by at, for indexes 0 through
+// n-1, are in the order of a min-heap with the given arity. It returns an
+// error describing the first element found that is less than its parent.
+func Verify(n int, at func(i int) int, arity int) error {
+	for i := 1; i < n; i++ {
+		p := (i - 1) / arity
+		if at(i) < at(p) {
+			return fmt.Errorf("heap invariant violated: element %d at index %d is less than parent %d at index %d", at(i), i, at(p), p)
+		}
+	}
+	return nil
+}
+
+func checkDrain(t *testing.T, h Heap[int], want []int) {
+	t.Helper()
+	for i, w := range want {
+		if x := h.Pop(); x != w {
+			t.Fatalf("Pop %d returned %d, expected %d", i, x, w)
+		}
+	}
+	if n := h.Len(); n != 0 {
+		t.Fatalf("heap has length %d after popping all elements", n)
+	}
+}
+
+func checkPanics(t *testing.T, name string, f func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("%s did not panic", name)
+		}
+	}()
+	f()
+}
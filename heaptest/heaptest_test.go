@@ -0,0 +1,54 @@
+package heaptest_test
+
+import (
+	"cmp"
+	"testing"
+
+	"github.com/gammazero/heap"
+	"github.com/gammazero/heap/heaptest"
+)
+
+func TestHeap(t *testing.T) {
+	heaptest.Run(t, heaptest.Config{
+		New: func() heaptest.Heap[int] { return heap.New(cmp.Less[int]) },
+	})
+}
+
+func TestOrdered(t *testing.T) {
+	heaptest.Run(t, heaptest.Config{
+		New:  func() heaptest.Heap[int] { return heap.NewOrdered[int]() },
+		Seed: 1,
+	})
+}
+
+func TestScanThreshold(t *testing.T) {
+	heaptest.Run(t, heaptest.Config{
+		New: func() heaptest.Heap[int] {
+			return heap.New(cmp.Less[int], heap.WithScanThreshold[int](8))
+		},
+		Seed: 2,
+	})
+}
+
+func TestKeyHeap(t *testing.T) {
+	heaptest.Run(t, heaptest.Config{
+		New: func() heaptest.Heap[int] {
+			return heap.NewKeyHeap(func(x int) int { return x }, cmp.Less[int])
+		},
+		Seed: 3,
+	})
+}
+
+func TestVerify(t *testing.T) {
+	data := []int{1, 3, 2, 4, 5, 0}
+	at := func(i int) int { return data[i] }
+	if err := heaptest.Verify(5, at, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := heaptest.Verify(6, at, 2); err == nil {
+		t.Fatal("expected invariant violation")
+	}
+	if err := heaptest.Verify(6, at, 5); err == nil {
+		t.Fatal("expected invariant violation with arity 5")
+	}
+}
@@ -0,0 +1,173 @@
+// Package heavyhitters finds the most frequent keys in a stream, using a
+// fixed amount of memory, with counts that optionally decay over time so that
+// recent occurrences count for more than old ones.
+//
+// The Space-Saving algorithm is used: k counters are kept in a heap ordered
+// by count. When a key that has no counter is offered and all counters are in
+// use, the counter with the smallest count is given to the new key, which
+// inherits its count. A key's count may therefore overestimate its true count
+// by at most the inherited count, which is reported as the key's error. Any
+// key whose true count is greater than the smallest count is guaranteed to
+// have a counter.
+//
+// As in package decay, counts are stored projected onto a fixed reference
+// time, in log space, so that decay does not change the order of counts and
+// the heap does not need to be rebuilt as time passes.
+package heavyhitters
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/gammazero/heap"
+)
+
+// Tracker tracks the k keys with the highest counts. It is not safe for
+// concurrent use.
+type Tracker[K comparable] struct {
+	h      *heap.Heap[*counter[K]]
+	index  map[K]*counter[K]
+	k      int
+	lambda float64 // decay constant per second
+	epoch  time.Time
+}
+
+type counter[K comparable] struct {
+	key K
+	// count and err are logs of the count and error projected to epoch.
+	count float64
+	err   float64
+	pos   int
+}
+
+// Item is a key and its estimated count.
+type Item[K comparable] struct {
+	Key K
+	// Count is the estimated count of the key, which may be greater than the
+	// true count by up to Error.
+	Count float64
+	// Error is the maximum amount by which Count overestimates the true
+	// count.
+	Error float64
+}
+
+// New returns a new Tracker that keeps counters for k keys. Counts decay to
+// half their value every halfLife. If halfLife is 0, counts do not decay.
+func New[K comparable](k int, halfLife time.Duration) *Tracker[K] {
+	if k < 1 {
+		panic("heavyhitters: k must be positive")
+	}
+	if halfLife < 0 {
+		panic("heavyhitters: half-life must not be negative")
+	}
+	t := &Tracker[K]{
+		h: heap.New(func(a, b *counter[K]) bool {
+			return a.count < b.count
+		}),
+		index: make(map[K]*counter[K], k),
+		k:     k,
+		epoch: time.Now(),
+	}
+	if halfLife != 0 {
+		t.lambda = math.Ln2 / halfLife.Seconds()
+	}
+	t.h.SetOnMove(func(c *counter[K], i int) {
+		c.pos = i
+	})
+	return t
+}
+
+// Len returns the number of keys that have counters, which is at most k.
+func (t *Tracker[K]) Len() int {
+	return t.h.Len()
+}
+
+// Offer adds weight to the count of key, now.
+func (t *Tracker[K]) Offer(key K, weight float64) {
+	t.OfferAt(key, weight, time.Now())
+}
+
+// OfferAt adds weight to the count of key at the given time. The weight must
+// be positive.
+func (t *Tracker[K]) OfferAt(key K, weight float64, at time.Time) {
+	if !(weight > 0) {
+		panic("heavyhitters: weight must be positive")
+	}
+	w := math.Log(weight) + t.lambda*at.Sub(t.epoch).Seconds()
+	if c, ok := t.index[key]; ok {
+		c.count = logAdd(c.count, w)
+		t.h.Fix(c.pos)
+		return
+	}
+	if t.h.Len() < t.k {
+		c := &counter[K]{key: key, count: w, err: math.Inf(-1)}
+		t.index[key] = c
+		t.h.Push(c)
+		return
+	}
+	// Give the counter with the smallest count to the new key.
+	c := t.h.Peek()
+	delete(t.index, c.key)
+	c.key = key
+	c.err = c.count
+	c.count = logAdd(c.count, w)
+	t.index[key] = c
+	t.h.Fix(c.pos)
+}
+
+// Count returns the estimated count of key now, and whether the key has a
+// counter.
+func (t *Tracker[K]) Count(key K) (Item[K], bool) {
+	c, ok := t.index[key]
+	if !ok {
+		return Item[K]{}, false
+	}
+	return t.item(c, t.scale(time.Now())), true
+}
+
+// Snapshot returns the keys that have counters, with their counts now, in
+// order from highest to lowest count.
+func (t *Tracker[K]) Snapshot() []Item[K] {
+	return t.SnapshotAt(time.Now())
+}
+
+// SnapshotAt returns the keys that have counters, with their counts at the
+// given time, in order from highest to lowest count.
+func (t *Tracker[K]) SnapshotAt(now time.Time) []Item[K] {
+	scale := t.scale(now)
+	items := make([]Item[K], 0, t.h.Len())
+	for c := range t.h.All() {
+		items = append(items, t.item(c, scale))
+	}
+	slices.SortFunc(items, func(a, b Item[K]) int {
+		return cmp.Compare(b.Count, a.Count)
+	})
+	return items
+}
+
+// scale returns the amount to add to a log count projected to the epoch to
+// get the log count at time now.
+func (t *Tracker[K]) scale(now time.Time) float64 {
+	return -t.lambda * now.Sub(t.epoch).Seconds()
+}
+
+func (t *Tracker[K]) item(c *counter[K], scale float64) Item[K] {
+	return Item[K]{
+		Key:   c.key,
+		Count: math.Exp(c.count + scale),
+		Error: math.Exp(c.err + scale),
+	}
+}
+
+// logAdd returns log(exp(a) + exp(b)) without overflow.
+func logAdd(a, b float64) float64 {
+	if a < b {
+		a, b = b, a
+	}
+	if math.IsInf(b, -1) {
+		return a
+	}
+	return a + math.Log1p(math.Exp(b-a))
+}
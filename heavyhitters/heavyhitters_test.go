@@ -0,0 +1,95 @@
+package heavyhitters_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/gammazero/heap/heavyhitters"
+)
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9*math.Max(1, math.Abs(b))
+}
+
+func TestTopKeys(t *testing.T) {
+	tr := heavyhitters.New[string](10, 0)
+	now := time.Now()
+	// Frequent keys mixed with many rare keys. Keys occurring more than n/k
+	// times, of n in total, are guaranteed to have counters.
+	for i := range 1000 {
+		tr.OfferAt("a", 1, now)
+		if i%2 == 0 {
+			tr.OfferAt("b", 1, now)
+		}
+		if i%4 == 0 {
+			tr.OfferAt("c", 1, now)
+		}
+		tr.OfferAt(string(rune('d'+rand.Intn(20))), 1, now)
+	}
+	if tr.Len() != 10 {
+		t.Fatalf("expected 10 counters, got %d", tr.Len())
+	}
+	snap := tr.SnapshotAt(now)
+	if snap[0].Key != "a" || snap[1].Key != "b" {
+		t.Fatalf("wrong heavy hitters: %+v", snap)
+	}
+	for i, it := range snap {
+		if i > 0 && it.Count > snap[i-1].Count {
+			t.Fatal("snapshot not ordered by count")
+		}
+	}
+	for key, want := range map[string]float64{"a": 1000, "b": 500} {
+		it, ok := tr.Count(key)
+		if !ok || it.Count < want-1e-6 || it.Count-it.Error > want+1e-6 {
+			t.Fatalf("count of %s %+v does not bound true count %v", key, it, want)
+		}
+	}
+	if _, ok := tr.Count("zzz"); ok {
+		t.Fatal("unexpected counter for key never offered")
+	}
+}
+
+func TestErrorBound(t *testing.T) {
+	tr := heavyhitters.New[int](2, 0)
+	now := time.Now()
+	tr.OfferAt(1, 5, now)
+	tr.OfferAt(2, 3, now)
+	tr.OfferAt(3, 1, now) // replaces 2, inheriting count 3
+	snap := tr.SnapshotAt(now)
+	if len(snap) != 2 || snap[0].Key != 1 || snap[1].Key != 3 {
+		t.Fatalf("wrong keys %+v", snap)
+	}
+	if !near(snap[1].Count, 4) || !near(snap[1].Error, 3) {
+		t.Fatalf("wrong count or error for replaced counter: %+v", snap[1])
+	}
+	if snap[0].Error != 0 {
+		t.Fatalf("expected no error for key 1, got %v", snap[0].Error)
+	}
+}
+
+func TestDecay(t *testing.T) {
+	tr := heavyhitters.New[string](2, time.Minute)
+	start := time.Now()
+	tr.OfferAt("old", 100, start)
+	tr.OfferAt("new", 30, start.Add(2*time.Minute))
+
+	snap := tr.SnapshotAt(start.Add(2 * time.Minute))
+	if snap[0].Key != "new" || !near(snap[0].Count, 30) || !near(snap[1].Count, 25) {
+		t.Fatalf("wrong decayed counts %+v", snap)
+	}
+	// A third key replaces the smallest decayed count.
+	tr.OfferAt("x", 1, start.Add(2*time.Minute))
+	if _, ok := tr.Count("old"); ok {
+		t.Fatal("expected decayed key to lose its counter")
+	}
+
+	// Counts over a long time do not overflow.
+	far := start.Add(24 * 365 * time.Hour)
+	tr.OfferAt("x", 1, far)
+	x := tr.SnapshotAt(far)[0]
+	if x.Key != "x" || math.IsInf(x.Count, 0) || math.IsNaN(x.Count) || !near(x.Count, 1) {
+		t.Fatalf("wrong count after a long time: %+v", x)
+	}
+}
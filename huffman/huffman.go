@@ -0,0 +1,114 @@
+// Package huffman builds Huffman trees, which give the optimal prefix code
+// lengths for symbols with known weights, and equivalently the cheapest order
+// in which to merge weighted items two at a time, such as sorted runs or
+// files, when the cost of each merge is the total weight merged.
+//
+// The tree is built by repeatedly taking the two nodes with the least weight
+// from a heap and merging them into a new node, in O(n log n) time.
+package huffman
+
+import "github.com/gammazero/heap"
+
+// Weight is the type of symbol weights.
+type Weight interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+// Symbol is a symbol and its weight, such as its frequency.
+type Symbol[S any, W Weight] struct {
+	Symbol S
+	Weight W
+}
+
+// Node is a node of a Huffman tree. A leaf node holds a symbol and has no
+// children. An internal node has two children, and its weight is the sum of
+// their weights.
+type Node[S any, W Weight] struct {
+	Symbol      S
+	Weight      W
+	Left, Right *Node[S, W]
+	// index is the index of a leaf's symbol in the input, or -1.
+	index int
+}
+
+// Leaf returns whether n is a leaf node.
+func (n *Node[S, W]) Leaf() bool {
+	return n.Left == nil
+}
+
+// Cost returns the sum of the weights of the internal nodes of the tree. This
+// is the total cost of the merges represented by the tree, and the total
+// length of the encoding of all symbols, weighted by symbol weight.
+func (n *Node[S, W]) Cost() W {
+	if n == nil || n.Leaf() {
+		return 0
+	}
+	return n.Weight + n.Left.Cost() + n.Right.Cost()
+}
+
+type item[S any, W Weight] struct {
+	n   *Node[S, W]
+	seq int
+}
+
+// Build builds a Huffman tree for the symbols, and returns its root and the
+// code length of each symbol, which is the depth of its leaf, in the order of
+// the symbols. Ties between equal weights are broken in the order of the
+// symbols, with merged nodes after the symbols, so the result is
+// deterministic.
+//
+// If there is only one symbol, the root is its leaf, and its code length is 1
+// so that it can still be encoded. If there are no symbols, the root is nil.
+func Build[S any, W Weight](symbols []Symbol[S, W]) (*Node[S, W], []int) {
+	if len(symbols) == 0 {
+		return nil, nil
+	}
+	items := make([]item[S, W], len(symbols))
+	for i, s := range symbols {
+		items[i] = item[S, W]{
+			n:   &Node[S, W]{Symbol: s.Symbol, Weight: s.Weight, index: i},
+			seq: i,
+		}
+	}
+	h := heap.NewFrom(func(a, b item[S, W]) bool {
+		if a.n.Weight != b.n.Weight {
+			return a.n.Weight < b.n.Weight
+		}
+		return a.seq < b.seq
+	}, items...)
+
+	seq := len(symbols)
+	for h.Len() > 1 {
+		a, b := h.Pop(), h.Pop()
+		h.Push(item[S, W]{
+			n: &Node[S, W]{
+				Weight: a.n.Weight + b.n.Weight,
+				Left:   a.n,
+				Right:  b.n,
+				index:  -1,
+			},
+			seq: seq,
+		})
+		seq++
+	}
+	root := h.Pop().n
+
+	lengths := make([]int, len(symbols))
+	if root.Leaf() {
+		lengths[0] = 1
+		return root, lengths
+	}
+	var walk func(n *Node[S, W], depth int)
+	walk = func(n *Node[S, W], depth int) {
+		if n.Leaf() {
+			lengths[n.index] = depth
+			return
+		}
+		walk(n.Left, depth+1)
+		walk(n.Right, depth+1)
+	}
+	walk(root, 0)
+	return root, lengths
+}
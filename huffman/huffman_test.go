@@ -0,0 +1,72 @@
+package huffman_test
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/gammazero/heap/huffman"
+)
+
+func TestBuild(t *testing.T) {
+	symbols := []huffman.Symbol[rune, int]{
+		{'a', 45}, {'b', 13}, {'c', 12}, {'d', 16}, {'e', 9}, {'f', 5},
+	}
+	root, lengths := huffman.Build(symbols)
+	if !slices.Equal(lengths, []int{1, 3, 3, 3, 4, 4}) {
+		t.Fatalf("wrong code lengths %v", lengths)
+	}
+	if root.Weight != 100 {
+		t.Fatalf("root weight %d, expected 100", root.Weight)
+	}
+	var cost int
+	for i, s := range symbols {
+		cost += s.Weight * lengths[i]
+	}
+	if root.Cost() != cost {
+		t.Fatalf("tree cost %d, expected %d", root.Cost(), cost)
+	}
+
+	// Kraft equality holds for a complete prefix code.
+	var kraft float64
+	for _, l := range lengths {
+		kraft += 1 / float64(int(1)<<l)
+	}
+	if kraft != 1 {
+		t.Fatalf("Kraft sum %v, expected 1", kraft)
+	}
+}
+
+func TestBuildSmall(t *testing.T) {
+	if root, lengths := huffman.Build[string, float64](nil); root != nil || lengths != nil {
+		t.Fatal("expected nil tree for no symbols")
+	}
+	root, lengths := huffman.Build([]huffman.Symbol[string, float64]{{"x", 0.5}})
+	if !root.Leaf() || root.Symbol != "x" || !slices.Equal(lengths, []int{1}) {
+		t.Fatal("wrong tree for one symbol")
+	}
+	if root.Cost() != 0 {
+		t.Fatal("expected no cost for one symbol")
+	}
+}
+
+func Example() {
+	// Merge sorted files of these sizes, two at a time, at least cost.
+	files := []huffman.Symbol[string, int]{
+		{"a.dat", 20}, {"b.dat", 30}, {"c.dat", 10}, {"d.dat", 5}, {"e.dat", 30},
+	}
+	root, _ := huffman.Build(files)
+	var show func(n *huffman.Node[string, int]) string
+	show = func(n *huffman.Node[string, int]) string {
+		if n.Leaf() {
+			return n.Symbol
+		}
+		return fmt.Sprintf("(%s %s)", show(n.Left), show(n.Right))
+	}
+	fmt.Println(show(root))
+	fmt.Println("cost:", root.Cost())
+
+	// Output:
+	// (((d.dat c.dat) a.dat) (b.dat e.dat))
+	// cost: 205
+}
@@ -0,0 +1,150 @@
+// Package indexed provides an addressable priority queue of keys, such as
+// graph nodes, in which the priority of a key already in the queue can be
+// looked up and decreased. This is the queue used by Dijkstra's shortest path
+// algorithm and Prim's minimum spanning tree algorithm.
+//
+// Membership is checked in O(1) time with a map from key to position in the
+// heap, and a priority is decreased in O(log n) time by sifting the key up
+// from its position.
+//
+// # Lazy deletion
+//
+// An alternative that needs no index is to push a new entry each time a key's
+// priority decreases, and to skip stale entries when they are popped. An
+// entry is stale if its key has already been popped with a better priority:
+//
+//	h := heap.New(func(a, b entry) bool { return a.dist < b.dist })
+//	h.Push(entry{src, 0})
+//	for h.Len() != 0 {
+//		e := h.Pop()
+//		if done[e.node] {
+//			continue // stale entry
+//		}
+//		done[e.node] = true
+//		for _, edge := range graph[e.node] {
+//			if d := e.dist + edge.weight; d < dist[edge.to] {
+//				dist[edge.to] = d
+//				h.Push(entry{edge.to, d})
+//			}
+//		}
+//	}
+//
+// Lazy deletion is simple and often fast, but the heap can hold one entry
+// for each edge rather than one for each node. Queue holds at most one entry
+// for each key.
+package indexed
+
+import "github.com/gammazero/heap"
+
+// Queue is a priority queue of distinct keys, each with a priority, that
+// removes the key with the least priority first. It is not safe for
+// concurrent use.
+type Queue[K comparable, P any] struct {
+	h     *heap.Heap[*item[K, P]]
+	index map[K]*item[K, P]
+	less  func(a, b P) bool
+}
+
+type item[K comparable, P any] struct {
+	key K
+	pri P
+	pos int
+}
+
+// New returns a new Queue that orders priorities by less.
+func New[K comparable, P any](less func(a, b P) bool) *Queue[K, P] {
+	q := &Queue[K, P]{
+		h: heap.New(func(a, b *item[K, P]) bool {
+			return less(a.pri, b.pri)
+		}),
+		index: make(map[K]*item[K, P]),
+		less:  less,
+	}
+	q.h.SetOnMove(func(it *item[K, P], i int) {
+		it.pos = i
+	})
+	return q
+}
+
+// Len returns the number of keys in the queue.
+func (q *Queue[K, P]) Len() int {
+	return q.h.Len()
+}
+
+// Contains returns whether key is in the queue.
+func (q *Queue[K, P]) Contains(key K) bool {
+	_, ok := q.index[key]
+	return ok
+}
+
+// Priority returns the priority of key, and whether key is in the queue.
+func (q *Queue[K, P]) Priority(key K) (P, bool) {
+	it, ok := q.index[key]
+	if !ok {
+		var zero P
+		return zero, false
+	}
+	return it.pri, true
+}
+
+// PushOrDecrease adds key with the given priority if key is not in the queue,
+// or decreases the priority of key if pri is less than its current priority.
+// It returns true if key was added or its priority decreased, and false if key
+// is in the queue with a priority that is not greater than pri.
+//
+// A key is not in the queue after it is popped, so PushOrDecrease adds it
+// again. Callers that must not visit a key twice, as in Dijkstra's algorithm,
+// need to record the keys they have popped.
+func (q *Queue[K, P]) PushOrDecrease(key K, pri P) bool {
+	if it, ok := q.index[key]; ok {
+		if !q.less(pri, it.pri) {
+			return false
+		}
+		it.pri = pri
+		q.h.Fix(it.pos)
+		return true
+	}
+	it := &item[K, P]{key: key, pri: pri}
+	q.index[key] = it
+	q.h.Push(it)
+	return true
+}
+
+// Set adds key with the given priority, or changes the priority of key if it
+// is already in the queue, whether the priority increases or decreases.
+func (q *Queue[K, P]) Set(key K, pri P) {
+	if it, ok := q.index[key]; ok {
+		it.pri = pri
+		q.h.Fix(it.pos)
+		return
+	}
+	it := &item[K, P]{key: key, pri: pri}
+	q.index[key] = it
+	q.h.Push(it)
+}
+
+// Peek returns the key with the least priority, and its priority, without
+// removing it. It panics if the queue is empty.
+func (q *Queue[K, P]) Peek() (K, P) {
+	it := q.h.Peek()
+	return it.key, it.pri
+}
+
+// Pop removes and returns the key with the least priority, and its priority.
+// It panics if the queue is empty.
+func (q *Queue[K, P]) Pop() (K, P) {
+	it := q.h.Pop()
+	delete(q.index, it.key)
+	return it.key, it.pri
+}
+
+// Remove removes key from the queue, and returns whether it was in the queue.
+func (q *Queue[K, P]) Remove(key K) bool {
+	it, ok := q.index[key]
+	if !ok {
+		return false
+	}
+	q.h.Remove(it.pos)
+	delete(q.index, key)
+	return true
+}
@@ -0,0 +1,87 @@
+package indexed_test
+
+import (
+	"cmp"
+	"fmt"
+	"testing"
+
+	"github.com/gammazero/heap/indexed"
+)
+
+func TestQueue(t *testing.T) {
+	q := indexed.New[string](cmp.Less[int])
+	if !q.PushOrDecrease("a", 5) || !q.PushOrDecrease("b", 3) || !q.PushOrDecrease("c", 7) {
+		t.Fatal("expected new keys to be added")
+	}
+	if q.PushOrDecrease("a", 6) {
+		t.Fatal("priority must not increase")
+	}
+	if !q.PushOrDecrease("c", 1) {
+		t.Fatal("expected priority to decrease")
+	}
+	if p, ok := q.Priority("c"); !ok || p != 1 {
+		t.Fatalf("wrong priority %d for c", p)
+	}
+	if !q.Contains("a") || q.Contains("z") {
+		t.Fatal("wrong membership")
+	}
+	q.Set("b", 10)
+	if !q.Remove("a") || q.Remove("a") {
+		t.Fatal("wrong result from Remove")
+	}
+	if q.Len() != 2 {
+		t.Fatalf("expected length 2, got %d", q.Len())
+	}
+	if k, p := q.Peek(); k != "c" || p != 1 {
+		t.Fatalf("wrong head %s=%d", k, p)
+	}
+	for _, want := range []string{"c", "b"} {
+		if k, _ := q.Pop(); k != want {
+			t.Fatalf("expected %s, got %s", want, k)
+		}
+	}
+	if q.Contains("c") {
+		t.Fatal("popped key still in queue")
+	}
+}
+
+type edge struct {
+	to     string
+	weight float64
+}
+
+func Example() {
+	graph := map[string][]edge{
+		"a": {{"b", 7}, {"c", 9}, {"f", 14}},
+		"b": {{"a", 7}, {"c", 10}, {"d", 15}},
+		"c": {{"a", 9}, {"b", 10}, {"d", 11}, {"f", 2}},
+		"d": {{"b", 15}, {"c", 11}, {"e", 6}},
+		"e": {{"d", 6}, {"f", 9}},
+		"f": {{"a", 14}, {"c", 2}, {"e", 9}},
+	}
+
+	// Dijkstra's algorithm.
+	dist := map[string]float64{}
+	q := indexed.New[string](cmp.Less[float64])
+	q.PushOrDecrease("a", 0)
+	for q.Len() != 0 {
+		node, d := q.Pop()
+		dist[node] = d
+		for _, e := range graph[node] {
+			if _, done := dist[e.to]; !done {
+				q.PushOrDecrease(e.to, d+e.weight)
+			}
+		}
+	}
+	for _, node := range []string{"a", "b", "c", "d", "e", "f"} {
+		fmt.Println(node, dist[node])
+	}
+
+	// Output:
+	// a 0
+	// b 7
+	// c 9
+	// d 20
+	// e 20
+	// f 11
+}
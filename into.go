@@ -0,0 +1,51 @@
+package heap
+
+import "slices"
+
+// Into appends the heap's elements to dst in order from minimum to maximum,
+// and returns the extended slice. The heap is not modified. If dst has enough
+// spare capacity to hold the elements, Into does not allocate, so a buffer
+// can be reused across calls. The elements are sorted in place in dst by
+// heapsort, in O(n log n) time.
+func (h *Heap[T]) Into(dst []T) []T {
+	if h.guard != nil {
+		h.checkRead()
+	}
+	start := len(dst)
+	dst = append(dst, h.heapOrder()...)
+	sorted := dst[start:]
+	// The copied elements are already in heap order, so each minimum element
+	// can be moved to the end of the shrinking heap, which leaves the
+	// elements in descending order.
+	for end := len(sorted) - 1; end > 0; end-- {
+		sorted[0], sorted[end] = sorted[end], sorted[0]
+		if h.ordered != nil {
+			h.ordered.down(sorted[:end], 0)
+		} else {
+			siftDown(sorted[:end], 0, h.less)
+		}
+	}
+	slices.Reverse(sorted)
+	return dst
+}
+
+// siftDown moves the element at index i of data down to its place in the heap
+// ordering given by less.
+func siftDown[T any](data []T, i int, less func(a, b T) bool) {
+	n := len(data)
+	for {
+		left := 2*i + 1
+		if left >= n || left < 0 { // left < 0 after int overflow
+			return
+		}
+		j := left
+		if right := left + 1; right < n && less(data[right], data[left]) {
+			j = right
+		}
+		if !less(data[j], data[i]) {
+			return
+		}
+		data[i], data[j] = data[j], data[i]
+		i = j
+	}
+}
@@ -0,0 +1,50 @@
+package heap_test
+
+import (
+	"cmp"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/gammazero/heap"
+)
+
+func TestInto(t *testing.T) {
+	data := rand.Perm(100)
+	for _, h := range []*heap.Heap[int]{
+		heap.NewFrom(cmp.Less[int], slices.Clone(data)...),
+		heap.NewOrdered(slices.Clone(data)...),
+	} {
+		layout := h.Export()
+		buf := []int{-1, -2}
+		buf = h.Into(buf)
+		if len(buf) != 102 || buf[0] != -1 || buf[1] != -2 {
+			t.Fatal("Into did not append to dst")
+		}
+		if !slices.IsSorted(buf[2:]) {
+			t.Fatalf("elements not sorted: %v", buf[2:])
+		}
+		if !slices.Equal(h.Export(), layout) {
+			t.Fatal("Into modified the heap")
+		}
+	}
+
+	h := heap.NewFrom(func(a, b int) bool { return a > b }, 1, 5, 3)
+	if got := h.Into(nil); !slices.Equal(got, []int{5, 3, 1}) {
+		t.Fatalf("wrong order %v", got)
+	}
+	if got := heap.New(cmp.Less[int]).Into(nil); len(got) != 0 {
+		t.Fatalf("expected nothing from empty heap, got %v", got)
+	}
+}
+
+func TestIntoNoAlloc(t *testing.T) {
+	h := heap.NewFrom(cmp.Less[int], rand.Perm(1000)...)
+	buf := make([]int, 0, 1000)
+	allocs := testing.AllocsPerRun(10, func() {
+		buf = h.Into(buf[:0])
+	})
+	if allocs != 0 {
+		t.Fatalf("expected no allocations, got %v", allocs)
+	}
+}
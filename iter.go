@@ -0,0 +1,86 @@
+package heap
+
+import "iter"
+
+// All returns an iterator over the elements of the heap in heap order, which
+// is the order of their indexes. The heap must not be modified during
+// iteration; if it is, the iterator panics.
+func (h *Heap[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if h.guard != nil {
+			h.checkRead()
+		}
+		data := h.heapOrder()
+		v := h.version
+		for i := 0; i < len(data); i++ {
+			if !yield(data[i]) {
+				return
+			}
+			h.checkVersion(v)
+		}
+	}
+}
+
+// Ascend returns an iterator over the elements of the heap in order from
+// minimum to maximum, without removing them. Each step takes O(log k) time,
+// where k is the number of elements iterated so far, so stopping early after
+// the first few elements is cheap. The heap must not be modified during
+// iteration; if it is, the iterator panics.
+func (h *Heap[T]) Ascend() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if h.guard != nil {
+			h.checkRead()
+		}
+		h.ascend(h.heapOrder(), yield)
+	}
+}
+
+// ascend yields the elements of data, which is in heap order, in order from
+// minimum to maximum.
+func (h *Heap[T]) ascend(data []T, yield func(T) bool) {
+	if len(data) == 0 {
+		return
+	}
+	v := h.version
+	// As in PeekN, the next smallest element is always a child of an element
+	// already yielded.
+	cand := New(func(a, b int) bool { return h.less(data[a], data[b]) })
+	cand.Push(0)
+	for cand.Len() != 0 {
+		i := cand.Pop()
+		if !yield(data[i]) {
+			return
+		}
+		h.checkVersion(v)
+		if left := 2*i + 1; left < len(data) {
+			cand.Push(left)
+			if left+1 < len(data) {
+				cand.Push(left + 1)
+			}
+		}
+	}
+}
+
+// Drain returns an iterator that pops each element from the heap, in order
+// from minimum to maximum, until the heap is empty or iteration stops. Elements
+// that are not iterated remain in the heap. The heap must not be modified
+// other than by the iterator during iteration; if it is, the iterator panics.
+func (h *Heap[T]) Drain() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for len(h.data) != 0 {
+			x := h.Pop()
+			v := h.version
+			if !yield(x) {
+				return
+			}
+			h.checkVersion(v)
+		}
+	}
+}
+
+// checkVersion panics if the heap has been modified since its version was v.
+func (h *Heap[T]) checkVersion(v uint64) {
+	if h.version != v {
+		panic("heap: heap modified during iteration")
+	}
+}
@@ -0,0 +1,100 @@
+package heap_test
+
+import (
+	"cmp"
+	"slices"
+	"testing"
+
+	"github.com/gammazero/heap"
+)
+
+func TestAll(t *testing.T) {
+	h := heap.NewFrom(cmp.Less[int], 5, 2, 8, 1, 9, 3)
+	var got []int
+	for x := range h.All() {
+		got = append(got, x)
+	}
+	if !slices.Equal(got, h.Export()) {
+		t.Fatalf("All returned %v, expected heap order %v", got, h.Export())
+	}
+	for x := range h.All() {
+		if x != 1 {
+			t.Fatalf("expected minimum first, got %d", x)
+		}
+		break
+	}
+}
+
+func TestAscend(t *testing.T) {
+	h := heap.NewFrom(cmp.Less[int], 5, 2, 8, 1, 9, 3, 2)
+	if got := slices.Collect(h.Ascend()); !slices.Equal(got, []int{1, 2, 2, 3, 5, 8, 9}) {
+		t.Fatalf("wrong ascending order %v", got)
+	}
+	if h.Len() != 7 {
+		t.Fatal("Ascend modified the heap")
+	}
+	var got []int
+	for x := range h.Ascend() {
+		if len(got) == 3 {
+			break
+		}
+		got = append(got, x)
+	}
+	if !slices.Equal(got, []int{1, 2, 2}) {
+		t.Fatalf("wrong first elements %v", got)
+	}
+	for range heap.New(cmp.Less[int]).Ascend() {
+		t.Fatal("empty heap yielded element")
+	}
+}
+
+func TestDrain(t *testing.T) {
+	h := heap.NewFrom(cmp.Less[int], 5, 2, 8, 1, 9, 3)
+	var got []int
+	for x := range h.Drain() {
+		got = append(got, x)
+		if x == 3 {
+			break
+		}
+	}
+	if !slices.Equal(got, []int{1, 2, 3}) {
+		t.Fatalf("wrong drained elements %v", got)
+	}
+	if h.Len() != 3 || h.Peek() != 5 {
+		t.Fatal("elements not iterated should remain in heap")
+	}
+	if got = slices.Collect(h.Drain()); !slices.Equal(got, []int{5, 8, 9}) || h.Len() != 0 {
+		t.Fatalf("wrong drained elements %v", got)
+	}
+}
+
+func TestModifiedDuringIteration(t *testing.T) {
+	newHeap := func() *heap.Heap[int] {
+		return heap.NewFrom(cmp.Less[int], 5, 2, 8, 1, 9, 3)
+	}
+	h := newHeap()
+	assertPanics(t, "All should panic when heap is modified", func() {
+		for range h.All() {
+			h.Push(0)
+		}
+	})
+	h = newHeap()
+	assertPanics(t, "Ascend should panic when heap is modified", func() {
+		for x := range h.Ascend() {
+			h.Set(0, x+10)
+		}
+	})
+	h = newHeap()
+	assertPanics(t, "Drain should panic when heap is modified", func() {
+		for range h.Drain() {
+			h.Pop()
+		}
+	})
+
+	// Reading the heap during iteration is allowed.
+	h = newHeap()
+	for range h.All() {
+		h.Peek()
+		h.At(h.Len() - 1)
+	}
+}
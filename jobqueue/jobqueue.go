@@ -0,0 +1,248 @@
+// Package jobqueue runs jobs on a pool of workers in priority order, retrying
+// failed jobs with backoff and passing jobs that fail too many times to a
+// dead-letter function.
+//
+// Jobs that are ready to run are kept in a heap ordered by priority, and jobs
+// waiting to be retried are kept in a heap ordered by the time of their next
+// attempt. When a retry is due, the job returns to the ready heap with its
+// original priority.
+//
+// Queue pushes jobs to a handler function. For consumers that pull items and
+// acknowledge them when done, LeaseQueue provides at-least-once delivery with
+// a visibility timeout.
+package jobqueue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gammazero/heap"
+	"github.com/gammazero/heap/retryqueue"
+)
+
+var (
+	// ErrClosed is returned by Submit after Shutdown has been called, and by
+	// LeaseQueue methods after Close.
+	ErrClosed = errors.New("jobqueue: queue is shut down")
+	// ErrShutdown is the error of jobs that were discarded, without running
+	// or being retried, because Shutdown did not complete in time.
+	ErrShutdown = errors.New("jobqueue: job discarded by shutdown")
+)
+
+// Job is a job in the queue.
+type Job[T any] struct {
+	// Value is the value passed to the handler.
+	Value T
+	// Priority is the job's priority. Jobs with higher priority run first.
+	Priority int
+	// Attempts is the number of times the job has been run and failed.
+	Attempts int
+	// Err is the error from the job's last failed attempt, or ErrShutdown
+	// if the job was discarded by Shutdown.
+	Err error
+
+	seq     uint64
+	readyAt time.Time
+}
+
+// Config configures a Queue.
+type Config[T any] struct {
+	// Workers is the number of jobs that run at the same time. If less than
+	// 1, 1 worker is used.
+	Workers int
+	// MaxAttempts is the number of times a job is run before it is passed to
+	// DeadLetter. If 0, failed jobs are retried until they succeed.
+	MaxAttempts int
+	// Backoff computes the delay before each retry of a failed job.
+	Backoff retryqueue.Backoff
+	// DeadLetter, if not nil, is called with each job that fails
+	// MaxAttempts times, or is discarded by Shutdown. It is called from a
+	// worker goroutine, and must not block for long.
+	DeadLetter func(*Job[T])
+}
+
+// Queue runs jobs by passing their values to a handler function. It is safe
+// for concurrent use.
+type Queue[T any] struct {
+	handler func(context.Context, T) error
+	cfg     Config[T]
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	cond    sync.Cond
+	ready   *heap.Heap[*Job[T]]
+	delayed *heap.Heap[*Job[T]]
+	timer   *time.Timer
+	seq     uint64
+	closed  bool
+	aborted bool
+}
+
+// New creates a Queue that runs each job by calling handler with the job's
+// value, and starts its workers. A job fails if handler returns an error. The
+// context passed to handler is canceled if Shutdown does not complete in
+// time.
+func New[T any](handler func(ctx context.Context, x T) error, cfg Config[T]) *Queue[T] {
+	q := &Queue[T]{
+		handler: handler,
+		cfg:     cfg,
+		ready: heap.New(func(a, b *Job[T]) bool {
+			if a.Priority != b.Priority {
+				return a.Priority > b.Priority
+			}
+			return a.seq < b.seq
+		}),
+		delayed: heap.New(func(a, b *Job[T]) bool {
+			return a.readyAt.Before(b.readyAt)
+		}),
+	}
+	q.cond.L = &q.mu
+	q.ctx, q.cancel = context.WithCancel(context.Background())
+	q.timer = time.AfterFunc(time.Hour, q.wake)
+	q.timer.Stop()
+	workers := max(cfg.Workers, 1)
+	q.wg.Add(workers)
+	for range workers {
+		go q.worker()
+	}
+	return q
+}
+
+// Submit adds a job with the given value and priority. It returns ErrClosed if
+// the queue has been shut down.
+func (q *Queue[T]) Submit(x T, prio int) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrClosed
+	}
+	q.seq++
+	q.ready.Push(&Job[T]{Value: x, Priority: prio, seq: q.seq})
+	q.cond.Signal()
+	return nil
+}
+
+// Len returns the number of jobs waiting to run, including jobs waiting to be
+// retried. Jobs that are running are not counted.
+func (q *Queue[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.ready.Len() + q.delayed.Len()
+}
+
+// Shutdown stops the queue from accepting jobs, and waits for all submitted
+// jobs to finish, including any retries. If ctx is done first, the contexts of
+// running jobs are canceled, jobs that are waiting are passed to DeadLetter
+// with ErrShutdown, jobs that fail are not retried, and Shutdown returns the
+// context's error once the running jobs return.
+func (q *Queue[T]) Shutdown(ctx context.Context) error {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+	q.cond.Broadcast()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		q.cancel()
+		return nil
+	case <-ctx.Done():
+	}
+
+	q.cancel()
+	q.mu.Lock()
+	q.aborted = true
+	var discarded []*Job[T]
+	for q.ready.Len() != 0 {
+		discarded = append(discarded, q.ready.Pop())
+	}
+	for q.delayed.Len() != 0 {
+		discarded = append(discarded, q.delayed.Pop())
+	}
+	q.timer.Stop()
+	q.mu.Unlock()
+	q.cond.Broadcast()
+
+	for _, j := range discarded {
+		j.Err = ErrShutdown
+		q.deadLetter(j)
+	}
+	<-done
+	return ctx.Err()
+}
+
+func (q *Queue[T]) worker() {
+	defer q.wg.Done()
+	for {
+		q.mu.Lock()
+		for q.ready.Len() == 0 {
+			if q.closed && q.delayed.Len() == 0 {
+				q.mu.Unlock()
+				// Wake the other workers so they can exit too.
+				q.cond.Broadcast()
+				return
+			}
+			q.cond.Wait()
+		}
+		j := q.ready.Pop()
+		q.mu.Unlock()
+
+		err := q.handler(q.ctx, j.Value)
+		if err == nil {
+			continue
+		}
+		j.Attempts++
+		j.Err = err
+		q.mu.Lock()
+		if q.aborted || (q.cfg.MaxAttempts > 0 && j.Attempts >= q.cfg.MaxAttempts) {
+			q.mu.Unlock()
+			q.deadLetter(j)
+			continue
+		}
+		j.readyAt = time.Now().Add(q.cfg.Backoff.Delay(j.Attempts))
+		q.delayed.Push(j)
+		q.armTimer()
+		q.mu.Unlock()
+	}
+}
+
+// wake moves the jobs whose retries are due to the ready heap.
+func (q *Queue[T]) wake() {
+	q.mu.Lock()
+	now := time.Now()
+	var n int
+	for q.delayed.Len() != 0 && !q.delayed.Peek().readyAt.After(now) {
+		q.ready.Push(q.delayed.Pop())
+		n++
+	}
+	q.armTimer()
+	q.mu.Unlock()
+	if n != 0 {
+		q.cond.Broadcast()
+	}
+}
+
+// armTimer sets the timer to call wake when the next retry is due. It must be
+// called with the lock held.
+func (q *Queue[T]) armTimer() {
+	if q.delayed.Len() == 0 {
+		q.timer.Stop()
+		return
+	}
+	q.timer.Reset(time.Until(q.delayed.Peek().readyAt))
+}
+
+func (q *Queue[T]) deadLetter(j *Job[T]) {
+	if q.cfg.DeadLetter != nil {
+		q.cfg.DeadLetter(j)
+	}
+}
@@ -0,0 +1,152 @@
+package jobqueue_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gammazero/heap/jobqueue"
+	"github.com/gammazero/heap/retryqueue"
+)
+
+var errFail = errors.New("fail")
+
+func TestPriorityOrder(t *testing.T) {
+	gate := make(chan struct{})
+	var mu sync.Mutex
+	var order []int
+	q := jobqueue.New(func(ctx context.Context, x int) error {
+		if x == 0 {
+			<-gate
+			return nil
+		}
+		mu.Lock()
+		order = append(order, x)
+		mu.Unlock()
+		return nil
+	}, jobqueue.Config[int]{Workers: 1})
+
+	// Block the only worker while the other jobs are submitted.
+	if err := q.Submit(0, 100); err != nil {
+		t.Fatal(err)
+	}
+	for q.Len() != 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if err := q.Submit(1, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Submit(3, 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Submit(2, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Submit(4, 3); err != nil {
+		t.Fatal(err)
+	}
+	close(gate)
+	if err := q.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	want := []int{3, 4, 2, 1}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("wrong order %v, expected %v", order, want)
+		}
+	}
+	if err := q.Submit(5, 1); !errors.Is(err, jobqueue.ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestRetryAndDeadLetter(t *testing.T) {
+	var mu sync.Mutex
+	calls := map[string]int{}
+	var dead []*jobqueue.Job[string]
+	q := jobqueue.New(func(ctx context.Context, x string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		calls[x]++
+		if x == "flaky" && calls[x] < 3 || x == "broken" {
+			return errFail
+		}
+		return nil
+	}, jobqueue.Config[string]{
+		Workers:     2,
+		MaxAttempts: 4,
+		Backoff:     retryqueue.Backoff{Base: time.Millisecond},
+		DeadLetter: func(j *jobqueue.Job[string]) {
+			mu.Lock()
+			dead = append(dead, j)
+			mu.Unlock()
+		},
+	})
+	if err := q.Submit("ok", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Submit("flaky", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Submit("broken", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// Shutdown waits for the retries to finish.
+	if err := q.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if calls["ok"] != 1 || calls["flaky"] != 3 || calls["broken"] != 4 {
+		t.Fatalf("wrong number of calls %v", calls)
+	}
+	if len(dead) != 1 || dead[0].Value != "broken" || dead[0].Attempts != 4 || !errors.Is(dead[0].Err, errFail) {
+		t.Fatalf("wrong dead letters %+v", dead)
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	var mu sync.Mutex
+	var dead []*jobqueue.Job[int]
+	q := jobqueue.New(func(ctx context.Context, x int) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, jobqueue.Config[int]{
+		Workers:     1,
+		MaxAttempts: 0,
+		DeadLetter: func(j *jobqueue.Job[int]) {
+			mu.Lock()
+			dead = append(dead, j)
+			mu.Unlock()
+		},
+	})
+	for i := range 3 {
+		if err := q.Submit(i, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := q.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if len(dead) != 3 {
+		t.Fatalf("expected all jobs dead-lettered, got %d", len(dead))
+	}
+	var discarded int
+	for _, j := range dead {
+		if errors.Is(j.Err, jobqueue.ErrShutdown) {
+			discarded++
+		} else if !errors.Is(j.Err, context.Canceled) {
+			t.Fatalf("unexpected error %v", j.Err)
+		}
+	}
+	if discarded != 2 {
+		t.Fatalf("expected 2 jobs discarded without running, got %d", discarded)
+	}
+	if q.Len() != 0 {
+		t.Fatal("jobs remain after shutdown")
+	}
+}
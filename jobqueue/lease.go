@@ -0,0 +1,274 @@
+package jobqueue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gammazero/heap"
+)
+
+// ErrLeaseExpired is returned by Ack, Nack, and Extend when the lease has
+// expired, or was already acknowledged, so the item may have been delivered
+// again.
+var ErrLeaseExpired = errors.New("jobqueue: lease expired")
+
+// LeaseQueue is a priority queue for consumers that pull items, with
+// at-least-once delivery. Pop leases an item to the consumer instead of
+// removing it. The consumer acknowledges the item with Ack when it has been
+// processed, which removes it, or with Nack, which returns it to the queue at
+// once. If neither happens before the lease's visibility timeout, the item
+// returns to the queue so that it is delivered again. LeaseQueue is safe for
+// concurrent use.
+//
+// Leased items are kept in a heap ordered by lease deadline, so expired
+// leases are found in O(log n) time each.
+type LeaseQueue[T any] struct {
+	mu         sync.Mutex
+	ready      *heap.Heap[*leaseItem[T]]
+	leased     *heap.Heap[*leaseItem[T]]
+	visibility time.Duration
+	seq        uint64
+	closed     bool
+	// notify is closed and replaced when an item becomes ready or the
+	// queue is closed, to wake blocked calls to Pop.
+	notify chan struct{}
+}
+
+type leaseItem[T any] struct {
+	val        T
+	prio       int
+	seq        uint64
+	deliveries int
+	deadline   time.Time
+	// pos is the item's index in the leased heap, or -1 if not leased.
+	pos int
+}
+
+// Lease is an item delivered by Pop, which must be acknowledged with Ack or
+// Nack before its deadline.
+type Lease[T any] struct {
+	q          *LeaseQueue[T]
+	it         *leaseItem[T]
+	deliveries int
+}
+
+// NewLeaseQueue returns a new LeaseQueue in which leases expire after the
+// visibility timeout.
+func NewLeaseQueue[T any](visibility time.Duration) *LeaseQueue[T] {
+	if visibility <= 0 {
+		panic("jobqueue: visibility timeout must be positive")
+	}
+	q := &LeaseQueue[T]{
+		ready: heap.New(func(a, b *leaseItem[T]) bool {
+			if a.prio != b.prio {
+				return a.prio > b.prio
+			}
+			return a.seq < b.seq
+		}),
+		leased: heap.New(func(a, b *leaseItem[T]) bool {
+			return a.deadline.Before(b.deadline)
+		}),
+		visibility: visibility,
+		notify:     make(chan struct{}),
+	}
+	q.leased.SetOnMove(func(it *leaseItem[T], i int) {
+		it.pos = i
+	})
+	return q
+}
+
+// Push adds an item with the given priority. Items with higher priority are
+// delivered first. It returns ErrClosed if the queue is closed.
+func (q *LeaseQueue[T]) Push(x T, prio int) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrClosed
+	}
+	q.seq++
+	q.ready.Push(&leaseItem[T]{val: x, prio: prio, seq: q.seq, pos: -1})
+	q.signal()
+	return nil
+}
+
+// Pop leases the item with the highest priority, waiting until an item is
+// ready, ctx is done, or the queue is closed. It returns ctx's error if ctx is
+// done, and ErrClosed if the queue is closed.
+func (q *LeaseQueue[T]) Pop(ctx context.Context) (*Lease[T], error) {
+	var timer *time.Timer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+	for {
+		q.mu.Lock()
+		now := time.Now()
+		q.expire(now)
+		if q.ready.Len() != 0 {
+			l := q.lease(now)
+			q.mu.Unlock()
+			return l, nil
+		}
+		if q.closed {
+			q.mu.Unlock()
+			return nil, ErrClosed
+		}
+		notify := q.notify
+		// Wake when the next lease expires, returning its item.
+		var expired <-chan time.Time
+		if q.leased.Len() != 0 {
+			d := q.leased.Peek().deadline.Sub(now)
+			if timer == nil {
+				timer = time.NewTimer(d)
+			} else {
+				timer.Reset(d)
+			}
+			expired = timer.C
+		}
+		q.mu.Unlock()
+
+		select {
+		case <-notify:
+		case <-expired:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// TryPop leases the item with the highest priority if one is ready, without
+// waiting. It returns false if no item is ready.
+func (q *LeaseQueue[T]) TryPop() (*Lease[T], bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := time.Now()
+	q.expire(now)
+	if q.ready.Len() == 0 {
+		return nil, false
+	}
+	return q.lease(now), true
+}
+
+// Len returns the number of items that are ready to be delivered.
+func (q *LeaseQueue[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.expire(time.Now())
+	return q.ready.Len()
+}
+
+// InFlight returns the number of items that are leased and not yet
+// acknowledged.
+func (q *LeaseQueue[T]) InFlight() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.expire(time.Now())
+	return q.leased.Len()
+}
+
+// Close closes the queue. Push returns ErrClosed after Close, and Pop returns
+// ErrClosed once no items are ready. Leases that are held can still be
+// acknowledged.
+func (q *LeaseQueue[T]) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.closed {
+		q.closed = true
+		q.signal()
+	}
+}
+
+// Value returns the leased item.
+func (l *Lease[T]) Value() T {
+	return l.it.val
+}
+
+// Deliveries returns the number of times the item has been delivered,
+// including this delivery. An item delivered more than once was not
+// acknowledged in time by an earlier consumer, which may have processed it.
+func (l *Lease[T]) Deliveries() int {
+	return l.deliveries
+}
+
+// Ack removes the item from the queue permanently. It returns ErrLeaseExpired
+// if the lease is no longer held.
+func (l *Lease[T]) Ack() error {
+	q := l.q
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !l.held(time.Now()) {
+		return ErrLeaseExpired
+	}
+	q.leased.Remove(l.it.pos)
+	return nil
+}
+
+// Nack returns the item to the queue to be delivered again at once. It
+// returns ErrLeaseExpired if the lease is no longer held.
+func (l *Lease[T]) Nack() error {
+	q := l.q
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !l.held(time.Now()) {
+		return ErrLeaseExpired
+	}
+	q.leased.Remove(l.it.pos)
+	q.ready.Push(l.it)
+	q.signal()
+	return nil
+}
+
+// Extend moves the lease's deadline to d from now, for a consumer that needs
+// more time to process the item. It returns ErrLeaseExpired if the lease is
+// no longer held.
+func (l *Lease[T]) Extend(d time.Duration) error {
+	q := l.q
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := time.Now()
+	if !l.held(now) {
+		return ErrLeaseExpired
+	}
+	l.it.deadline = now.Add(d)
+	q.leased.Fix(l.it.pos)
+	return nil
+}
+
+// held returns whether the lease is still held at time now. It must be
+// called with the lock held.
+func (l *Lease[T]) held(now time.Time) bool {
+	l.q.expire(now)
+	return l.it.pos >= 0 && l.it.deliveries == l.deliveries
+}
+
+// lease leases the ready item with the highest priority. It must be called
+// with the lock held.
+func (q *LeaseQueue[T]) lease(now time.Time) *Lease[T] {
+	it := q.ready.Pop()
+	it.deliveries++
+	it.deadline = now.Add(q.visibility)
+	q.leased.Push(it)
+	return &Lease[T]{q: q, it: it, deliveries: it.deliveries}
+}
+
+// expire returns items whose leases have expired to the ready heap. It must
+// be called with the lock held.
+func (q *LeaseQueue[T]) expire(now time.Time) {
+	var n int
+	for q.leased.Len() != 0 && !q.leased.Peek().deadline.After(now) {
+		q.ready.Push(q.leased.Pop())
+		n++
+	}
+	if n != 0 {
+		q.signal()
+	}
+}
+
+// signal wakes blocked calls to Pop. It must be called with the lock held.
+func (q *LeaseQueue[T]) signal() {
+	close(q.notify)
+	q.notify = make(chan struct{})
+}
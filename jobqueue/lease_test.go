@@ -0,0 +1,126 @@
+package jobqueue_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gammazero/heap/jobqueue"
+)
+
+func TestLeaseAck(t *testing.T) {
+	q := jobqueue.NewLeaseQueue[string](time.Minute)
+	if err := q.Push("low", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Push("high", 5); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := q.Pop(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Value() != "high" || l.Deliveries() != 1 {
+		t.Fatalf("wrong lease %s deliveries %d", l.Value(), l.Deliveries())
+	}
+	if q.Len() != 1 || q.InFlight() != 1 {
+		t.Fatalf("wrong counts: ready %d in flight %d", q.Len(), q.InFlight())
+	}
+	if err = l.Ack(); err != nil {
+		t.Fatal(err)
+	}
+	if err = l.Ack(); !errors.Is(err, jobqueue.ErrLeaseExpired) {
+		t.Fatalf("expected ErrLeaseExpired for second Ack, got %v", err)
+	}
+	if q.InFlight() != 0 {
+		t.Fatal("acknowledged item still in flight")
+	}
+}
+
+func TestLeaseNack(t *testing.T) {
+	q := jobqueue.NewLeaseQueue[string](time.Minute)
+	if err := q.Push("a", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Push("b", 0); err != nil {
+		t.Fatal(err)
+	}
+	l, _ := q.TryPop()
+	if err := l.Nack(); err != nil {
+		t.Fatal(err)
+	}
+	l, _ = q.TryPop()
+	if l.Value() != "a" || l.Deliveries() != 2 {
+		t.Fatalf("expected a redelivered, got %s with %d deliveries", l.Value(), l.Deliveries())
+	}
+}
+
+func TestLeaseExpiry(t *testing.T) {
+	q := jobqueue.NewLeaseQueue[int](20 * time.Millisecond)
+	if err := q.Push(1, 0); err != nil {
+		t.Fatal(err)
+	}
+	first, _ := q.TryPop()
+	if _, ok := q.TryPop(); ok {
+		t.Fatal("leased item delivered twice before expiry")
+	}
+
+	// Pop blocks until the lease expires and the item is redelivered.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	second, err := q.Pop(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Value() != 1 || second.Deliveries() != 2 {
+		t.Fatalf("wrong redelivery %d with %d deliveries", second.Value(), second.Deliveries())
+	}
+	if err = first.Ack(); !errors.Is(err, jobqueue.ErrLeaseExpired) {
+		t.Fatalf("expected ErrLeaseExpired for expired lease, got %v", err)
+	}
+	if err = second.Extend(time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if err = second.Ack(); err != nil {
+		t.Fatalf("extended lease expired: %v", err)
+	}
+}
+
+func TestLeasePopBlocking(t *testing.T) {
+	q := jobqueue.NewLeaseQueue[int](time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := q.Pop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	pushed := make(chan error, 1)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		pushed <- q.Push(7, 0)
+	}()
+	l, err := q.Pop(context.Background())
+	if err != nil || l.Value() != 7 {
+		t.Fatalf("Pop returned %v, %v", l, err)
+	}
+	if err = <-pushed; err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		q.Close()
+	}()
+	if _, err = q.Pop(context.Background()); !errors.Is(err, jobqueue.ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+	if err = q.Push(1, 0); !errors.Is(err, jobqueue.ErrClosed) {
+		t.Fatalf("expected ErrClosed from Push, got %v", err)
+	}
+	if err = l.Ack(); err != nil {
+		t.Fatal("lease not acknowledged after Close")
+	}
+}
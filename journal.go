@@ -0,0 +1,180 @@
+package heap
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Op identifies a heap operation recorded in a Journal.
+type Op uint8
+
+const (
+	// OpPush records an element pushed onto the heap.
+	OpPush Op = iota + 1
+	// OpPop records the minimum element removed from the heap.
+	OpPop
+	// OpRemove records an element removed by Remove, DeleteFunc, or
+	// Truncate.
+	OpRemove
+	// OpSet records an element replaced by Set.
+	OpSet
+	// OpFix records an element whose position was fixed by Fix or FixAll.
+	OpFix
+	// OpReplace records all of the heap's elements being replaced, by Load
+	// or Decode. The replaced and new elements are not recorded.
+	OpReplace
+	// OpLoad records elements appended by LoadPage. The elements are not
+	// recorded.
+	OpLoad
+	// OpRollback records the changes made since Begin being rolled back.
+	OpRollback
+)
+
+var opNames = [...]string{
+	OpPush:     "push",
+	OpPop:      "pop",
+	OpRemove:   "remove",
+	OpSet:      "set",
+	OpFix:      "fix",
+	OpReplace:  "replace",
+	OpLoad:     "load",
+	OpRollback: "rollback",
+}
+
+func (op Op) String() string {
+	if int(op) < len(opNames) && opNames[op] != "" {
+		return opNames[op]
+	}
+	return fmt.Sprintf("Op(%d)", op)
+}
+
+// Record describes one heap operation.
+type Record struct {
+	// Time is when the operation happened.
+	Time time.Time
+	// Op is the operation.
+	Op Op
+	// Len is the number of elements in the heap after the operation.
+	Len int
+	// Elem describes the element that the operation was applied to, or is
+	// empty if the operation does not apply to a single element.
+	Elem string
+	// Old describes the element that was replaced, for OpSet.
+	Old string
+}
+
+// String formats the record as one line of text.
+func (r Record) String() string {
+	s := fmt.Sprintf("%s %s len=%d", r.Time.Format(time.RFC3339Nano), r.Op, r.Len)
+	if r.Elem != "" {
+		s += " " + r.Elem
+	}
+	if r.Op == OpSet {
+		s += " old=" + r.Old
+	}
+	return s
+}
+
+// Journal records heap operations, so that what happened to an element can
+// be found after the fact, such as whether a missing element was popped,
+// removed, or never pushed. The most recent records are kept in memory, and
+// every record can also be written to an io.Writer. A Journal is safe for
+// concurrent use, and can be shared by multiple heaps.
+type Journal struct {
+	mu    sync.Mutex
+	ring  []Record
+	next  int
+	count int
+	w     io.Writer
+	err   error
+}
+
+// NewJournal returns a Journal that keeps the last size records in memory. If
+// w is not nil, each record is also written to w, as one line of text. Writes
+// are not buffered, so w should be buffered if operations are frequent.
+func NewJournal(size int, w io.Writer) *Journal {
+	if size < 0 {
+		panic("heap: negative journal size")
+	}
+	return &Journal{
+		ring: make([]Record, size),
+		w:    w,
+	}
+}
+
+// Records returns the records kept in memory, from oldest to newest.
+func (j *Journal) Records() []Record {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	n := min(j.count, len(j.ring))
+	out := make([]Record, 0, n)
+	out = append(out, j.ring[j.next:n]...)
+	return append(out, j.ring[:j.next]...)
+}
+
+// Err returns the first error from writing to the Journal's writer. Records
+// are not written after an error.
+func (j *Journal) Err() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.err
+}
+
+func (j *Journal) add(r Record) {
+	r.Time = time.Now()
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.count++
+	if len(j.ring) != 0 {
+		j.ring[j.next] = r
+		j.next = (j.next + 1) % len(j.ring)
+	}
+	if j.w != nil && j.err == nil {
+		_, j.err = io.WriteString(j.w, r.String()+"\n")
+	}
+}
+
+// SetJournal sets the Journal that records the heap's operations. Each
+// operation applied to an element is recorded with a description of the
+// element returned by describe, such as the element's ID. If describe is nil,
+// elements are formatted by fmt.Sprint. Setting j to nil stops the recording.
+//
+// Recording adds the cost of describing the element and reading the time to
+// every heap operation.
+func (h *Heap[T]) SetJournal(j *Journal, describe func(T) string) {
+	if describe == nil {
+		describe = func(x T) string { return fmt.Sprint(x) }
+	}
+	h.journal = j
+	h.describe = describe
+}
+
+// record queues the record of an operation applied to element x, after which
+// the heap holds n elements.
+func (h *Heap[T]) record(op Op, x T, n int) {
+	h.notify(notice[T]{kind: noticeRecord, op: op, x: x, n: n, hasElem: true})
+}
+
+// recordSet queues the record of the replacement of old by x.
+func (h *Heap[T]) recordSet(x, old T, n int) {
+	h.notify(notice[T]{kind: noticeRecord, op: OpSet, x: x, old: old, n: n, hasElem: true, hasOld: true})
+}
+
+// recordOp queues the record of an operation applied to the heap as a whole.
+func (h *Heap[T]) recordOp(op Op, n int) {
+	h.notify(notice[T]{kind: noticeRecord, op: op, n: n})
+}
+
+// makeRecord makes the Record of a queued operation.
+func (h *Heap[T]) makeRecord(nt notice[T]) Record {
+	r := Record{Op: nt.op, Len: nt.n}
+	if nt.hasElem {
+		r.Elem = h.describe(nt.x)
+	}
+	if nt.hasOld {
+		r.Old = h.describe(nt.old)
+	}
+	return r
+}
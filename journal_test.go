@@ -0,0 +1,128 @@
+package heap_test
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gammazero/heap"
+)
+
+func TestJournal(t *testing.T) {
+	var out strings.Builder
+	j := heap.NewJournal(4, &out)
+	h := heap.New(func(a, b int) bool { return a < b })
+	h.SetJournal(j, func(x int) string { return "job-" + strconv.Itoa(x) })
+
+	for _, x := range []int{5, 3, 8, 1} {
+		h.Push(x)
+	}
+	h.Pop()
+	h.Remove(h.Len() - 1)
+	h.Set(0, 9)
+	h.DeleteFunc(func(x int) bool { return x == 9 })
+
+	type rec struct {
+		op   heap.Op
+		n    int
+		elem string
+	}
+	var got []rec
+	for _, r := range j.Records() {
+		if r.Time.IsZero() {
+			t.Fatal("record has no time")
+		}
+		got = append(got, rec{r.Op, r.Len, r.Elem})
+	}
+	want := []rec{
+		{heap.OpPop, 3, "job-1"},
+		{heap.OpRemove, 2, "job-8"},
+		{heap.OpSet, 2, "job-9"},
+		{heap.OpRemove, 1, "job-9"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d records, expected %d: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("record %d is %v, expected %v", i, got[i], want[i])
+		}
+	}
+	if r := j.Records()[2]; r.Old != "job-3" {
+		t.Errorf("set record has old element %q", r.Old)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 8 {
+		t.Fatalf("wrote %d lines, expected 8", len(lines))
+	}
+	if !strings.HasSuffix(lines[0], " push len=1 job-5") {
+		t.Errorf("wrong first line %q", lines[0])
+	}
+	if !strings.HasSuffix(lines[6], " set len=2 job-9 old=job-3") {
+		t.Errorf("wrong set line %q", lines[6])
+	}
+}
+
+func TestJournalBulk(t *testing.T) {
+	j := heap.NewJournal(100, nil)
+	h := heap.NewFrom(func(a, b int) bool { return a < b }, 4, 2, 7, 1, 9)
+	h.SetJournal(j, nil)
+
+	h.Truncate(3)
+	h.FixAll(0, 1)
+	sorted := h.PopAllSorted()
+
+	var ops []string
+	for _, r := range j.Records() {
+		ops = append(ops, r.Op.String()+" "+r.Elem+" "+strconv.Itoa(r.Len))
+	}
+	got := strings.Join(ops, ", ")
+	// The elements removed by Truncate are 7 and 9, in either order.
+	if !strings.HasPrefix(got, "remove 7 4, remove 9 3") && !strings.HasPrefix(got, "remove 9 4, remove 7 3") {
+		t.Fatalf("wrong truncate records: %s", got)
+	}
+	if len(ops) != 2+2+len(sorted) {
+		t.Fatalf("wrong number of records: %s", got)
+	}
+	for _, op := range ops[2:4] {
+		if !strings.HasPrefix(op, "fix ") {
+			t.Fatalf("expected fix records: %s", got)
+		}
+	}
+	for i, op := range ops[4:] {
+		if !strings.HasPrefix(op, "pop ") || !strings.HasSuffix(op, " "+strconv.Itoa(2-i)) {
+			t.Fatalf("expected pop records: %s", got)
+		}
+	}
+
+	h.SetJournal(nil, nil)
+	h.Push(1)
+	if len(j.Records()) != len(ops) {
+		t.Fatal("recorded operation after journal was removed")
+	}
+}
+
+type failWriter struct{}
+
+func (failWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestJournalWriteError(t *testing.T) {
+	j := heap.NewJournal(0, failWriter{})
+	h := heap.New(func(a, b int) bool { return a < b })
+	h.SetJournal(j, nil)
+	h.Push(1)
+	h.Push(2)
+	if j.Err() == nil || j.Err().Error() != "write failed" {
+		t.Fatalf("expected write error, got %v", j.Err())
+	}
+	if len(j.Records()) != 0 {
+		t.Fatal("expected no records kept in memory")
+	}
+	if heap.Op(99).String() != "Op(99)" {
+		t.Fatalf("wrong name for unknown op: %s", heap.Op(99))
+	}
+}
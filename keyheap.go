@@ -0,0 +1,78 @@
+package heap
+
+// KeyHeap is a heap that orders elements by a key computed from each element.
+// The key is computed once when an element is added, or when it is changed by
+// Set or Fix, and is stored with the element. This avoids recomputing keys
+// that are expensive to compute each time elements are compared.
+type KeyHeap[T, K any] struct {
+	h   *Heap[keyed[T, K]]
+	key func(T) K
+}
+
+type keyed[T, K any] struct {
+	key K
+	val T
+}
+
+// NewKeyHeap returns a new KeyHeap that orders elements by the keys returned
+// by the key function, using the less function to compare keys.
+func NewKeyHeap[T, K any](key func(T) K, less func(a, b K) bool) *KeyHeap[T, K] {
+	return &KeyHeap[T, K]{
+		h: New(func(a, b keyed[T, K]) bool {
+			return less(a.key, b.key)
+		}),
+		key: key,
+	}
+}
+
+// Len returns the number of elements in the heap.
+func (kh *KeyHeap[T, K]) Len() int {
+	return kh.h.Len()
+}
+
+// Push computes the key of an element and pushes the element onto the heap.
+func (kh *KeyHeap[T, K]) Push(x T) {
+	kh.h.Push(keyed[T, K]{key: kh.key(x), val: x})
+}
+
+// Pop removes and returns the element with the minimum key.
+func (kh *KeyHeap[T, K]) Pop() T {
+	return kh.h.Pop().val
+}
+
+// Peek returns the element with the minimum key without removing it.
+func (kh *KeyHeap[T, K]) Peek() T {
+	return kh.h.Peek().val
+}
+
+// PeekKey returns the minimum key in the heap.
+func (kh *KeyHeap[T, K]) PeekKey() K {
+	return kh.h.Peek().key
+}
+
+// At returns the element at index i from the heap.
+func (kh *KeyHeap[T, K]) At(i int) T {
+	return kh.h.At(i).val
+}
+
+// Remove removes and returns the element at index i from the heap.
+func (kh *KeyHeap[T, K]) Remove(i int) T {
+	return kh.h.Remove(i).val
+}
+
+// Set replaces the element at index i, computing the key of the new element.
+func (kh *KeyHeap[T, K]) Set(i int, x T) {
+	kh.h.Set(i, keyed[T, K]{key: kh.key(x), val: x})
+}
+
+// Fix recomputes the key of the element at index i and re-establishes the
+// heap ordering. Call Fix after changing an element in a way that changes its
+// key.
+func (kh *KeyHeap[T, K]) Fix(i int) {
+	if i < 0 || i >= len(kh.h.data) {
+		panic("heap: Fix index out of range")
+	}
+	e := &kh.h.data[i]
+	e.key = kh.key(e.val)
+	kh.h.Fix(i)
+}
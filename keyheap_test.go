@@ -0,0 +1,55 @@
+package heap_test
+
+import (
+	"cmp"
+	"strings"
+	"testing"
+
+	"github.com/gammazero/heap"
+)
+
+func TestKeyHeap(t *testing.T) {
+	var keyCalls int
+	key := func(s *string) string {
+		keyCalls++
+		return strings.ToLower(*s)
+	}
+	h := heap.NewKeyHeap(key, cmp.Less[string])
+	words := []string{"Delta", "alpha", "Charlie", "bravo", "Echo"}
+	for i := range words {
+		h.Push(&words[i])
+	}
+	if keyCalls != len(words) {
+		t.Fatalf("expected %d key calls, got %d", len(words), keyCalls)
+	}
+	if *h.Peek() != "alpha" || h.PeekKey() != "alpha" {
+		t.Fatalf("wrong minimum: %s", *h.Peek())
+	}
+
+	// Change an element and fix it.
+	for i := range h.Len() {
+		if p := h.At(i); *p == "Echo" {
+			*p = "Aardvark"
+			h.Fix(i)
+			break
+		}
+	}
+	if *h.Peek() != "Aardvark" {
+		t.Fatalf("expected Aardvark, got %s", *h.Peek())
+	}
+	zulu := "Zulu"
+	h.Set(0, &zulu)
+
+	var out []string
+	for h.Len() != 0 {
+		out = append(out, *h.Pop())
+	}
+	want := []string{"alpha", "bravo", "Charlie", "Delta", "Zulu"}
+	if strings.Join(out, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected %v, got %v", want, out)
+	}
+	if keyCalls != len(words)+2 {
+		t.Fatalf("expected %d key calls, got %d", len(words)+2, keyCalls)
+	}
+	assertPanics(t, "Fix out of range", func() { h.Fix(0) })
+}
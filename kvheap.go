@@ -0,0 +1,112 @@
+package heap
+
+// KVHeap is a heap of key-value pairs ordered by key. Keys are stored in a
+// separate slice from values, so that sifting elements into place only reads
+// and moves keys, and an index for each value. Values stay where they were
+// first stored until they are popped. This makes heap operations much faster
+// than with a Heap of structs when values are large.
+type KVHeap[K, V any] struct {
+	keys  []K
+	slots []int // slots[i] is the index in vals of the value for keys[i]
+	vals  []V
+	free  []int // unused indexes in vals
+	less  func(a, b K) bool
+}
+
+// NewKV returns a new KVHeap that orders keys using the less function.
+func NewKV[K, V any](less func(a, b K) bool) *KVHeap[K, V] {
+	return &KVHeap[K, V]{
+		less: less,
+	}
+}
+
+// Len returns the number of key-value pairs in the heap.
+func (h *KVHeap[K, V]) Len() int {
+	return len(h.keys)
+}
+
+// Push adds a key-value pair to the heap.
+func (h *KVHeap[K, V]) Push(k K, v V) {
+	var slot int
+	if n := len(h.free); n != 0 {
+		slot = h.free[n-1]
+		h.free = h.free[:n-1]
+		h.vals[slot] = v
+	} else {
+		slot = len(h.vals)
+		h.vals = append(h.vals, v)
+	}
+	h.keys = append(h.keys, k)
+	h.slots = append(h.slots, slot)
+	h.up(len(h.keys) - 1)
+}
+
+// Peek returns the key-value pair with the minimum key without removing it.
+func (h *KVHeap[K, V]) Peek() (K, V) {
+	if len(h.keys) == 0 {
+		panic("heap: Peek called on empty KVHeap")
+	}
+	return h.keys[0], h.vals[h.slots[0]]
+}
+
+// Pop removes and returns the key-value pair with the minimum key.
+func (h *KVHeap[K, V]) Pop() (K, V) {
+	if len(h.keys) == 0 {
+		panic("heap: Pop called on empty KVHeap")
+	}
+	k, slot := h.keys[0], h.slots[0]
+	v := h.vals[slot]
+	var zeroV V
+	h.vals[slot] = zeroV
+
+	n := len(h.keys) - 1
+	h.keys[0], h.slots[0] = h.keys[n], h.slots[n]
+	var zeroK K
+	h.keys[n] = zeroK
+	h.keys, h.slots = h.keys[:n], h.slots[:n]
+	if n == 0 {
+		// All values are gone, so start storing values from the beginning.
+		h.vals = h.vals[:0]
+		h.free = h.free[:0]
+	} else {
+		h.free = append(h.free, slot)
+		h.down(0)
+	}
+	return k, v
+}
+
+func (h *KVHeap[K, V]) swap(i, j int) {
+	h.keys[i], h.keys[j] = h.keys[j], h.keys[i]
+	h.slots[i], h.slots[j] = h.slots[j], h.slots[i]
+}
+
+func (h *KVHeap[K, V]) down(i int) {
+	keys := h.keys
+	n := len(keys)
+	for {
+		left := 2*i + 1
+		if left >= n || left < 0 { // left < 0 after int overflow
+			break
+		}
+		j := left
+		if right := left + 1; right < n && h.less(keys[right], keys[left]) {
+			j = right
+		}
+		if !h.less(keys[j], keys[i]) {
+			break
+		}
+		h.swap(i, j)
+		i = j
+	}
+}
+
+func (h *KVHeap[K, V]) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !h.less(h.keys[i], h.keys[parent]) {
+			break
+		}
+		h.swap(i, parent)
+		i = parent
+	}
+}
@@ -0,0 +1,67 @@
+package heap_test
+
+import (
+	"cmp"
+	"math/rand"
+	"testing"
+
+	"github.com/gammazero/heap"
+)
+
+type payload struct {
+	id   int
+	data [192]int64
+}
+
+func TestKVHeap(t *testing.T) {
+	h := heap.NewKV[int, payload](cmp.Less[int])
+	for _, k := range rand.Perm(100) {
+		h.Push(k, payload{id: k})
+	}
+	if k, v := h.Peek(); k != 0 || v.id != 0 {
+		t.Fatalf("expected 0, got %d, %d", k, v.id)
+	}
+	for i := range 100 {
+		k, v := h.Pop()
+		if k != i || v.id != i {
+			t.Fatalf("expected %d, got key %d, value %d", i, k, v.id)
+		}
+		// Reuse freed value slots.
+		if i%2 == 0 {
+			h.Push(1000+i, payload{id: 1000 + i})
+		}
+	}
+	for i := 0; h.Len() != 0; i += 2 {
+		if k, v := h.Pop(); k != 1000+i || v.id != 1000+i {
+			t.Fatalf("expected %d, got key %d, value %d", 1000+i, k, v.id)
+		}
+	}
+	assertPanics(t, "empty Pop", func() { h.Pop() })
+	assertPanics(t, "empty Peek", func() { h.Peek() })
+}
+
+func BenchmarkLargeValues(b *testing.B) {
+	keys := rand.Perm(1000)
+	b.Run("Heap", func(b *testing.B) {
+		h := heap.New(func(a, b payload) bool { return a.id < b.id })
+		for b.Loop() {
+			for _, k := range keys {
+				h.Push(payload{id: k})
+			}
+			for h.Len() != 0 {
+				h.Pop()
+			}
+		}
+	})
+	b.Run("KVHeap", func(b *testing.B) {
+		h := heap.NewKV[int, payload](cmp.Less[int])
+		for b.Loop() {
+			for _, k := range keys {
+				h.Push(k, payload{id: k})
+			}
+			for h.Len() != 0 {
+				h.Pop()
+			}
+		}
+	})
+}
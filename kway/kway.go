@@ -0,0 +1,121 @@
+// Package kway selects the smallest values from several sorted sources, using
+// a heap that holds the frontier of candidate values: one value from each
+// source that has not yet been fully consumed. Each value is selected in
+// O(log k) time for k sources, and sources are read only as far as needed.
+package kway
+
+import (
+	"iter"
+
+	"github.com/gammazero/heap"
+)
+
+type head[T any] struct {
+	val T
+	src int
+}
+
+// Merge returns an iterator over the values of the sources, which must each
+// be sorted by less, in sorted order. Values that are equal are yielded in
+// the order of their sources. Each source is read only as far as needed, so
+// the first k values of many long sources can be taken cheaply.
+func Merge[T any](less func(a, b T) bool, sources ...iter.Seq[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		nexts := make([]func() (T, bool), len(sources))
+		for i, src := range sources {
+			next, stop := iter.Pull(src)
+			defer stop()
+			nexts[i] = next
+		}
+		h := heap.New(func(a, b head[T]) bool {
+			if less(a.val, b.val) {
+				return true
+			}
+			return !less(b.val, a.val) && a.src < b.src
+		}, heap.WithCapacity[head[T]](len(sources)))
+		for i, next := range nexts {
+			if x, ok := next(); ok {
+				h.Push(head[T]{val: x, src: i})
+			}
+		}
+		for h.Len() != 0 {
+			top := h.Peek()
+			if !yield(top.val) {
+				return
+			}
+			// Replace the yielded value with the next from its source.
+			if x, ok := nexts[top.src](); ok {
+				h.Set(0, head[T]{val: x, src: top.src})
+			} else {
+				h.Pop()
+			}
+		}
+	}
+}
+
+// Smallest returns the k smallest values of the sources, which must each be
+// sorted by less, in sorted order. If the sources have fewer than k values in
+// total, all of them are returned.
+func Smallest[T any](k int, less func(a, b T) bool, sources ...iter.Seq[T]) []T {
+	if k < 0 {
+		panic("kway: negative count")
+	}
+	var out []T
+	if k == 0 {
+		return out
+	}
+	for x := range Merge(less, sources...) {
+		out = append(out, x)
+		if len(out) == k {
+			break
+		}
+	}
+	return out
+}
+
+type pair struct {
+	i, j int
+}
+
+// KSmallestPairs returns the k smallest values of combine(a[i], b[j]) over all
+// pairs of indexes i and j, in sorted order by less. For example, with combine
+// returning the sum of a and b, it finds the k smallest sums of an element of
+// a and an element of b. The values of combine must be ordered consistently
+// with the orders of a and b: combine(a[i], b[j]) must not be less than
+// combine(a[i'], b[j']) when i >= i' and j >= j', as is true of sums of sorted
+// slices.
+//
+// Only O(k) pairs are examined, in O(k log k) time, rather than all
+// len(a)*len(b) pairs.
+func KSmallestPairs[A, B, T any](a []A, b []B, k int, combine func(A, B) T, less func(x, y T) bool) []T {
+	if k < 0 {
+		panic("kway: negative count")
+	}
+	if k == 0 || len(a) == 0 || len(b) == 0 {
+		return nil
+	}
+	type cand struct {
+		val T
+		pair
+	}
+	// Each row i is a sorted source of the values combine(a[i], b[j]) for
+	// increasing j. Only the first k rows can contribute.
+	rows := min(len(a), k)
+	h := heap.New(func(x, y cand) bool {
+		return less(x.val, y.val)
+	}, heap.WithCapacity[cand](rows))
+	for i := range rows {
+		h.Push(cand{val: combine(a[i], b[0]), pair: pair{i, 0}})
+	}
+	out := make([]T, 0, min(k, len(a)*len(b)))
+	for len(out) < k && h.Len() != 0 {
+		c := h.Peek()
+		out = append(out, c.val)
+		if j := c.j + 1; j < len(b) {
+			h.Set(0, cand{val: combine(a[c.i], b[j]), pair: pair{c.i, j}})
+		} else {
+			h.Pop()
+		}
+	}
+	return out
+}
@@ -0,0 +1,103 @@
+package kway_test
+
+import (
+	"cmp"
+	"iter"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/gammazero/heap/kway"
+)
+
+func TestMerge(t *testing.T) {
+	var sources [][]int
+	var all []int
+	for range 5 {
+		s := make([]int, rand.Intn(20))
+		for i := range s {
+			s[i] = rand.Intn(50)
+		}
+		slices.Sort(s)
+		sources = append(sources, s)
+		all = append(all, s...)
+	}
+	slices.Sort(all)
+
+	var seqs []iter.Seq[int]
+	for _, s := range sources {
+		seqs = append(seqs, slices.Values(s))
+	}
+	if got := slices.Collect(kway.Merge(cmp.Less[int], seqs...)); !slices.Equal(got, all) {
+		t.Fatalf("wrong merge\n got %v\nwant %v", got, all)
+	}
+	n := min(7, len(all))
+	if got := kway.Smallest(7, cmp.Less[int], seqs...); !slices.Equal(got, all[:n]) {
+		t.Fatalf("wrong smallest %v", got)
+	}
+	if got := kway.Smallest(3, cmp.Less[int]); len(got) != 0 {
+		t.Fatalf("expected nothing from no sources, got %v", got)
+	}
+}
+
+func TestMergeStable(t *testing.T) {
+	type kv struct{ k, src int }
+	less := func(a, b kv) bool { return a.k < b.k }
+	a := []kv{{1, 0}, {2, 0}}
+	b := []kv{{1, 1}, {2, 1}}
+	got := slices.Collect(kway.Merge(less, slices.Values(a), slices.Values(b)))
+	want := []kv{{1, 0}, {1, 1}, {2, 0}, {2, 1}}
+	if !slices.Equal(got, want) {
+		t.Fatalf("equal values not in source order: %v", got)
+	}
+}
+
+func TestMergeReadsLazily(t *testing.T) {
+	var read int
+	counting := func(yield func(int) bool) {
+		for i := 0; ; i++ {
+			read++
+			if !yield(i) {
+				return
+			}
+		}
+	}
+	got := kway.Smallest(5, cmp.Less[int], counting, slices.Values([]int{2, 100}))
+	if !slices.Equal(got, []int{0, 1, 2, 2, 3}) {
+		t.Fatalf("wrong smallest %v", got)
+	}
+	if read > 6 {
+		t.Fatalf("infinite source read %d values", read)
+	}
+}
+
+func TestKSmallestPairs(t *testing.T) {
+	sum := func(x, y int) int { return x + y }
+	for range 20 {
+		a := make([]int, rand.Intn(10))
+		b := make([]int, rand.Intn(10))
+		for i := range a {
+			a[i] = rand.Intn(100)
+		}
+		for i := range b {
+			b[i] = rand.Intn(100)
+		}
+		slices.Sort(a)
+		slices.Sort(b)
+		var all []int
+		for _, x := range a {
+			for _, y := range b {
+				all = append(all, x+y)
+			}
+		}
+		slices.Sort(all)
+		k := rand.Intn(30)
+		got := kway.KSmallestPairs(a, b, k, sum, cmp.Less[int])
+		if want := all[:min(k, len(all))]; !slices.Equal(got, want) && len(want) != 0 {
+			t.Fatalf("a=%v b=%v k=%d: got %v, want %v", a, b, k, got, want)
+		}
+	}
+	if got := kway.KSmallestPairs([]int{1}, []int{}, 3, sum, cmp.Less[int]); got != nil {
+		t.Fatalf("expected no pairs, got %v", got)
+	}
+}
@@ -0,0 +1,124 @@
+// Package lease tracks leases that expire unless they are renewed, such as
+// heartbeats from cluster members or holders of distributed locks.
+//
+// Leases are kept in an expiry heap keyed by lease ID, so registering,
+// renewing, and revoking a lease are O(log n). A single goroutine waits for
+// the earliest expiration and reports expired leases to a callback.
+package lease
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gammazero/heap/expiry"
+)
+
+// Tracker tracks leases by ID. It is safe for concurrent use.
+type Tracker[K comparable] struct {
+	mu       sync.Mutex
+	exp      *expiry.Expiry[K, struct{}]
+	expired  []K
+	onExpire func(K)
+	wake     chan struct{}
+	done     chan struct{}
+	stopped  chan struct{}
+	once     sync.Once
+}
+
+// New creates a Tracker that calls onExpire with the ID of each lease that
+// expires. The callback is called from the Tracker's goroutine, one lease at a
+// time, and may call the Tracker's methods.
+func New[K comparable](onExpire func(id K)) *Tracker[K] {
+	t := &Tracker[K]{
+		onExpire: onExpire,
+		wake:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	t.exp = expiry.New(func(id K, _ struct{}) {
+		t.expired = append(t.expired, id)
+	})
+	go t.run()
+	return t
+}
+
+// Register adds a lease that expires after ttl unless it is renewed. If the
+// lease already exists, its ttl is replaced and it is renewed.
+func (t *Tracker[K]) Register(id K, ttl time.Duration) {
+	t.mu.Lock()
+	t.exp.Add(id, struct{}{}, ttl)
+	t.mu.Unlock()
+	select {
+	case t.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Renew extends a lease by its ttl from now. It returns false if the lease
+// does not exist because it expired, was revoked, or was never registered.
+func (t *Tracker[K]) Renew(id K) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.exp.Touch(id)
+}
+
+// Revoke removes a lease without reporting it as expired. It returns false if
+// the lease does not exist.
+func (t *Tracker[K]) Revoke(id K) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.exp.Remove(id)
+}
+
+// Len returns the number of leases.
+func (t *Tracker[K]) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.exp.Len()
+}
+
+// Close stops the Tracker's goroutine. No more expirations are reported after
+// Close returns.
+func (t *Tracker[K]) Close() {
+	t.once.Do(func() {
+		close(t.done)
+	})
+	<-t.stopped
+}
+
+func (t *Tracker[K]) run() {
+	defer close(t.stopped)
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		t.mu.Lock()
+		t.exp.Sweep(time.Now())
+		expired := t.expired
+		t.expired = nil
+		next, ok := t.exp.NextExpiry()
+		t.mu.Unlock()
+
+		for _, id := range expired {
+			select {
+			case <-t.done:
+				return
+			default:
+			}
+			t.onExpire(id)
+		}
+
+		var wait <-chan time.Time
+		if ok {
+			timer.Reset(time.Until(next))
+			wait = timer.C
+		}
+		select {
+		case <-wait:
+		case <-t.wake:
+		case <-t.done:
+			return
+		}
+	}
+}
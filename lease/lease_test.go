@@ -0,0 +1,90 @@
+package lease_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gammazero/heap/lease"
+)
+
+func TestExpire(t *testing.T) {
+	expired := make(chan string, 3)
+	tr := lease.New(func(id string) { expired <- id })
+	defer tr.Close()
+
+	tr.Register("slow", time.Hour)
+	tr.Register("b", 40*time.Millisecond)
+	tr.Register("a", 20*time.Millisecond)
+	if tr.Len() != 3 {
+		t.Fatalf("expected 3 leases, got %d", tr.Len())
+	}
+
+	for _, want := range []string{"a", "b"} {
+		select {
+		case id := <-expired:
+			if id != want {
+				t.Fatalf("expected %s to expire, got %s", want, id)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for %s to expire", want)
+		}
+	}
+	if tr.Len() != 1 {
+		t.Fatalf("expected 1 lease, got %d", tr.Len())
+	}
+	if tr.Renew("a") {
+		t.Fatal("should not renew expired lease")
+	}
+	if !tr.Revoke("slow") {
+		t.Fatal("expected to revoke lease")
+	}
+}
+
+func TestRenew(t *testing.T) {
+	expired := make(chan string, 1)
+	tr := lease.New(func(id string) { expired <- id })
+	defer tr.Close()
+
+	tr.Register("member", 30*time.Millisecond)
+	deadline := time.Now().Add(100 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+		if !tr.Renew("member") {
+			t.Fatal("lease expired while being renewed")
+		}
+	}
+	select {
+	case id := <-expired:
+		t.Fatalf("renewed lease %s expired", id)
+	default:
+	}
+
+	select {
+	case <-expired:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for lease to expire")
+	}
+}
+
+func TestRenewExpired(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	tr := lease.New(func(id string) {
+		if id == "first" {
+			close(started)
+			<-release
+		}
+	})
+	defer tr.Close()
+	defer close(release)
+
+	// While the tracker is busy reporting the first lease, the second
+	// expires but is not yet swept. It must not be renewed.
+	tr.Register("first", time.Millisecond)
+	<-started
+	tr.Register("second", time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	if tr.Renew("second") {
+		t.Fatal("renewed a lease that had expired")
+	}
+}
@@ -0,0 +1,144 @@
+package heap
+
+import stdheap "container/heap"
+
+// AsInterface returns a container/heap.Interface that operates on the
+// elements of h. This allows code written for container/heap to use a Heap
+// while call sites are migrated. Values passed to the Push method must be of
+// type T.
+//
+// The functions in container/heap maintain the heap order using the methods
+// of the returned value, so h remains a valid heap. The Heap and the returned
+// value can be used in turn, but not at the same time.
+func AsInterface[T any](h *Heap[T]) stdheap.Interface {
+	return heapInterface[T]{h}
+}
+
+type heapInterface[T any] struct {
+	h *Heap[T]
+}
+
+func (a heapInterface[T]) Len() int {
+	a.h.ensureOrdered()
+	return len(a.h.data)
+}
+
+func (a heapInterface[T]) Less(i, j int) bool {
+	return a.h.less(a.h.data[i], a.h.data[j])
+}
+
+func (a heapInterface[T]) Swap(i, j int) {
+	a.h.modify()
+	data := a.h.data
+	data[i], data[j] = data[j], data[i]
+	if a.h.onMove != nil {
+		a.h.onMove(data[i], i)
+		a.h.onMove(data[j], j)
+	}
+}
+
+func (a heapInterface[T]) Push(x any) {
+	h := a.h
+	h.modify()
+	c := cap(h.data)
+	h.grow()
+	h.data = append(h.data, x.(T))
+	if h.onMove != nil {
+		h.onMove(x.(T), len(h.data)-1)
+	}
+	h.watermarksChanged()
+	h.notifyPush(len(h.data), c)
+	if h.journal != nil {
+		h.record(OpPush, x.(T), len(h.data))
+	}
+	h.flush()
+}
+
+func (a heapInterface[T]) Pop() any {
+	h := a.h
+	h.modify()
+	n := len(h.data) - 1
+	x := h.data[n]
+	h.clearSlot(n)
+	h.data = h.data[:n]
+	if h.onMove != nil {
+		h.onMove(x, -1)
+	}
+	h.watermarksChanged()
+	h.notifyPop(len(h.data))
+	if h.journal != nil {
+		h.record(OpPop, x, len(h.data))
+	}
+	h.flush()
+	return x
+}
+
+// Legacy is a generic facade over a container/heap.Interface value. It allows
+// code written for Heap to use an existing container/heap implementation
+// while call sites are migrated.
+type Legacy[T any] struct {
+	h    stdheap.Interface
+	from func(any) T
+	to   func(T) any
+}
+
+// WrapInterface returns a Legacy that operates on h. The from function
+// converts a value returned by the Pop method of h to a T, and the to
+// function converts a T to the value passed to the Push method of h. If from
+// or to is nil, values are converted by type assertion.
+//
+// The Len, Less, and Swap methods of h must already describe a valid heap, as
+// after calling container/heap.Init.
+func WrapInterface[T any](h stdheap.Interface, from func(any) T, to func(T) any) *Legacy[T] {
+	if from == nil {
+		from = func(x any) T { return x.(T) }
+	}
+	if to == nil {
+		to = func(x T) any { return x }
+	}
+	return &Legacy[T]{
+		h:    h,
+		from: from,
+		to:   to,
+	}
+}
+
+// Len returns the number of elements in the heap.
+func (l *Legacy[T]) Len() int {
+	return l.h.Len()
+}
+
+// Push adds an element to the heap.
+func (l *Legacy[T]) Push(x T) {
+	stdheap.Push(l.h, l.to(x))
+}
+
+// Pop removes and returns the minimum element in the heap.
+func (l *Legacy[T]) Pop() T {
+	if l.h.Len() == 0 {
+		panic("heap: Pop called on empty heap")
+	}
+	return l.from(stdheap.Pop(l.h))
+}
+
+// Remove removes and returns the element at index i.
+func (l *Legacy[T]) Remove(i int) T {
+	if i < 0 || i >= l.h.Len() {
+		panic("heap: Remove index out of range")
+	}
+	return l.from(stdheap.Remove(l.h, i))
+}
+
+// Fix re-establishes the heap ordering after the element at index i has
+// changed its value.
+func (l *Legacy[T]) Fix(i int) {
+	if i < 0 || i >= l.h.Len() {
+		panic("heap: Fix index out of range")
+	}
+	stdheap.Fix(l.h, i)
+}
+
+// Unwrap returns the container/heap.Interface value that l operates on.
+func (l *Legacy[T]) Unwrap() stdheap.Interface {
+	return l.h
+}
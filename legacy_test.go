@@ -0,0 +1,103 @@
+package heap_test
+
+import (
+	"cmp"
+	stdheap "container/heap"
+	"slices"
+	"testing"
+
+	"github.com/gammazero/heap"
+)
+
+// intSlice is a container/heap.Interface, as found in legacy code.
+type intSlice []int
+
+func (s intSlice) Len() int           { return len(s) }
+func (s intSlice) Less(i, j int) bool { return s[i] < s[j] }
+func (s intSlice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s *intSlice) Push(x any)        { *s = append(*s, x.(int)) }
+func (s *intSlice) Pop() any {
+	old := *s
+	x := old[len(old)-1]
+	*s = old[:len(old)-1]
+	return x
+}
+
+func TestAsInterface(t *testing.T) {
+	h := heap.New(cmp.Less[int])
+	pos := map[int]int{}
+	h.SetOnMove(func(x, i int) {
+		if i < 0 {
+			delete(pos, x)
+		} else {
+			pos[x] = i
+		}
+	})
+	for _, x := range []int{5, 2, 8} {
+		h.Push(x)
+	}
+	legacy := heap.AsInterface(h)
+	stdheap.Push(legacy, 1)
+	stdheap.Push(legacy, 6)
+	if h.Len() != 5 || h.Peek() != 1 {
+		t.Fatal("heap not updated by container/heap")
+	}
+	if x := stdheap.Pop(legacy).(int); x != 1 {
+		t.Fatalf("expected 1, got %d", x)
+	}
+	for x, i := range pos {
+		if h.At(i) != x {
+			t.Fatalf("index of %d is %d, but At(%d) is %d", x, i, i, h.At(i))
+		}
+	}
+	var out []int
+	for h.Len() != 0 {
+		out = append(out, h.Pop())
+	}
+	if !slices.Equal(out, []int{2, 5, 6, 8}) {
+		t.Fatalf("wrong order: %v", out)
+	}
+	if len(pos) != 0 {
+		t.Fatal("removed elements still tracked")
+	}
+}
+
+func TestWrapInterface(t *testing.T) {
+	s := &intSlice{7, 3, 9}
+	stdheap.Init(s)
+	l := heap.WrapInterface[int](s, nil, nil)
+	l.Push(1)
+	l.Push(5)
+	if l.Len() != 5 {
+		t.Fatalf("expected length 5, got %d", l.Len())
+	}
+	if x := l.Pop(); x != 1 {
+		t.Fatalf("expected 1, got %d", x)
+	}
+	(*s)[0] = 10
+	l.Fix(0)
+	l.Remove(l.Len() - 1)
+	var out []int
+	for l.Len() != 0 {
+		out = append(out, l.Pop())
+	}
+	if !slices.IsSorted(out) || len(out) != 3 {
+		t.Fatalf("wrong output: %v", out)
+	}
+	if l.Unwrap() != stdheap.Interface(s) {
+		t.Fatal("Unwrap returned wrong value")
+	}
+	assertPanics(t, "empty Pop", func() { l.Pop() })
+
+	// Convert between a legacy element type and T.
+	type item struct{ v int }
+	s2 := &intSlice{}
+	l2 := heap.WrapInterface(s2,
+		func(x any) item { return item{x.(int)} },
+		func(x item) any { return x.v })
+	l2.Push(item{2})
+	l2.Push(item{1})
+	if x := l2.Pop(); x.v != 1 {
+		t.Fatalf("expected 1, got %d", x.v)
+	}
+}
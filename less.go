@@ -0,0 +1,64 @@
+package heap
+
+import (
+	"math/big"
+	"net/netip"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// LessTime orders times from earliest to latest. Times are compared by the
+// instant they represent, regardless of location.
+func LessTime(a, b time.Time) bool {
+	return a.Before(b)
+}
+
+// LessDuration orders durations from shortest to longest.
+func LessDuration(a, b time.Duration) bool {
+	return a < b
+}
+
+// LessAddr orders IP addresses as by [netip.Addr.Less]. The invalid zero Addr
+// is first, followed by IPv4 addresses and then IPv6 addresses.
+func LessAddr(a, b netip.Addr) bool {
+	return a.Less(b)
+}
+
+// LessBigInt orders big integers from smallest to largest. A nil *big.Int is
+// ordered before all other values.
+func LessBigInt(a, b *big.Int) bool {
+	if a == nil || b == nil {
+		return a == nil && b != nil
+	}
+	return a.Cmp(b) < 0
+}
+
+// LessFold orders strings without regard to case, by comparing the lower case
+// form of each rune, as by [unicode.ToLower]. Strings that differ only in case
+// are equal. It does not allocate.
+func LessFold(a, b string) bool {
+	for a != "" && b != "" {
+		ra, na := utf8.DecodeRuneInString(a)
+		rb, nb := utf8.DecodeRuneInString(b)
+		if ra != rb {
+			ra, rb = unicode.ToLower(ra), unicode.ToLower(rb)
+			if ra != rb {
+				return ra < rb
+			}
+		}
+		a, b = a[na:], b[nb:]
+	}
+	return a == "" && b != ""
+}
+
+// NewTime returns a new heap of times, ordered from earliest to latest.
+func NewTime(opts ...Option[time.Time]) *Heap[time.Time] {
+	return New(LessTime, opts...)
+}
+
+// NewDuration returns a new heap of durations, ordered from shortest to
+// longest.
+func NewDuration(opts ...Option[time.Duration]) *Heap[time.Duration] {
+	return New(LessDuration, opts...)
+}
@@ -0,0 +1,85 @@
+package heap_test
+
+import (
+	"math/big"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/gammazero/heap"
+)
+
+func TestNewTime(t *testing.T) {
+	now := time.Now()
+	h := heap.NewTime()
+	h.Push(now.Add(time.Hour))
+	h.Push(now.In(time.UTC))
+	h.Push(now.Add(-time.Hour))
+	if x := h.Pop(); !x.Equal(now.Add(-time.Hour)) {
+		t.Fatalf("wrong earliest time %v", x)
+	}
+	if x := h.Pop(); !x.Equal(now) {
+		t.Fatalf("wrong second time %v", x)
+	}
+
+	d := heap.NewDuration()
+	d.Push(time.Second)
+	d.Push(time.Millisecond)
+	if x := d.Pop(); x != time.Millisecond {
+		t.Fatalf("wrong shortest duration %v", x)
+	}
+}
+
+func TestLessAddr(t *testing.T) {
+	h := heap.New(heap.LessAddr)
+	for _, s := range []string{"::1", "10.0.0.2", "10.0.0.10"} {
+		h.Push(netip.MustParseAddr(s))
+	}
+	h.Push(netip.Addr{})
+	want := []string{"invalid IP", "10.0.0.2", "10.0.0.10", "::1"}
+	for _, w := range want {
+		if x := h.Pop(); x.String() != w {
+			t.Fatalf("expected %s, got %s", w, x)
+		}
+	}
+}
+
+func TestLessBigInt(t *testing.T) {
+	h := heap.New(heap.LessBigInt)
+	h.Push(big.NewInt(5))
+	h.Push(nil)
+	h.Push(new(big.Int).Lsh(big.NewInt(1), 100))
+	h.Push(big.NewInt(-7))
+	if x := h.Pop(); x != nil {
+		t.Fatalf("expected nil first, got %v", x)
+	}
+	for _, w := range []string{"-7", "5", "1267650600228229401496703205376"} {
+		if x := h.Pop(); x.String() != w {
+			t.Fatalf("expected %s, got %s", w, x)
+		}
+	}
+	if heap.LessBigInt(nil, nil) {
+		t.Fatal("nil must not be less than nil")
+	}
+}
+
+func TestLessFold(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"apple", "Banana", true},
+		{"Banana", "apple", false},
+		{"ABC", "abc", false},
+		{"abc", "ABC", false},
+		{"ab", "ABC", true},
+		{"Éclair", "éclairs", true},
+		{"", "a", true},
+		{"a", "", false},
+	}
+	for _, tc := range tests {
+		if got := heap.LessFold(tc.a, tc.b); got != tc.want {
+			t.Errorf("LessFold(%q, %q) = %v, want %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package heap
+
+// Max returns the maximum element in the heap without removing it. The
+// maximum is one of the leaves of the heap, so Max scans the last half of the
+// heap in O(n) time. It panics if the heap is empty.
+//
+// Max is useful for occasionally finding the worst element of a heap, such as
+// to shed it from a full buffer. To find the maximum often, keep the elements
+// in a second heap with the opposite ordering.
+func (h *Heap[T]) Max() T {
+	if len(h.data) == 0 {
+		panic("heap: Max called on empty heap")
+	}
+	if h.guard != nil {
+		h.checkRead()
+	}
+	return h.data[h.maxIndex()]
+}
+
+// PopMax removes and returns the maximum element from the heap. Finding the
+// maximum takes O(n) time, as for [Heap.Max], and removing it takes O(log n).
+// It panics if the heap is empty.
+func (h *Heap[T]) PopMax() T {
+	if len(h.data) == 0 {
+		panic("heap: PopMax called on empty heap")
+	}
+	h.ensureOrdered()
+	return h.Remove(h.maxIndex())
+}
+
+// maxIndex returns the index of the maximum element. An element with children
+// is not greater than its children, so only the leaves, which are the
+// elements after the parent of the last element, need to be compared. If the
+// elements are unordered, all of them are compared.
+func (h *Heap[T]) maxIndex() int {
+	h.mustHaveLess()
+	n := len(h.data)
+	m := n / 2
+	if h.unordered {
+		m = 0
+	}
+	for i := m + 1; i < n; i++ {
+		if h.less(h.data[m], h.data[i]) {
+			m = i
+		}
+	}
+	return m
+}
@@ -0,0 +1,62 @@
+package heap_test
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/gammazero/heap"
+)
+
+func TestMax(t *testing.T) {
+	for n := 1; n <= 33; n++ {
+		h := heap.NewFrom(func(a, b int) bool { return a < b }, rand.Perm(n)...)
+		if m := h.Max(); m != n-1 {
+			t.Fatalf("n=%d: Max returned %d", n, m)
+		}
+		for want := n - 1; want >= 0; want-- {
+			if m := h.PopMax(); m != want {
+				t.Fatalf("n=%d: PopMax returned %d, expected %d", n, m, want)
+			}
+			if err := h.Verify(); err != nil {
+				t.Fatalf("n=%d: %v", n, err)
+			}
+		}
+		if h.Len() != 0 {
+			t.Fatalf("n=%d: expected empty heap", n)
+		}
+	}
+
+	assertPanics(t, "Max", func() { heap.New(func(a, b int) bool { return a < b }).Max() })
+	assertPanics(t, "PopMax", func() { heap.New(func(a, b int) bool { return a < b }).PopMax() })
+}
+
+func TestPopMaxOnMove(t *testing.T) {
+	type item struct {
+		val int
+		pos int
+	}
+	h := heap.New(func(a, b *item) bool { return a.val < b.val })
+	h.SetOnMove(func(x *item, i int) { x.pos = i })
+	h.SetScanThreshold(8)
+	vals := make([]int, 50)
+	for i := range vals {
+		vals[i] = rand.IntN(1000)
+		h.Push(&item{val: vals[i]})
+	}
+	slices.Sort(vals)
+	for i := range 25 {
+		x := h.PopMax()
+		if x.val != vals[len(vals)-1-i] {
+			t.Fatalf("PopMax returned %d, expected %d", x.val, vals[len(vals)-1-i])
+		}
+		if x.pos != -1 {
+			t.Fatalf("removed item has index %d", x.pos)
+		}
+	}
+	for i := range h.Len() {
+		if h.At(i).pos != i {
+			t.Fatalf("item at %d has index %d", i, h.At(i).pos)
+		}
+	}
+}
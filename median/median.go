@@ -0,0 +1,141 @@
+// Package median provides a running median of a stream of values, optionally
+// over a sliding window of the most recent values.
+//
+// The values are split between two heaps: a max-heap holding the smaller
+// half of the values and a min-heap holding the larger half. The heaps are
+// kept balanced, so that the median is at the top of one or both heaps. Each
+// Push takes O(log n) time and Median takes O(1) time.
+package median
+
+import "github.com/gammazero/heap"
+
+// Median keeps the median of the values pushed to it. It is not safe for
+// concurrent use.
+type Median[T any] struct {
+	less func(a, b T) bool
+	// low holds the smaller half of the values, with the largest on top.
+	// high holds the larger half, with the smallest on top. low holds the
+	// same number of values as high, or one more.
+	low  *heap.Heap[*entry[T]]
+	high *heap.Heap[*entry[T]]
+	// ring holds the values in the window in the order they were pushed,
+	// and next is the index in ring of the oldest value.
+	ring []*entry[T]
+	next int
+}
+
+type entry[T any] struct {
+	val T
+	pos int
+	low bool
+}
+
+// New returns a new Median of all values pushed, ordered by less.
+func New[T any](less func(a, b T) bool) *Median[T] {
+	m := &Median[T]{
+		less: less,
+		low: heap.New(func(a, b *entry[T]) bool {
+			return less(b.val, a.val)
+		}),
+		high: heap.New(func(a, b *entry[T]) bool {
+			return less(a.val, b.val)
+		}),
+	}
+	m.low.SetOnMove(func(e *entry[T], i int) {
+		e.pos, e.low = i, true
+	})
+	m.high.SetOnMove(func(e *entry[T], i int) {
+		e.pos, e.low = i, false
+	})
+	return m
+}
+
+// NewWindow returns a new Median of the last size values pushed. When more
+// than size values have been pushed, each Push evicts the oldest value.
+func NewWindow[T any](less func(a, b T) bool, size int) *Median[T] {
+	if size < 1 {
+		panic("median: window size must be positive")
+	}
+	m := New(less)
+	m.ring = make([]*entry[T], 0, size)
+	return m
+}
+
+// Len returns the number of values the median is taken over.
+func (m *Median[T]) Len() int {
+	return m.low.Len() + m.high.Len()
+}
+
+// Push adds a value. If the Median has a window that is full, the oldest
+// value is evicted.
+func (m *Median[T]) Push(x T) {
+	e := &entry[T]{val: x}
+	if m.ring != nil {
+		if len(m.ring) == cap(m.ring) {
+			m.remove(m.ring[m.next])
+			m.ring[m.next] = e
+			m.next = (m.next + 1) % len(m.ring)
+		} else {
+			m.ring = append(m.ring, e)
+		}
+	}
+	// Eviction can leave either heap empty, so x is compared with the top of
+	// each heap that has values.
+	if (m.low.Len() != 0 && m.less(m.low.Peek().val, x)) ||
+		(m.high.Len() != 0 && m.less(m.high.Peek().val, x)) {
+		m.high.Push(e)
+	} else {
+		m.low.Push(e)
+	}
+	m.balance()
+}
+
+// Median returns the middle value. If the number of values is even, there
+// are two middle values, and lower and upper are the smaller and larger of
+// them. Otherwise lower and upper are both the middle value. Median panics if
+// there are no values.
+func (m *Median[T]) Median() (lower, upper T) {
+	if m.low.Len() == 0 {
+		panic("median: Median called with no values")
+	}
+	lower = m.low.Peek().val
+	if m.low.Len() == m.high.Len() {
+		return lower, m.high.Peek().val
+	}
+	return lower, lower
+}
+
+// Reset removes all values.
+func (m *Median[T]) Reset() {
+	for m.low.Len() != 0 {
+		m.low.Pop()
+	}
+	for m.high.Len() != 0 {
+		m.high.Pop()
+	}
+	if m.ring != nil {
+		clear(m.ring)
+		m.ring = m.ring[:0]
+		m.next = 0
+	}
+}
+
+// remove removes an entry from whichever heap holds it.
+func (m *Median[T]) remove(e *entry[T]) {
+	if e.low {
+		m.low.Remove(e.pos)
+	} else {
+		m.high.Remove(e.pos)
+	}
+}
+
+// balance moves values between the heaps until low holds the same number of
+// values as high, or one more.
+func (m *Median[T]) balance() {
+	for m.low.Len() > m.high.Len()+1 {
+		m.high.Push(m.low.Pop())
+	}
+	for m.high.Len() > m.low.Len() {
+		m.low.Push(m.high.Pop())
+	}
+}
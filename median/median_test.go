@@ -0,0 +1,93 @@
+package median_test
+
+import (
+	"cmp"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/gammazero/heap/median"
+)
+
+func sortedMedian(vals []int) (int, int) {
+	s := slices.Clone(vals)
+	slices.Sort(s)
+	n := len(s)
+	if n%2 == 1 {
+		return s[n/2], s[n/2]
+	}
+	return s[n/2-1], s[n/2]
+}
+
+func TestMedian(t *testing.T) {
+	m := median.New(cmp.Less[int])
+	var vals []int
+	for i := range 500 {
+		x := rand.Intn(100)
+		m.Push(x)
+		vals = append(vals, x)
+		lo, hi := m.Median()
+		wantLo, wantHi := sortedMedian(vals)
+		if lo != wantLo || hi != wantHi {
+			t.Fatalf("push %d: median is (%d, %d), expected (%d, %d)", i, lo, hi, wantLo, wantHi)
+		}
+	}
+	if m.Len() != 500 {
+		t.Fatalf("expected length 500, got %d", m.Len())
+	}
+	m.Reset()
+	if m.Len() != 0 {
+		t.Fatal("Reset did not remove values")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic from Median with no values")
+		}
+	}()
+	m.Median()
+}
+
+func TestWindow(t *testing.T) {
+	const size = 7
+	m := median.NewWindow(cmp.Less[int], size)
+	var vals []int
+	for i := range 500 {
+		x := rand.Intn(50)
+		m.Push(x)
+		vals = append(vals, x)
+		window := vals[max(0, len(vals)-size):]
+		if m.Len() != len(window) {
+			t.Fatalf("push %d: length %d, expected %d", i, m.Len(), len(window))
+		}
+		lo, hi := m.Median()
+		wantLo, wantHi := sortedMedian(window)
+		if lo != wantLo || hi != wantHi {
+			t.Fatalf("push %d: median is (%d, %d), expected (%d, %d)", i, lo, hi, wantLo, wantHi)
+		}
+	}
+
+	m.Reset()
+	m.Push(3)
+	m.Push(1)
+	if lo, hi := m.Median(); lo != 1 || hi != 3 {
+		t.Fatalf("wrong median (%d, %d) after Reset", lo, hi)
+	}
+}
+
+func TestWindowIncreasing(t *testing.T) {
+	// Evicting the oldest value empties the low half, so each new value
+	// must be compared with the high half.
+	for size := 1; size <= 4; size++ {
+		m := median.NewWindow(cmp.Less[int], size)
+		var vals []int
+		for _, x := range []int{1, 5, 10, 20, 30, 40} {
+			m.Push(x)
+			vals = append(vals, x)
+			lo, hi := m.Median()
+			wantLo, wantHi := sortedMedian(vals[max(0, len(vals)-size):])
+			if lo != wantLo || hi != wantHi {
+				t.Fatalf("size %d, push %d: median is (%d, %d), expected (%d, %d)", size, x, lo, hi, wantLo, wantHi)
+			}
+		}
+	}
+}
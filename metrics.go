@@ -0,0 +1,34 @@
+package heap
+
+// Metrics receives notifications of heap operations, so that a heap can be
+// monitored by a metrics system. Each method is called after the operation
+// completes, with the number of elements then in the heap, so that a gauge of
+// the heap's length can be kept along with counts of operations. The methods
+// are called once the heap is no longer being modified, so they may use the
+// heap.
+type Metrics interface {
+	// OnPush is called when an element is pushed.
+	OnPush(length int)
+	// OnPop is called when the minimum element is removed by Pop.
+	OnPop(length int)
+	// OnRemove is called when elements are removed by any method other than
+	// Pop, with the number of elements removed.
+	OnRemove(removed, length int)
+	// OnGrow is called when the heap's storage is reallocated to hold more
+	// elements, with the new capacity.
+	OnGrow(capacity int)
+}
+
+// SetMetrics sets the Metrics that is notified of heap operations. Setting m
+// to nil stops the notifications.
+func (h *Heap[T]) SetMetrics(m Metrics) {
+	h.metrics = m
+}
+
+// WithMetrics sets the Metrics that is notified of heap operations, as set by
+// [Heap.SetMetrics].
+func WithMetrics[T any](m Metrics) Option[T] {
+	return func(o *options[T]) {
+		o.metrics = m
+	}
+}
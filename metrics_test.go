@@ -0,0 +1,68 @@
+package heap_test
+
+import (
+	"cmp"
+	"testing"
+
+	"github.com/gammazero/heap"
+)
+
+type testMetrics struct {
+	pushes, pops, removes, grows int
+	length, capacity             int
+}
+
+func (m *testMetrics) OnPush(length int) {
+	m.pushes++
+	m.length = length
+}
+
+func (m *testMetrics) OnPop(length int) {
+	m.pops++
+	m.length = length
+}
+
+func (m *testMetrics) OnRemove(removed, length int) {
+	m.removes += removed
+	m.length = length
+}
+
+func (m *testMetrics) OnGrow(capacity int) {
+	m.grows++
+	m.capacity = capacity
+}
+
+func TestMetrics(t *testing.T) {
+	m := &testMetrics{}
+	h := heap.New(cmp.Less[int], heap.WithMetrics[int](m), heap.WithGrowth[int](heap.GrowBy(4)))
+	for i := range 10 {
+		h.Push(i)
+	}
+	if m.pushes != 10 || m.length != 10 {
+		t.Fatalf("wrong push metrics: %+v", m)
+	}
+	if m.grows != 3 || m.capacity != 12 {
+		t.Fatalf("wrong grow metrics: %+v", m)
+	}
+	h.Pop()
+	h.Remove(0)
+	h.Remove(3)
+	if m.pops != 1 || m.removes != 2 || m.length != 7 {
+		t.Fatalf("wrong removal metrics: %+v", m)
+	}
+	n := h.DeleteFunc(func(x int) bool { return x%2 == 0 })
+	if m.removes != 2+n || m.length != h.Len() {
+		t.Fatalf("wrong bulk removal metrics: %+v", m)
+	}
+	n += h.Len()
+	h.PopAllSorted()
+	if m.removes != 2+n || m.length != 0 {
+		t.Fatalf("wrong metrics after PopAllSorted: %+v", m)
+	}
+
+	h.SetMetrics(nil)
+	h.Push(1)
+	if m.pushes != 10 {
+		t.Fatal("metrics notified after removal")
+	}
+}
@@ -0,0 +1,36 @@
+package heap
+
+import "sync/atomic"
+
+// CheckConcurrentUse enables or disables detection of unsynchronized
+// concurrent use of the heap. When enabled, the heap panics if a goroutine
+// reads or modifies the heap while another goroutine is modifying it, instead
+// of silently corrupting the heap ordering. This is similar to the runtime's
+// detection of concurrent map writes.
+//
+// Detection is best-effort; it only catches operations that overlap in time.
+// Use the race detector for complete coverage. Enabling or disabling the
+// check must not itself happen concurrently with other heap operations.
+func (h *Heap[T]) CheckConcurrentUse(enable bool) {
+	if !enable {
+		h.guard = nil
+	} else if h.guard == nil {
+		h.guard = new(atomic.Int32)
+	}
+}
+
+func (h *Heap[T]) startWrite() {
+	if !h.guard.CompareAndSwap(0, 1) {
+		panic("heap: concurrent heap writes")
+	}
+}
+
+func (h *Heap[T]) endWrite() {
+	h.guard.Store(0)
+}
+
+func (h *Heap[T]) checkRead() {
+	if h.guard.Load() != 0 {
+		panic("heap: concurrent heap read and heap write")
+	}
+}
@@ -0,0 +1,103 @@
+package heap_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gammazero/heap"
+)
+
+func TestCheckConcurrentUse(t *testing.T) {
+	var h *heap.Heap[int]
+	var reenter func()
+	h = heap.New(func(a, b int) bool {
+		// Calling back into the heap from the less function looks the same
+		// to the heap as another goroutine using it during a write.
+		if reenter != nil {
+			reenter()
+		}
+		return a < b
+	})
+	h.CheckConcurrentUse(true)
+	h.Push(2)
+	h.Push(1)
+
+	reenter = func() { h.Peek() }
+	assertPanics(t, "should panic on read during write", func() {
+		h.Push(3)
+	})
+
+	reenter = func() { h.Push(4) }
+	assertPanics(t, "should panic on write during write", func() {
+		h.Pop()
+	})
+
+	// The heap remains usable after the detected misuse.
+	reenter = nil
+	if h.Len() == 0 {
+		t.Fatal("expected non-empty heap")
+	}
+	h.Push(0)
+	if h.Pop() != 0 {
+		t.Fatal("expected 0 at head of heap")
+	}
+
+	h.CheckConcurrentUse(false)
+	reenter = func() { h.Len() }
+	h.Push(5)
+}
+
+// lenMetrics reads the length of the heap from each callback.
+type lenMetrics struct {
+	h      *heap.Heap[int]
+	calls  int
+	length int
+}
+
+func (m *lenMetrics) OnPush(int)        { m.update() }
+func (m *lenMetrics) OnPop(int)         { m.update() }
+func (m *lenMetrics) OnRemove(int, int) { m.update() }
+func (m *lenMetrics) OnGrow(int)        {}
+
+func (m *lenMetrics) update() {
+	m.calls++
+	m.length = m.h.Len()
+}
+
+func TestCheckConcurrentUseCallbacks(t *testing.T) {
+	h := heap.New(func(a, b int) bool { return a < b })
+	h.CheckConcurrentUse(true)
+
+	// Callbacks run after the write completes, so they may use the heap.
+	m := &lenMetrics{h: h}
+	h.SetMetrics(m)
+	j := heap.NewJournal(16, nil)
+	h.SetJournal(j, func(x int) string {
+		return fmt.Sprint(x, "/", h.Len())
+	})
+	var highs int
+	h.SetWatermarks(1, 3, func(above bool) {
+		if above {
+			highs++
+			// Shed load from within the callback.
+			h.Pop()
+		}
+	})
+	for i := range 4 {
+		h.Push(i)
+	}
+	h.Remove(h.Len() - 1)
+	if highs != 1 {
+		t.Fatalf("expected high watermark once, got %d", highs)
+	}
+	if h.Len() != 2 || m.length != 2 {
+		t.Fatalf("expected 2 elements, got %d", h.Len())
+	}
+	// Four pushes, the pop by the callback, and the remove.
+	if m.calls != 6 {
+		t.Fatalf("expected 6 metrics calls, got %d", m.calls)
+	}
+	if n := len(j.Records()); n != 6 {
+		t.Fatalf("expected 6 journal records, got %d", n)
+	}
+}
@@ -0,0 +1,152 @@
+// Package mlfq provides a multi-level feedback queue scheduler.
+//
+// Tasks start in the highest-priority level, level 0. A task that uses up the
+// time quantum of its level is demoted to the next lower-priority level, so
+// long-running tasks sink while short, interactive tasks stay near the top.
+// Periodically all tasks are boosted back to level 0 so that demoted tasks are
+// not starved. Within a level, tasks are scheduled round-robin.
+//
+// The scheduler does not run tasks itself. The caller takes the next task with
+// Next, runs it, and then returns it with Yield along with how long it ran.
+package mlfq
+
+import (
+	"time"
+
+	"github.com/gammazero/heap"
+)
+
+// Policy configures the levels of a Scheduler.
+type Policy interface {
+	// Levels returns the number of priority levels.
+	Levels() int
+	// Quantum returns the amount of time a task may use at the given level
+	// before being demoted.
+	Quantum(level int) time.Duration
+	// BoostInterval returns how often all tasks are moved back to level 0.
+	// Zero disables periodic boosting.
+	BoostInterval() time.Duration
+}
+
+// DefaultPolicy is a Policy whose quantum doubles at each lower level.
+type DefaultPolicy struct {
+	NumLevels   int
+	BaseQuantum time.Duration
+	Boost       time.Duration
+}
+
+// Levels implements Policy.
+func (p DefaultPolicy) Levels() int {
+	return max(p.NumLevels, 1)
+}
+
+// Quantum implements Policy.
+func (p DefaultPolicy) Quantum(level int) time.Duration {
+	return p.BaseQuantum << level
+}
+
+// BoostInterval implements Policy.
+func (p DefaultPolicy) BoostInterval() time.Duration {
+	return p.Boost
+}
+
+// Task is a task managed by a Scheduler.
+type Task[T any] struct {
+	Value T
+	level int
+	used  time.Duration
+	seq   uint64
+	boost uint64
+}
+
+// Level returns the task's current priority level.
+func (t *Task[T]) Level() int {
+	return t.level
+}
+
+// Scheduler is a multi-level feedback queue. It is not safe for concurrent
+// use.
+type Scheduler[T any] struct {
+	h         *heap.Heap[*Task[T]]
+	policy    Policy
+	seq       uint64
+	boost     uint64
+	lastBoost time.Time
+}
+
+// New returns a new Scheduler that uses the given policy.
+func New[T any](policy Policy) *Scheduler[T] {
+	return &Scheduler[T]{
+		h:         heap.New(lessTask[T]),
+		policy:    policy,
+		lastBoost: time.Now(),
+	}
+}
+
+func lessTask[T any](a, b *Task[T]) bool {
+	if a.level != b.level {
+		return a.level < b.level
+	}
+	return a.seq < b.seq
+}
+
+// Len returns the number of tasks waiting to be scheduled.
+func (s *Scheduler[T]) Len() int {
+	return s.h.Len()
+}
+
+// Add adds a new task at level 0 and returns it.
+func (s *Scheduler[T]) Add(x T) *Task[T] {
+	t := &Task[T]{Value: x, boost: s.boost}
+	s.enqueue(t)
+	return t
+}
+
+// Next removes and returns the waiting task with the highest priority. If the
+// boost interval has elapsed, all tasks are boosted first. The second return
+// value is false if there are no waiting tasks.
+func (s *Scheduler[T]) Next() (*Task[T], bool) {
+	if iv := s.policy.BoostInterval(); iv > 0 && time.Since(s.lastBoost) >= iv {
+		s.Boost()
+	}
+	if s.h.Len() == 0 {
+		return nil, false
+	}
+	return s.h.Pop(), true
+}
+
+// Yield returns a task taken by Next to the scheduler after it ran for the
+// given duration. If the task has used up its level's quantum, it is demoted
+// to the next level. A task that has finished should not be yielded.
+func (s *Scheduler[T]) Yield(t *Task[T], used time.Duration) {
+	if t.boost != s.boost {
+		// Boosted while running.
+		t.level, t.used, t.boost = 0, 0, s.boost
+	}
+	t.used += used
+	if t.used >= s.policy.Quantum(t.level) && t.level < s.policy.Levels()-1 {
+		t.level++
+		t.used = 0
+	}
+	s.enqueue(t)
+}
+
+// Boost moves all tasks, including tasks that are currently running, back to
+// level 0.
+func (s *Scheduler[T]) Boost() {
+	s.boost++
+	s.lastBoost = time.Now()
+	tasks := make([]*Task[T], s.h.Len())
+	for i := range tasks {
+		t := s.h.At(i)
+		t.level, t.used, t.boost = 0, 0, s.boost
+		tasks[i] = t
+	}
+	s.h = heap.NewFrom(lessTask[T], tasks...)
+}
+
+func (s *Scheduler[T]) enqueue(t *Task[T]) {
+	s.seq++
+	t.seq = s.seq
+	s.h.Push(t)
+}
@@ -0,0 +1,101 @@
+package mlfq_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gammazero/heap/mlfq"
+)
+
+func TestDemotion(t *testing.T) {
+	s := mlfq.New[string](mlfq.DefaultPolicy{
+		NumLevels:   3,
+		BaseQuantum: 10 * time.Millisecond,
+	})
+	s.Add("cpu")
+	s.Add("io")
+
+	// The cpu task uses its whole quantum and is demoted, while the io task
+	// yields early and stays at level 0.
+	task, _ := s.Next()
+	if task.Value != "cpu" {
+		t.Fatalf("expected cpu, got %s", task.Value)
+	}
+	s.Yield(task, 10*time.Millisecond)
+	if task.Level() != 1 {
+		t.Fatalf("expected level 1, got %d", task.Level())
+	}
+
+	task, _ = s.Next()
+	if task.Value != "io" {
+		t.Fatalf("expected io, got %s", task.Value)
+	}
+	s.Yield(task, time.Millisecond)
+	if task.Level() != 0 {
+		t.Fatalf("expected level 0, got %d", task.Level())
+	}
+
+	task, _ = s.Next()
+	if task.Value != "io" {
+		t.Fatalf("expected io to run before demoted cpu, got %s", task.Value)
+	}
+	s.Yield(task, time.Millisecond)
+
+	cpu := s.Add("cpu2")
+	for range 2 {
+		task, _ = s.Next()
+		if task.Level() != 0 {
+			t.Fatalf("expected level 0 task, got level %d", task.Level())
+		}
+		s.Yield(task, time.Hour)
+	}
+	if cpu.Level() != 1 {
+		t.Fatalf("expected level 1, got %d", cpu.Level())
+	}
+
+	// Tasks do not fall below the lowest level.
+	for range 6 {
+		task, _ = s.Next()
+		s.Yield(task, time.Hour)
+	}
+	for range s.Len() {
+		task, _ = s.Next()
+		if task.Level() != 2 {
+			t.Fatalf("expected level 2, got %d", task.Level())
+		}
+	}
+	if _, ok := s.Next(); ok {
+		t.Fatal("expected no tasks")
+	}
+}
+
+func TestBoost(t *testing.T) {
+	s := mlfq.New[int](mlfq.DefaultPolicy{
+		NumLevels:   4,
+		BaseQuantum: time.Millisecond,
+		Boost:       20 * time.Millisecond,
+	})
+	for i := range 3 {
+		s.Add(i)
+	}
+	for range 6 {
+		task, _ := s.Next()
+		s.Yield(task, time.Second)
+	}
+	running, _ := s.Next()
+	if running.Level() != 2 {
+		t.Fatalf("expected level 2, got %d", running.Level())
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	task, _ := s.Next()
+	if task.Level() != 0 {
+		t.Fatalf("expected boosted task at level 0, got %d", task.Level())
+	}
+
+	// A task that was running during the boost returns at level 0.
+	s.Yield(running, 0)
+	if running.Level() != 0 {
+		t.Fatalf("expected running task boosted to level 0, got %d", running.Level())
+	}
+}
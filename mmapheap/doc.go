@@ -0,0 +1,15 @@
+// Package mmapheap provides a heap of fixed-size records stored in a
+// memory-mapped file, so that large heaps survive restarts and do not occupy
+// memory managed by the Go garbage collector.
+//
+// Each element is stored as a record of a fixed number of bytes. The caller
+// supplies functions that convert an element to and from its record. The file
+// holds a small header followed by the records in heap order.
+//
+// Operations update the file in place, in several steps, so only a heap that
+// was closed, or whose process exited between operations, can be reopened
+// safely. If the process or system stops part way through an operation, the
+// file may have lost or duplicated an element, and is not recovered.
+//
+// This package is only available on Linux.
+package mmapheap
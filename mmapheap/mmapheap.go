@@ -0,0 +1,247 @@
+//go:build linux
+
+package mmapheap
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// ErrFormat is returned by Open when a file is not a heap file, or holds
+// records of a different size.
+var ErrFormat = errors.New("mmapheap: invalid heap file")
+
+const (
+	// headerSize is the size of the file header: the magic bytes, the record
+	// size, and the number of elements.
+	headerSize = 16
+	// minRecords is the number of records that space is allocated for when a
+	// heap file is created.
+	minRecords = 64
+)
+
+var magic = [4]byte{'M', 'H', 'E', 'P'}
+
+// MmapHeap is a heap stored in a memory-mapped file. It is not safe for
+// concurrent use.
+type MmapHeap[T any] struct {
+	f    *os.File
+	buf  []byte
+	tmp  []byte
+	size int
+	n    int
+	less func(a, b T) bool
+	put  func(b []byte, x T)
+	get  func(b []byte) T
+}
+
+// Open opens the heap file at path, creating it if it does not exist. Each
+// element is stored in a record of size bytes, written by put and read by get.
+// If the file already holds a heap, it is reopened, so the heap survives a
+// restart. Only a heap left between operations can be reopened safely; see
+// the package documentation.
+func Open[T any](path string, less func(a, b T) bool, size int, put func(b []byte, x T), get func(b []byte) T) (*MmapHeap[T], error) {
+	if size < 1 {
+		panic("mmapheap: record size must be positive")
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	h := &MmapHeap[T]{
+		f:    f,
+		tmp:  make([]byte, size),
+		size: size,
+		less: less,
+		put:  put,
+		get:  get,
+	}
+	if err = h.init(); err != nil {
+		// The error from init is the one reported.
+		_ = h.unmap()
+		_ = f.Close()
+		return nil, err
+	}
+	return h, nil
+}
+
+func (h *MmapHeap[T]) init() error {
+	fi, err := h.f.Stat()
+	if err != nil {
+		return err
+	}
+	if fi.Size() == 0 {
+		if err = h.remap(headerSize + minRecords*h.size); err != nil {
+			return err
+		}
+		copy(h.buf, magic[:])
+		binary.BigEndian.PutUint32(h.buf[4:], uint32(h.size))
+		h.setLen(0)
+		return nil
+	}
+	if fi.Size() < headerSize {
+		return ErrFormat
+	}
+	if err = h.remap(int(fi.Size())); err != nil {
+		return err
+	}
+	if [4]byte(h.buf[:4]) != magic || binary.BigEndian.Uint32(h.buf[4:]) != uint32(h.size) {
+		return ErrFormat
+	}
+	n := binary.BigEndian.Uint64(h.buf[8:])
+	if n > uint64((len(h.buf)-headerSize)/h.size) {
+		return ErrFormat
+	}
+	h.n = int(n)
+	return nil
+}
+
+// Len returns the number of elements in the heap.
+func (h *MmapHeap[T]) Len() int {
+	return h.n
+}
+
+// Push adds an element to the heap. The file is extended if there is no
+// space for the element.
+func (h *MmapHeap[T]) Push(x T) error {
+	if end := h.offset(h.n + 1); end > len(h.buf) {
+		if err := h.remap(max(2*len(h.buf), end)); err != nil {
+			return err
+		}
+	}
+	h.put(h.record(h.n), x)
+	h.setLen(h.n + 1)
+	h.up(h.n - 1)
+	return nil
+}
+
+// Pop removes and returns the minimum element in the heap.
+func (h *MmapHeap[T]) Pop() T {
+	if h.n == 0 {
+		panic("mmapheap: Pop called on empty heap")
+	}
+	x := h.get(h.record(0))
+	n := h.n - 1
+	h.swap(0, n)
+	h.setLen(n)
+	h.down(0)
+	return x
+}
+
+// Peek returns the minimum element in the heap without removing it.
+func (h *MmapHeap[T]) Peek() T {
+	if h.n == 0 {
+		panic("mmapheap: Peek called on empty heap")
+	}
+	return h.get(h.record(0))
+}
+
+// Sync writes changes to the heap to stable storage. Changes are written by
+// the operating system even if the process exits without calling Sync, but
+// may be lost if the system crashes.
+func (h *MmapHeap[T]) Sync() error {
+	if len(h.buf) == 0 {
+		return nil
+	}
+	_, _, errno := syscall.Syscall(syscall.SYS_MSYNC,
+		uintptr(unsafe.Pointer(&h.buf[0])), uintptr(len(h.buf)), syscall.MS_SYNC)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// Close syncs the heap to storage and closes the file. The heap must not be
+// used after it is closed.
+func (h *MmapHeap[T]) Close() error {
+	err := h.Sync()
+	if uerr := h.unmap(); err == nil {
+		err = uerr
+	}
+	if cerr := h.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// remap resizes the file to length bytes and maps it into memory.
+func (h *MmapHeap[T]) remap(length int) error {
+	if err := h.unmap(); err != nil {
+		return err
+	}
+	if err := h.f.Truncate(int64(length)); err != nil {
+		return err
+	}
+	buf, err := syscall.Mmap(int(h.f.Fd()), 0, length, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return err
+	}
+	h.buf = buf
+	return nil
+}
+
+func (h *MmapHeap[T]) unmap() error {
+	if h.buf == nil {
+		return nil
+	}
+	err := syscall.Munmap(h.buf)
+	h.buf = nil
+	return err
+}
+
+func (h *MmapHeap[T]) setLen(n int) {
+	h.n = n
+	binary.BigEndian.PutUint64(h.buf[8:], uint64(n))
+}
+
+func (h *MmapHeap[T]) offset(i int) int {
+	return headerSize + i*h.size
+}
+
+func (h *MmapHeap[T]) record(i int) []byte {
+	off := h.offset(i)
+	return h.buf[off : off+h.size : off+h.size]
+}
+
+func (h *MmapHeap[T]) lessAt(i, j int) bool {
+	return h.less(h.get(h.record(i)), h.get(h.record(j)))
+}
+
+func (h *MmapHeap[T]) swap(i, j int) {
+	a, b := h.record(i), h.record(j)
+	copy(h.tmp, a)
+	copy(a, b)
+	copy(b, h.tmp)
+}
+
+func (h *MmapHeap[T]) down(i int) {
+	for {
+		left := 2*i + 1
+		if left >= h.n || left < 0 {
+			return
+		}
+		j := left
+		if right := left + 1; right < h.n && h.lessAt(right, left) {
+			j = right
+		}
+		if !h.lessAt(j, i) {
+			return
+		}
+		h.swap(i, j)
+		i = j
+	}
+}
+
+func (h *MmapHeap[T]) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !h.lessAt(i, parent) {
+			return
+		}
+		h.swap(i, parent)
+		i = parent
+	}
+}
@@ -0,0 +1,111 @@
+//go:build linux
+
+package mmapheap_test
+
+import (
+	"cmp"
+	"encoding/binary"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gammazero/heap/mmapheap"
+)
+
+func putInt(b []byte, x int) {
+	binary.BigEndian.PutUint64(b, uint64(x))
+}
+
+func getInt(b []byte) int {
+	return int(binary.BigEndian.Uint64(b))
+}
+
+func openInts(t *testing.T, path string) *mmapheap.MmapHeap[int] {
+	t.Helper()
+	h, err := mmapheap.Open(path, cmp.Less[int], 8, putInt, getInt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func TestMmapHeap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "heap")
+	h := openInts(t, path)
+	const n = 1000
+	for range n {
+		if err := h.Push(rand.Intn(n)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if h.Len() != n {
+		t.Fatalf("expected length %d, got %d", n, h.Len())
+	}
+	for range n / 2 {
+		h.Pop()
+	}
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	h = openInts(t, path)
+	defer func() {
+		if err := h.Close(); err != nil {
+			t.Error(err)
+		}
+	}()
+	if h.Len() != n/2 {
+		t.Fatalf("expected length %d after reopen, got %d", n/2, h.Len())
+	}
+	prev := h.Peek()
+	for h.Len() != 0 {
+		x := h.Pop()
+		if x < prev {
+			t.Fatalf("element out of order: %d < %d", x, prev)
+		}
+		prev = x
+	}
+	if err := h.Sync(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOpenErrors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "heap")
+	h := openInts(t, path)
+	if err := h.Push(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := mmapheap.Open(path, cmp.Less[int], 4, putInt, getInt); err != mmapheap.ErrFormat {
+		t.Fatalf("expected ErrFormat for wrong record size, got %v", err)
+	}
+
+	bad := filepath.Join(dir, "bad")
+	if err := os.WriteFile(bad, []byte("not a heap file at all"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mmapheap.Open(bad, cmp.Less[int], 8, putInt, getInt); err != mmapheap.ErrFormat {
+		t.Fatalf("expected ErrFormat, got %v", err)
+	}
+}
+
+func TestEmptyPanics(t *testing.T) {
+	h := openInts(t, filepath.Join(t.TempDir(), "heap"))
+	defer func() {
+		if err := h.Close(); err != nil {
+			t.Error(err)
+		}
+	}()
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+	h.Pop()
+}
@@ -0,0 +1,121 @@
+// Package multiqueue provides a relaxed concurrent priority queue.
+//
+// A MultiQueue is made up of many independently locked heaps. Push adds an
+// element to a randomly chosen heap, and Pop removes the minimum element from
+// the better of two randomly chosen heaps. The element returned by Pop is
+// therefore only approximately the minimum of the whole queue, but producers
+// and consumers rarely contend for the same lock, so throughput scales with
+// the number of goroutines using the queue.
+package multiqueue
+
+import (
+	"math/rand/v2"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gammazero/heap"
+)
+
+// MultiQueue is a relaxed priority queue that is safe for concurrent use.
+type MultiQueue[T any] struct {
+	shards []shard[T]
+	less   func(a, b T) bool
+	length atomic.Int64
+}
+
+type shard[T any] struct {
+	mu sync.Mutex
+	h  *heap.Heap[T]
+	_  [64]byte // keep shard locks on separate cache lines
+}
+
+// New returns a new MultiQueue with the given less function. The queue has c
+// heaps for each of GOMAXPROCS processors. If c is less than 2, then 2 is used.
+func New[T any](less func(a, b T) bool, c int) *MultiQueue[T] {
+	if c < 2 {
+		c = 2
+	}
+	n := c * runtime.GOMAXPROCS(0)
+	q := &MultiQueue[T]{
+		shards: make([]shard[T], n),
+		less:   less,
+	}
+	for i := range q.shards {
+		q.shards[i].h = heap.New(less)
+	}
+	return q
+}
+
+// Len returns the number of elements in the queue.
+func (q *MultiQueue[T]) Len() int {
+	return int(q.length.Load())
+}
+
+// Push adds the given element to a randomly chosen heap.
+func (q *MultiQueue[T]) Push(x T) {
+	// Count the element before it can be popped, so that a concurrent Pop
+	// cannot make the length negative.
+	q.length.Add(1)
+	s := &q.shards[rand.IntN(len(q.shards))]
+	s.mu.Lock()
+	s.h.Push(x)
+	s.mu.Unlock()
+}
+
+// Pop removes and returns the smaller of the minimum elements of two randomly
+// chosen heaps. If both of those heaps are empty, then Pop looks at every heap
+// before reporting that the queue is empty. The second return value is false
+// if the queue is empty.
+func (q *MultiQueue[T]) Pop() (T, bool) {
+	n := len(q.shards)
+	i := rand.IntN(n)
+	j := rand.IntN(n - 1)
+	if j >= i {
+		j++
+	}
+	if i > j {
+		i, j = j, i
+	}
+	a, b := &q.shards[i], &q.shards[j]
+	a.mu.Lock()
+	b.mu.Lock()
+	src := a
+	switch {
+	case a.h.Len() == 0:
+		src = b
+	case b.h.Len() != 0 && q.less(b.h.Peek(), a.h.Peek()):
+		src = b
+	}
+	if src.h.Len() != 0 {
+		x := src.h.Pop()
+		b.mu.Unlock()
+		a.mu.Unlock()
+		q.length.Add(-1)
+		return x, true
+	}
+	b.mu.Unlock()
+	a.mu.Unlock()
+	return q.popAny(i)
+}
+
+// popAny pops from the first non-empty heap, starting at index start.
+func (q *MultiQueue[T]) popAny(start int) (T, bool) {
+	n := len(q.shards)
+	for k := range n {
+		if q.length.Load() == 0 {
+			break
+		}
+		s := &q.shards[(start+k)%n]
+		s.mu.Lock()
+		if s.h.Len() != 0 {
+			x := s.h.Pop()
+			s.mu.Unlock()
+			q.length.Add(-1)
+			return x, true
+		}
+		s.mu.Unlock()
+	}
+	var zero T
+	return zero, false
+}
@@ -0,0 +1,107 @@
+package multiqueue_test
+
+import (
+	"cmp"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/gammazero/heap/multiqueue"
+)
+
+func TestPushPop(t *testing.T) {
+	q := multiqueue.New(cmp.Less[int], 2)
+	const n = 1000
+	for i := n - 1; i >= 0; i-- {
+		q.Push(i)
+	}
+	if q.Len() != n {
+		t.Fatalf("expected length %d, got %d", n, q.Len())
+	}
+
+	seen := make([]int, 0, n)
+	for {
+		x, ok := q.Pop()
+		if !ok {
+			break
+		}
+		seen = append(seen, x)
+	}
+	if len(seen) != n {
+		t.Fatalf("expected %d elements, got %d", n, len(seen))
+	}
+	if q.Len() != 0 {
+		t.Fatalf("expected empty queue, got length %d", q.Len())
+	}
+	sort.Ints(seen)
+	for i, x := range seen {
+		if x != i {
+			t.Fatalf("missing element %d", i)
+		}
+	}
+}
+
+func TestPopEmpty(t *testing.T) {
+	q := multiqueue.New(cmp.Less[int], 0)
+	if _, ok := q.Pop(); ok {
+		t.Fatal("expected Pop on empty queue to fail")
+	}
+	q.Push(7)
+	x, ok := q.Pop()
+	if !ok || x != 7 {
+		t.Fatalf("expected 7, got %d", x)
+	}
+}
+
+func TestConcurrent(t *testing.T) {
+	q := multiqueue.New(cmp.Less[int], 4)
+	const workers = 8
+	const perWorker = 2000
+
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range perWorker {
+				q.Push(w*perWorker + i)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var mu sync.Mutex
+	seen := make(map[int]bool)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				x, ok := q.Pop()
+				if !ok {
+					return
+				}
+				mu.Lock()
+				seen[x] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(seen) != workers*perWorker {
+		t.Fatalf("expected %d elements, got %d", workers*perWorker, len(seen))
+	}
+}
+
+func BenchmarkPushPop(b *testing.B) {
+	q := multiqueue.New(cmp.Less[int], 2)
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			q.Push(i)
+			q.Pop()
+			i++
+		}
+	})
+}
@@ -0,0 +1,121 @@
+package heap
+
+// Notifications of heap operations, to the watermark function, Metrics, and
+// Journal, are queued while an operation modifies the heap, and are delivered
+// after the operation completes and releases the heap's write guard. This lets
+// the callbacks use the heap, such as to read its length, even when
+// concurrent use is being checked.
+
+type noticeKind uint8
+
+const (
+	noticeWatermarks noticeKind = iota
+	noticePush
+	noticePop
+	noticeRemove
+	noticeGrow
+	noticeRecord
+)
+
+// notice is a queued notification. For metrics, n is the length of the heap
+// and m is the number removed or the new capacity. For journal records, x
+// and old are the elements, if hasElem and hasOld are set.
+type notice[T any] struct {
+	kind    noticeKind
+	op      Op
+	n, m    int
+	x, old  T
+	hasElem bool
+	hasOld  bool
+}
+
+// endOp ends an operation that modifies the heap. It releases the write
+// guard, and then delivers the notifications queued by the operation.
+func (h *Heap[T]) endOp() {
+	if h.guard != nil {
+		h.endWrite()
+	}
+	if len(h.notices) != 0 {
+		h.flush()
+	}
+}
+
+func (h *Heap[T]) notify(nt notice[T]) {
+	h.notices = append(h.notices, nt)
+}
+
+// watermarksChanged queues a check of the watermarks, if they are set.
+func (h *Heap[T]) watermarksChanged() {
+	if h.wm != nil {
+		h.notify(notice[T]{kind: noticeWatermarks})
+	}
+}
+
+// notifyPush queues the metrics notifications for a push, after which the
+// heap holds n elements, and whose storage had capacity c before the push.
+func (h *Heap[T]) notifyPush(n, c int) {
+	if h.metrics != nil {
+		if cap(h.data) != c {
+			h.notify(notice[T]{kind: noticeGrow, m: cap(h.data)})
+		}
+		h.notify(notice[T]{kind: noticePush, n: n})
+	}
+}
+
+// notifyPop queues the metrics notification for a pop, after which the heap
+// holds n elements.
+func (h *Heap[T]) notifyPop(n int) {
+	if h.metrics != nil {
+		h.notify(notice[T]{kind: noticePop, n: n})
+	}
+}
+
+// notifyRemove queues the metrics notification for the removal of m
+// elements, after which the heap holds n elements.
+func (h *Heap[T]) notifyRemove(m, n int) {
+	if h.metrics != nil {
+		h.notify(notice[T]{kind: noticeRemove, n: n, m: m})
+	}
+}
+
+// flush delivers the queued notifications. Notifications queued by the
+// callbacks, if they modify the heap, are delivered in turn.
+func (h *Heap[T]) flush() {
+	if h.flushing {
+		return
+	}
+	h.flushing = true
+	defer func() {
+		clear(h.notices)
+		h.notices = h.notices[:0]
+		h.flushing = false
+	}()
+	for i := 0; i < len(h.notices); i++ {
+		nt := h.notices[i]
+		switch nt.kind {
+		case noticeWatermarks:
+			h.checkWatermarks()
+		case noticeRecord:
+			if h.journal != nil {
+				h.journal.add(h.makeRecord(nt))
+			}
+		default:
+			if h.metrics != nil {
+				h.deliverMetrics(nt)
+			}
+		}
+	}
+}
+
+func (h *Heap[T]) deliverMetrics(nt notice[T]) {
+	switch nt.kind {
+	case noticePush:
+		h.metrics.OnPush(nt.n)
+	case noticePop:
+		h.metrics.OnPop(nt.n)
+	case noticeRemove:
+		h.metrics.OnRemove(nt.m, nt.n)
+	case noticeGrow:
+		h.metrics.OnGrow(nt.m)
+	}
+}
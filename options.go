@@ -0,0 +1,79 @@
+package heap
+
+// Option configures a heap of elements of type T created by New. The element
+// type must be given to options that have no argument of that type, as in
+// heap.WithCapacity[int](100).
+type Option[T any] func(*options[T])
+
+type options[T any] struct {
+	capacity int
+	onMove   func(x T, i int)
+	growth   Growth
+	scanMax  int
+	lazy     bool
+	metrics  Metrics
+}
+
+// WithCapacity allocates storage for n elements when the heap is created.
+func WithCapacity[T any](n int) Option[T] {
+	if n < 0 {
+		panic("heap: negative capacity")
+	}
+	return func(o *options[T]) {
+		o.capacity = n
+	}
+}
+
+// WithOnMove sets a function that is called when elements move, as set by
+// [Heap.SetOnMove].
+func WithOnMove[T any](fn func(x T, i int)) Option[T] {
+	return func(o *options[T]) {
+		o.onMove = fn
+	}
+}
+
+// WithGrowth sets how the heap's storage grows, as set by [Heap.SetGrowth].
+func WithGrowth[T any](growth Growth) Option[T] {
+	return func(o *options[T]) {
+		o.growth = growth
+	}
+}
+
+// WithScanThreshold sets the number of elements up to which the heap's
+// elements are kept unordered, as set by [Heap.SetScanThreshold].
+func WithScanThreshold[T any](n int) Option[T] {
+	if n < 0 {
+		panic("heap: negative scan threshold")
+	}
+	return func(o *options[T]) {
+		o.scanMax = n
+	}
+}
+
+// WithLazy enables lazy ordering, as set by [Heap.SetLazy].
+func WithLazy[T any]() Option[T] {
+	return func(o *options[T]) {
+		o.lazy = true
+	}
+}
+
+// apply applies options to a new heap.
+func (h *Heap[T]) apply(opts []Option[T]) {
+	if len(opts) == 0 {
+		return
+	}
+	var o options[T]
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.capacity > cap(h.data) {
+		data := make([]T, len(h.data), o.capacity)
+		copy(data, h.data)
+		h.data = data
+	}
+	h.onMove = o.onMove
+	h.growth = o.growth
+	h.scanMax = o.scanMax
+	h.lazy = o.lazy
+	h.metrics = o.metrics
+}
@@ -0,0 +1,38 @@
+package heap_test
+
+import (
+	"cmp"
+	"testing"
+
+	"github.com/gammazero/heap"
+)
+
+func TestOptions(t *testing.T) {
+	moved := map[int]int{}
+	h := heap.New(cmp.Less[int64],
+		heap.WithCapacity[int64](100),
+		heap.WithOnMove(func(x int64, i int) { moved[int(x)] = i }),
+		heap.WithScanThreshold[int64](4),
+		heap.WithLazy[int64](),
+	)
+	if c := storageCap(h); c != 100 {
+		t.Fatalf("expected capacity 100, got %d", c)
+	}
+	for _, x := range []int64{3, 1, 2} {
+		h.Push(x)
+	}
+	if len(moved) != 3 {
+		t.Fatal("onMove not called")
+	}
+	if h.Pop() != 1 || h.Pop() != 2 || h.Pop() != 3 {
+		t.Fatal("wrong order")
+	}
+
+	h = heap.New(cmp.Less[int64], heap.WithGrowth[int64](heap.GrowBy(7)))
+	h.Push(1)
+	if c := storageCap(h); c != 7 {
+		t.Fatalf("expected capacity 7, got %d", c)
+	}
+
+	assertPanics(t, "negative capacity", func() { heap.WithCapacity[int64](-1) })
+}
@@ -0,0 +1,132 @@
+package heap
+
+import "cmp"
+
+// orderedOps holds sift functions specialized for a built-in ordered type.
+// They compare elements with the < and > operators directly, avoiding the
+// cost of calling the less function.
+type orderedOps[T any] struct {
+	up   func(data []T, i int)
+	down func(data []T, i int) bool
+}
+
+// NewOrdered returns a new min-heap of a built-in ordered type. Elements are
+// compared using the < operator directly, which is faster than calling a less
+// function. NaN values are ordered before all other floating-point values,
+// as by [cmp.Less].
+func NewOrdered[T cmp.Ordered](data ...T) *Heap[T] {
+	h := &Heap[T]{
+		less:        cmp.Less[T],
+		data:        data,
+		pointerFree: !hasPointers[T](),
+		ordered: &orderedOps[T]{
+			up:   upMin[T],
+			down: downMin[T],
+		},
+	}
+	h.heapifyNew()
+	return h
+}
+
+// NewMax returns a new max-heap of a built-in ordered type, so that Pop
+// removes the largest element. Elements are compared using the > operator
+// directly, which is faster than calling a less function. NaN values are
+// ordered after all other floating-point values.
+func NewMax[T cmp.Ordered](data ...T) *Heap[T] {
+	h := &Heap[T]{
+		less:        func(a, b T) bool { return cmp.Less(b, a) },
+		data:        data,
+		pointerFree: !hasPointers[T](),
+		ordered: &orderedOps[T]{
+			up:   upMax[T],
+			down: downMax[T],
+		},
+	}
+	h.heapifyNew()
+	return h
+}
+
+// useOrdered returns true if the specialized sift functions can be used. They
+// cannot be used when onMove must be called or less calls are being counted.
+func (h *Heap[T]) useOrdered() bool {
+	return h.ordered != nil && h.onMove == nil && h.stats == nil
+}
+
+// isNaN reports whether x is a NaN, without requiring T to be a float type.
+func isNaN[T cmp.Ordered](x T) bool {
+	return x != x
+}
+
+// lessMin is the same as cmp.Less, and is simple enough to be inlined.
+func lessMin[T cmp.Ordered](a, b T) bool {
+	return a < b || (isNaN(a) && !isNaN(b))
+}
+
+func upMin[T cmp.Ordered](data []T, i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !lessMin(data[i], data[parent]) {
+			break
+		}
+		data[i], data[parent] = data[parent], data[i]
+		i = parent
+	}
+}
+
+func downMin[T cmp.Ordered](data []T, i int) bool {
+	n := len(data)
+	i0 := i
+	for {
+		left := 2*i + 1
+		if left >= n || left < 0 { // left < 0 after int overflow
+			break
+		}
+		j := left
+		if right := left + 1; right < n && lessMin(data[right], data[left]) {
+			j = right
+		}
+		if !lessMin(data[j], data[i]) {
+			break
+		}
+		data[i], data[j] = data[j], data[i]
+		i = j
+	}
+	return i > i0
+}
+
+// lessMax orders NaN values after all other values, the reverse of lessMin.
+func lessMax[T cmp.Ordered](a, b T) bool {
+	return a > b || (isNaN(b) && !isNaN(a))
+}
+
+func upMax[T cmp.Ordered](data []T, i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !lessMax(data[i], data[parent]) {
+			break
+		}
+		data[i], data[parent] = data[parent], data[i]
+		i = parent
+	}
+}
+
+func downMax[T cmp.Ordered](data []T, i int) bool {
+	n := len(data)
+	i0 := i
+	for {
+		left := 2*i + 1
+		if left >= n || left < 0 { // left < 0 after int overflow
+			break
+		}
+		j := left
+		if right := left + 1; right < n && lessMax(data[right], data[left]) {
+			j = right
+		}
+		if !lessMax(data[j], data[i]) {
+			break
+		}
+		data[i], data[j] = data[j], data[i]
+		i = j
+	}
+	return i > i0
+}
@@ -0,0 +1,145 @@
+package heap_test
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/gammazero/heap"
+)
+
+func TestNewOrdered(t *testing.T) {
+	data := rand.Perm(1000)
+	h := heap.NewOrdered(slices.Clone(data[:500])...)
+	for _, x := range data[500:] {
+		h.Push(x)
+	}
+	removed := h.Remove(10)
+	for i := range 1000 {
+		if i == removed {
+			continue
+		}
+		if x := h.Pop(); x != i {
+			t.Fatalf("expected %d, got %d", i, x)
+		}
+	}
+
+	hm := heap.NewMax("b", "d", "a")
+	hm.Push("c")
+	var out []string
+	for hm.Len() != 0 {
+		out = append(out, hm.Pop())
+	}
+	if !slices.Equal(out, []string{"d", "c", "b", "a"}) {
+		t.Fatalf("wrong max-heap order: %v", out)
+	}
+}
+
+func TestOrderedNaN(t *testing.T) {
+	nan := math.NaN()
+	h := heap.NewOrdered(3, nan, 1)
+	h.Push(2)
+	if x := h.Pop(); !math.IsNaN(x) {
+		t.Fatalf("expected NaN first in min-heap, got %v", x)
+	}
+	if x := h.Pop(); x != 1 {
+		t.Fatalf("expected 1, got %v", x)
+	}
+
+	hm := heap.NewMax(3, nan, 1)
+	hm.Push(2)
+	var out []float64
+	for hm.Len() != 0 {
+		out = append(out, hm.Pop())
+	}
+	if !slices.Equal(out[:3], []float64{3, 2, 1}) || !math.IsNaN(out[3]) {
+		t.Fatalf("expected NaN last in max-heap, got %v", out)
+	}
+}
+
+func TestOrderedWithOnMove(t *testing.T) {
+	// The specialized path is not used when onMove is set.
+	h := heap.NewOrdered[int]()
+	pos := map[int]int{}
+	h.SetOnMove(func(x, i int) { pos[x] = i })
+	for _, x := range rand.Perm(100) {
+		h.Push(x)
+	}
+	for x, i := range pos {
+		if i >= 0 && h.At(i) != x {
+			t.Fatalf("tracked index of %d is %d", x, i)
+		}
+	}
+}
+
+func BenchmarkOrderedPushPop10k(b *testing.B) {
+	const n = 10000
+	data := rand.Perm(n)
+	b.Run("less", func(b *testing.B) {
+		h := heap.New(cmp.Less[int])
+		for b.Loop() {
+			for _, x := range data {
+				h.Push(x)
+			}
+			for h.Len() > 0 {
+				h.Pop()
+			}
+		}
+	})
+	b.Run("ordered", func(b *testing.B) {
+		h := heap.NewOrdered[int]()
+		for b.Loop() {
+			for _, x := range data {
+				h.Push(x)
+			}
+			for h.Len() > 0 {
+				h.Pop()
+			}
+		}
+	})
+}
+
+// BenchmarkTimestamps compares the less function with the specialized sift
+// functions of NewOrdered for a queue of uint64 timestamps, which are pushed
+// in roughly increasing order, as by a scheduler. The heap is binary, so
+// there is no arity to vary.
+func BenchmarkTimestamps(b *testing.B) {
+	newHeaps := []struct {
+		name string
+		new  func(data ...uint64) *heap.Heap[uint64]
+	}{
+		{"less", func(data ...uint64) *heap.Heap[uint64] { return heap.NewFrom(cmp.Less[uint64], data...) }},
+		{"ordered", heap.NewOrdered[uint64]},
+	}
+	for _, n := range []int{1000, 100000} {
+		data := make([]uint64, n)
+		now := uint64(1_700_000_000_000_000_000)
+		for i := range data {
+			data[i] = now + uint64(i)*1000 + uint64(rand.Intn(100000))
+		}
+		for _, nh := range newHeaps {
+			b.Run(fmt.Sprintf("pushpop/n=%d/%s", n, nh.name), func(b *testing.B) {
+				h := nh.new()
+				for b.Loop() {
+					for _, x := range data {
+						h.Push(x)
+					}
+					for h.Len() > 0 {
+						h.Pop()
+					}
+				}
+			})
+			b.Run(fmt.Sprintf("heapify/n=%d/%s", n, nh.name), func(b *testing.B) {
+				buf := make([]uint64, n)
+				for b.Loop() {
+					copy(buf, data)
+					slices.Reverse(buf)
+					nh.new(buf...)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package heap
+
+// PeekN returns up to n of the smallest elements in the heap, in order from
+// minimum to maximum, without removing them. If the heap holds fewer than n
+// elements, all of its elements are returned. The complexity is O(n log n),
+// independent of the size of the heap.
+func (h *Heap[T]) PeekN(n int) []T {
+	if n < 0 {
+		panic("heap: PeekN count is negative")
+	}
+	if h.guard != nil {
+		h.checkRead()
+	}
+	return h.peekN(h.heapOrder(), n)
+}
+
+// peekN returns up to n of the smallest elements of data, which is in heap
+// order.
+func (h *Heap[T]) peekN(data []T, n int) []T {
+	n = min(n, len(data))
+	if n == 0 {
+		return nil
+	}
+	// The next smallest element is always a child of an element already
+	// taken, so the candidates are kept in a heap of indexes into data.
+	out := make([]T, 0, n)
+	cand := New(func(a, b int) bool { return h.less(data[a], data[b]) })
+	cand.Push(0)
+	for len(out) < n {
+		i := cand.Pop()
+		out = append(out, data[i])
+		if left := 2*i + 1; left < len(data) {
+			cand.Push(left)
+			if left+1 < len(data) {
+				cand.Push(left + 1)
+			}
+		}
+	}
+	return out
+}
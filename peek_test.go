@@ -0,0 +1,44 @@
+package heap_test
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/gammazero/heap"
+)
+
+func TestPeekN(t *testing.T) {
+	data := rand.Perm(100)
+	h := heap.NewFrom(func(a, b int) bool { return a < b }, slices.Clone(data)...)
+
+	top := h.PeekN(10)
+	if !slices.Equal(top, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}) {
+		t.Fatalf("wrong PeekN result: %v", top)
+	}
+	if h.Len() != 100 || h.Peek() != 0 {
+		t.Fatal("PeekN modified the heap")
+	}
+	if all := h.PeekN(200); len(all) != 100 || !slices.IsSorted(all) {
+		t.Fatal("PeekN beyond Len did not return all elements in order")
+	}
+	if got := h.PeekN(0); len(got) != 0 {
+		t.Fatalf("expected no elements, got %v", got)
+	}
+	if got := heap.New(func(a, b int) bool { return a < b }).PeekN(3); len(got) != 0 {
+		t.Fatalf("expected no elements from empty heap, got %v", got)
+	}
+}
+
+func TestCap(t *testing.T) {
+	h := heap.New(func(a, b int) bool { return a < b }, heap.WithCapacity[int](16))
+	if h.Cap() != 16 {
+		t.Fatalf("expected capacity 16, got %d", h.Cap())
+	}
+	for i := range 17 {
+		h.Push(i)
+	}
+	if h.Cap() < 17 {
+		t.Fatalf("capacity %d less than length", h.Cap())
+	}
+}
@@ -0,0 +1,36 @@
+package heap
+
+import "sync"
+
+// Pool is a set of heaps that can be reused, to avoid allocating new heaps
+// and their storage when many short-lived heaps are used, such as for a top-k
+// computation in each request handled by a server. A Pool is safe for
+// concurrent use. The zero value is ready to use.
+type Pool[T any] struct {
+	p sync.Pool
+}
+
+// Get returns an empty heap with the given less function, reusing a heap from
+// the pool if there is one.
+func (p *Pool[T]) Get(less func(a, b T) bool) *Heap[T] {
+	h, ok := p.p.Get().(*Heap[T])
+	if !ok {
+		return New(less)
+	}
+	h.less = less
+	return h
+}
+
+// Put removes all elements from the heap and adds it to the pool. The heap's
+// storage is kept for reuse, and any settings, such as the function set by
+// SetOnMove, are removed. The heap must not be used after it is passed to Put.
+func (p *Pool[T]) Put(h *Heap[T]) {
+	if !h.pointerFree {
+		clear(h.data)
+	}
+	*h = Heap[T]{
+		data:        h.data[:0],
+		pointerFree: h.pointerFree,
+	}
+	p.p.Put(h)
+}
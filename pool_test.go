@@ -0,0 +1,49 @@
+package heap_test
+
+import (
+	"cmp"
+	"testing"
+
+	"github.com/gammazero/heap"
+)
+
+func TestPool(t *testing.T) {
+	var p heap.Pool[int]
+	h := p.Get(cmp.Less[int])
+	for i := range 100 {
+		h.Push(i)
+	}
+	h.SetOnMove(func(int, int) { t.Fatal("onMove called on reused heap") })
+	h.EnableStats(true)
+	p.Put(h)
+
+	// The pool may or may not return the same heap, but it is always empty
+	// and uses the new less function.
+	for range 3 {
+		h = p.Get(func(a, b int) bool { return a > b })
+		if h.Len() != 0 {
+			t.Fatalf("expected empty heap, got length %d", h.Len())
+		}
+		h.Push(1)
+		h.Push(3)
+		h.Push(2)
+		if h.Pop() != 3 {
+			t.Fatal("heap not using new less function")
+		}
+		if h.Stats() != (heap.Stats{}) {
+			t.Fatal("stats enabled on reused heap")
+		}
+		p.Put(h)
+	}
+}
+
+func BenchmarkPool(b *testing.B) {
+	var p heap.Pool[int]
+	for b.Loop() {
+		h := p.Get(cmp.Less[int])
+		for i := range 100 {
+			h.Push(i)
+		}
+		p.Put(h)
+	}
+}
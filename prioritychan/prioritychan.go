@@ -0,0 +1,86 @@
+// Package prioritychan provides a channel-like type that delivers values in
+// priority order instead of in the order they were sent.
+package prioritychan
+
+import "github.com/gammazero/heap"
+
+// PriorityChan delivers sent values to receivers highest priority first.
+// Values sent with the same priority are received in the order they were sent.
+//
+// Values are held in a heap by an internal goroutine, so Send never waits for
+// a receiver.
+type PriorityChan[T any] struct {
+	in  chan item[T]
+	out chan T
+}
+
+type item[T any] struct {
+	val  T
+	prio int
+	seq  uint64
+}
+
+// New creates a new PriorityChan.
+func New[T any]() *PriorityChan[T] {
+	c := &PriorityChan[T]{
+		in:  make(chan item[T]),
+		out: make(chan T),
+	}
+	go c.pump()
+	return c
+}
+
+// Send sends a value with the given priority. Like sending on a closed
+// channel, calling Send after Close panics.
+func (c *PriorityChan[T]) Send(x T, prio int) {
+	c.in <- item[T]{val: x, prio: prio}
+}
+
+// Recv returns the channel from which values are received. The channel is
+// closed after Close is called and all remaining values have been received.
+func (c *PriorityChan[T]) Recv() <-chan T {
+	return c.out
+}
+
+// Close indicates that no more values will be sent. Values that were already
+// sent can still be received.
+func (c *PriorityChan[T]) Close() {
+	close(c.in)
+}
+
+func (c *PriorityChan[T]) pump() {
+	defer close(c.out)
+
+	h := heap.New(func(a, b item[T]) bool {
+		if a.prio != b.prio {
+			return a.prio > b.prio
+		}
+		return a.seq < b.seq
+	})
+	var seq uint64
+	in := c.in
+	for in != nil || h.Len() != 0 {
+		if h.Len() == 0 {
+			it, ok := <-in
+			if !ok {
+				return
+			}
+			seq++
+			it.seq = seq
+			h.Push(it)
+			continue
+		}
+		select {
+		case it, ok := <-in:
+			if !ok {
+				in = nil
+				continue
+			}
+			seq++
+			it.seq = seq
+			h.Push(it)
+		case c.out <- h.Peek().val:
+			h.Pop()
+		}
+	}
+}
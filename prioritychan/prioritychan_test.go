@@ -0,0 +1,80 @@
+package prioritychan_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gammazero/heap/prioritychan"
+)
+
+func TestPriorityOrder(t *testing.T) {
+	c := prioritychan.New[string]()
+	c.Send("low", 1)
+	c.Send("high", 9)
+	c.Send("mid-1", 5)
+	c.Send("mid-2", 5)
+	c.Close()
+
+	var got []string
+	for s := range c.Recv() {
+		got = append(got, s)
+	}
+	want := []string{"high", "mid-1", "mid-2", "low"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestSelect(t *testing.T) {
+	c := prioritychan.New[int]()
+	other := make(chan int)
+
+	select {
+	case x := <-c.Recv():
+		t.Fatalf("unexpected value %d from empty channel", x)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	go func() { other <- 1 }()
+	c.Send(2, 0)
+	got := map[int]bool{}
+	for len(got) < 2 {
+		select {
+		case x := <-c.Recv():
+			got[x] = true
+		case x := <-other:
+			got[x] = true
+		}
+	}
+	c.Close()
+	if _, ok := <-c.Recv(); ok {
+		t.Fatal("expected closed channel")
+	}
+}
+
+func TestSendAfterClosePanics(t *testing.T) {
+	c := prioritychan.New[int]()
+	c.Close()
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected Send after Close to panic")
+		}
+	}()
+	c.Send(1, 1)
+}
+
+func Example() {
+	c := prioritychan.New[string]()
+	c.Send("batch job", 1)
+	c.Send("interactive request", 10)
+	c.Close()
+
+	for s := range c.Recv() {
+		fmt.Println(s)
+	}
+
+	// Output:
+	// interactive request
+	// batch job
+}
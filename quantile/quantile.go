@@ -0,0 +1,109 @@
+// Package quantile estimates quantiles of a stream of values, such as the
+// median or 99th percentile of request latencies, using a fixed amount of
+// memory.
+//
+// A uniform random sample of the values is kept using a heap: each value is
+// given a random priority, and the values with the highest priorities are
+// kept. Quantiles are estimated from the sample. With a sample of size k, the
+// rank of an estimated quantile differs from the true rank by about
+// sqrt(q*(1-q)/k) of the number of values, independent of the number of
+// values in the stream. Until more than k values are pushed, quantiles are
+// exact.
+package quantile
+
+import (
+	"math/rand/v2"
+	"slices"
+
+	"github.com/gammazero/heap"
+)
+
+// Sketch estimates quantiles of the values pushed to it. It is not safe for
+// concurrent use.
+type Sketch[T any] struct {
+	less   func(a, b T) bool
+	size   int
+	count  uint64
+	sample *heap.Heap[sampled[T]]
+	// sorted holds the sampled values in order, and is nil if a value has
+	// been pushed since the values were last sorted.
+	sorted []T
+}
+
+type sampled[T any] struct {
+	val T
+	pri uint64
+}
+
+// New returns a new Sketch that orders values by less, and keeps a sample of
+// up to size values.
+func New[T any](less func(a, b T) bool, size int) *Sketch[T] {
+	if size < 1 {
+		panic("quantile: sample size must be positive")
+	}
+	return &Sketch[T]{
+		less: less,
+		size: size,
+		sample: heap.New(func(a, b sampled[T]) bool {
+			return a.pri < b.pri
+		}, heap.WithCapacity[sampled[T]](size)),
+	}
+}
+
+// Count returns the number of values pushed.
+func (s *Sketch[T]) Count() uint64 {
+	return s.count
+}
+
+// Push adds a value to the stream.
+func (s *Sketch[T]) Push(x T) {
+	s.count++
+	e := sampled[T]{val: x, pri: rand.Uint64()}
+	if s.sample.Len() < s.size {
+		s.sample.Push(e)
+	} else if e.pri > s.sample.Peek().pri {
+		// Replace the sampled value with the lowest priority.
+		s.sample.Set(0, e)
+	} else {
+		return
+	}
+	s.sorted = nil
+}
+
+// Quantile returns an estimate of the q-quantile of the values pushed, where
+// q is between 0 and 1. For example, Quantile(0.5) estimates the median, and
+// Quantile(0.99) the 99th percentile. Quantile panics if no values have been
+// pushed.
+func (s *Sketch[T]) Quantile(q float64) T {
+	if !(q >= 0 && q <= 1) {
+		panic("quantile: q must be between 0 and 1")
+	}
+	if s.count == 0 {
+		panic("quantile: Quantile called with no values")
+	}
+	if s.sorted == nil {
+		s.sorted = s.sorted[:0]
+		for e := range s.sample.All() {
+			s.sorted = append(s.sorted, e.val)
+		}
+		slices.SortFunc(s.sorted, func(a, b T) int {
+			switch {
+			case s.less(a, b):
+				return -1
+			case s.less(b, a):
+				return 1
+			}
+			return 0
+		})
+	}
+	return s.sorted[int(q*float64(len(s.sorted)-1)+0.5)]
+}
+
+// Reset removes all values.
+func (s *Sketch[T]) Reset() {
+	for s.sample.Len() != 0 {
+		s.sample.Pop()
+	}
+	s.count = 0
+	s.sorted = nil
+}
@@ -0,0 +1,79 @@
+package quantile_test
+
+import (
+	"cmp"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gammazero/heap/quantile"
+)
+
+func TestExact(t *testing.T) {
+	s := quantile.New(cmp.Less[int], 101)
+	for _, x := range rand.Perm(101) {
+		s.Push(x)
+	}
+	for _, q := range []float64{0, 0.25, 0.5, 0.99, 1} {
+		if x := s.Quantile(q); x != int(q*100) {
+			t.Fatalf("quantile %v is %d, expected %d", q, x, int(q*100))
+		}
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	const n = 100000
+	s := quantile.New(cmp.Less[float64], 2000)
+	for _, x := range rand.Perm(n) {
+		s.Push(float64(x))
+	}
+	if s.Count() != n {
+		t.Fatalf("expected count %d, got %d", n, s.Count())
+	}
+	for _, q := range []float64{0.01, 0.1, 0.5, 0.9, 0.99} {
+		got := s.Quantile(q)
+		// Allow 5 standard deviations of rank error.
+		tol := 5 * math.Sqrt(q*(1-q)/2000) * n
+		if want := q * n; math.Abs(got-want) > tol {
+			t.Errorf("quantile %v is %v, expected %v within %v", q, got, want, tol)
+		}
+	}
+}
+
+func TestSortedCache(t *testing.T) {
+	s := quantile.New(cmp.Less[int], 10)
+	s.Push(5)
+	if s.Quantile(1) != 5 {
+		t.Fatal("wrong maximum")
+	}
+	s.Push(9)
+	if s.Quantile(1) != 9 {
+		t.Fatal("maximum not updated after Push")
+	}
+	s.Reset()
+	if s.Count() != 0 {
+		t.Fatal("Reset did not clear count")
+	}
+	s.Push(1)
+	if s.Quantile(0.5) != 1 {
+		t.Fatal("wrong median after Reset")
+	}
+}
+
+func TestPanics(t *testing.T) {
+	s := quantile.New(cmp.Less[int], 10)
+	for _, f := range []func(){
+		func() { s.Quantile(0.5) },
+		func() { s.Push(1); s.Quantile(1.5) },
+		func() { quantile.New(cmp.Less[int], 0) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected panic")
+				}
+			}()
+			f()
+		}()
+	}
+}
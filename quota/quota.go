@@ -0,0 +1,166 @@
+// Package quota provides a priority queue shared by multiple tenants, in which
+// each tenant may have at most a limited number of elements outstanding.
+//
+// Elements are popped in global priority order, regardless of tenant. When a
+// tenant is at its limit, pushing another of its elements is handled by a
+// bounded.Policy that only considers the elements of that tenant, so one
+// tenant cannot cause the elements of another to be rejected or evicted.
+//
+// Elements are kept in a global heap, and each tenant's elements are also kept
+// in a heap ordered from worst to best, so that the tenant's worst element can
+// be evicted in O(log n).
+package quota
+
+import (
+	"github.com/gammazero/heap"
+	"github.com/gammazero/heap/bounded"
+)
+
+// Queue is a priority queue with a limit on the number of elements of each
+// tenant. It is not safe for concurrent use.
+type Queue[K comparable, T any] struct {
+	h        *heap.Heap[*entry[K, T]]
+	tenants  map[K]*heap.Heap[*entry[K, T]]
+	limits   map[K]int
+	less     func(a, b T) bool
+	tenantOf func(T) K
+	limit    int
+	policy   bounded.Policy
+	onEvict  func(T)
+}
+
+type entry[K comparable, T any] struct {
+	val       T
+	tenant    K
+	pos       int
+	tenantPos int
+}
+
+// New returns a new Queue ordered by less, in which each tenant may have at
+// most limit elements. The tenant of each element is given by tenantOf. The
+// policy determines what happens when an element is pushed for a tenant that
+// is at its limit; only that tenant's elements are evicted. If onEvict is not
+// nil, it is called with each element evicted from the queue.
+func New[K comparable, T any](less func(a, b T) bool, tenantOf func(T) K, limit int, policy bounded.Policy, onEvict func(T)) *Queue[K, T] {
+	if limit < 0 {
+		panic("quota: negative limit")
+	}
+	q := &Queue[K, T]{
+		h: heap.New(func(a, b *entry[K, T]) bool {
+			return less(a.val, b.val)
+		}),
+		tenants:  make(map[K]*heap.Heap[*entry[K, T]]),
+		limits:   make(map[K]int),
+		less:     less,
+		tenantOf: tenantOf,
+		limit:    limit,
+		policy:   policy,
+		onEvict:  onEvict,
+	}
+	q.h.SetOnMove(func(e *entry[K, T], i int) {
+		e.pos = i
+	})
+	return q
+}
+
+// SetLimit sets the limit for a tenant, replacing the queue's default limit.
+// If the tenant already has more elements than the new limit, they remain in
+// the queue, and further pushes for the tenant are handled as at the limit
+// until enough are popped.
+func (q *Queue[K, T]) SetLimit(tenant K, limit int) {
+	if limit < 0 {
+		panic("quota: negative limit")
+	}
+	q.limits[tenant] = limit
+}
+
+// Limit returns the limit for a tenant.
+func (q *Queue[K, T]) Limit(tenant K) int {
+	if n, ok := q.limits[tenant]; ok {
+		return n
+	}
+	return q.limit
+}
+
+// Len returns the number of elements in the queue.
+func (q *Queue[K, T]) Len() int {
+	return q.h.Len()
+}
+
+// Count returns the number of elements of a tenant in the queue.
+func (q *Queue[K, T]) Count(tenant K) int {
+	if th, ok := q.tenants[tenant]; ok {
+		return th.Len()
+	}
+	return 0
+}
+
+// Push adds x to the queue, and returns whether x was added. If the tenant of
+// x is at its limit, the queue's policy determines whether x is added and
+// whether the tenant's worst element is evicted to make room for it. The
+// eviction callback is called only for elements evicted from the queue, not
+// for x when it is not added.
+func (q *Queue[K, T]) Push(x T) bool {
+	tenant := q.tenantOf(x)
+	limit := q.Limit(tenant)
+	th := q.tenants[tenant]
+	if limit == 0 {
+		return false
+	}
+	if th != nil && th.Len() >= limit {
+		switch q.policy {
+		case bounded.EvictWorst:
+		case bounded.KeepBest:
+			// An element equal to the worst is not better, so the element
+			// already in the queue is kept.
+			if !q.less(x, th.Peek().val) {
+				return false
+			}
+		default:
+			return false
+		}
+		e := th.Pop()
+		q.h.Remove(e.pos)
+		if q.onEvict != nil {
+			q.onEvict(e.val)
+		}
+	}
+	if th == nil {
+		th = heap.New(func(a, b *entry[K, T]) bool {
+			return q.less(b.val, a.val)
+		})
+		th.SetOnMove(func(e *entry[K, T], i int) {
+			e.tenantPos = i
+		})
+		q.tenants[tenant] = th
+	}
+	e := &entry[K, T]{val: x, tenant: tenant}
+	q.h.Push(e)
+	th.Push(e)
+	return true
+}
+
+// Peek returns the element with the highest priority without removing it. It
+// panics if the queue is empty.
+func (q *Queue[K, T]) Peek() T {
+	if q.h.Len() == 0 {
+		panic("quota: Peek called on empty queue")
+	}
+	return q.h.Peek().val
+}
+
+// Pop removes and returns the element with the highest priority, of any
+// tenant. It panics if the queue is empty.
+func (q *Queue[K, T]) Pop() T {
+	if q.h.Len() == 0 {
+		panic("quota: Pop called on empty queue")
+	}
+	e := q.h.Pop()
+	th := q.tenants[e.tenant]
+	th.Remove(e.tenantPos)
+	if th.Len() == 0 {
+		// Do not keep the heaps of tenants that have no elements.
+		delete(q.tenants, e.tenant)
+	}
+	return e.val
+}
@@ -0,0 +1,93 @@
+package quota_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/gammazero/heap/bounded"
+	"github.com/gammazero/heap/quota"
+)
+
+type job struct {
+	tenant string
+	pri    int
+}
+
+func lessJob(a, b job) bool { return a.pri < b.pri }
+
+func tenantOf(j job) string { return j.tenant }
+
+func TestReject(t *testing.T) {
+	q := quota.New(lessJob, tenantOf, 2, bounded.Reject, nil)
+	for i, j := range []job{{"a", 5}, {"a", 1}, {"a", 3}, {"b", 4}, {"b", 2}} {
+		added := q.Push(j)
+		if added != (i != 2) {
+			t.Fatalf("push %v returned %t", j, added)
+		}
+	}
+	if q.Count("a") != 2 || q.Count("b") != 2 || q.Len() != 4 {
+		t.Fatalf("wrong counts a=%d b=%d len=%d", q.Count("a"), q.Count("b"), q.Len())
+	}
+	var got []int
+	for q.Len() != 0 {
+		got = append(got, q.Pop().pri)
+	}
+	if !slices.Equal(got, []int{1, 2, 4, 5}) {
+		t.Fatalf("popped %v", got)
+	}
+	if q.Count("a") != 0 {
+		t.Fatal("expected no elements for tenant a")
+	}
+}
+
+func TestEvictWithinTenant(t *testing.T) {
+	for _, policy := range []bounded.Policy{bounded.EvictWorst, bounded.KeepBest} {
+		var evicted []job
+		q := quota.New(lessJob, tenantOf, 2, policy, func(j job) {
+			evicted = append(evicted, j)
+		})
+		// Tenant b holds the globally worst elements, but only tenant a's
+		// elements may be evicted for a.
+		for _, j := range []job{{"b", 8}, {"b", 9}, {"a", 3}, {"a", 5}} {
+			q.Push(j)
+		}
+		if !q.Push(job{"a", 1}) {
+			t.Fatalf("policy %d: push of better element failed", policy)
+		}
+		if !slices.Equal(evicted, []job{{"a", 5}}) {
+			t.Fatalf("policy %d: evicted %v", policy, evicted)
+		}
+
+		evicted = nil
+		added := q.Push(job{"a", 7})
+		if added != (policy == bounded.EvictWorst) {
+			t.Fatalf("policy %d: push of worse element returned %t", policy, added)
+		}
+		if policy == bounded.EvictWorst && !slices.Equal(evicted, []job{{"a", 3}}) {
+			t.Fatalf("policy %d: evicted %v", policy, evicted)
+		}
+		if q.Count("a") != 2 || q.Count("b") != 2 {
+			t.Fatalf("policy %d: wrong counts a=%d b=%d", policy, q.Count("a"), q.Count("b"))
+		}
+	}
+}
+
+func TestSetLimit(t *testing.T) {
+	q := quota.New(lessJob, tenantOf, 1, bounded.Reject, nil)
+	q.SetLimit("big", 3)
+	q.SetLimit("off", 0)
+	if q.Limit("big") != 3 || q.Limit("other") != 1 {
+		t.Fatal("wrong limits")
+	}
+	for i := range 5 {
+		q.Push(job{"big", i})
+		q.Push(job{"other", i})
+		q.Push(job{"off", i})
+	}
+	if q.Count("big") != 3 || q.Count("other") != 1 || q.Count("off") != 0 {
+		t.Fatalf("wrong counts big=%d other=%d off=%d", q.Count("big"), q.Count("other"), q.Count("off"))
+	}
+	if q.Peek().pri != 0 {
+		t.Fatalf("wrong first element %v", q.Peek())
+	}
+}
@@ -0,0 +1,121 @@
+// Package retryqueue provides a queue of items that are retried with
+// exponential backoff after failing, ordered by the time of their next
+// attempt.
+package retryqueue
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"github.com/gammazero/heap/timerheap"
+)
+
+// Backoff computes the delay before retrying a failed item.
+type Backoff struct {
+	// Base is the delay before the first retry. The delay doubles for each
+	// subsequent retry.
+	Base time.Duration
+	// Cap is the maximum delay. Zero means there is no maximum.
+	Cap time.Duration
+	// Jitter is the fraction, from 0 to 1, of each delay that is randomized.
+	// A jitter of 0.5 gives a delay between half and all of the computed
+	// delay. Jitter spreads out retries of items that failed together.
+	Jitter float64
+}
+
+// Delay returns the delay before the next attempt of an item that has failed
+// the given number of times.
+func (b Backoff) Delay(failures int) time.Duration {
+	if failures < 1 || b.Base <= 0 {
+		return 0
+	}
+	d := b.Base
+	for i := 1; i < failures; i++ {
+		if b.Cap > 0 && d >= b.Cap {
+			break
+		}
+		if d > (1<<63-1)/2 {
+			d = 1<<63 - 1
+			break
+		}
+		d *= 2
+	}
+	if b.Cap > 0 && d > b.Cap {
+		d = b.Cap
+	}
+	if j := min(b.Jitter, 1); j > 0 {
+		d -= time.Duration(rand.Float64() * j * float64(d))
+	}
+	return d
+}
+
+// Item is a value in the RetryQueue along with its number of failures.
+type Item[T any] struct {
+	Value    T
+	Failures int
+}
+
+// RetryQueue holds items until their next attempt is due. It is not safe for
+// concurrent use.
+type RetryQueue[T any] struct {
+	th          *timerheap.TimerHeap[*Item[T]]
+	backoff     Backoff
+	maxAttempts int
+	deadLetter  func(*Item[T])
+}
+
+// New returns a new RetryQueue. An item that fails maxAttempts times is
+// removed and passed to deadLetter, if deadLetter is not nil. If maxAttempts
+// is zero, items are retried forever.
+func New[T any](backoff Backoff, maxAttempts int, deadLetter func(*Item[T])) *RetryQueue[T] {
+	return &RetryQueue[T]{
+		th:          timerheap.New[*Item[T]](),
+		backoff:     backoff,
+		maxAttempts: maxAttempts,
+		deadLetter:  deadLetter,
+	}
+}
+
+// Len returns the number of items waiting for an attempt.
+func (q *RetryQueue[T]) Len() int {
+	return q.th.Len()
+}
+
+// Add adds a new item whose first attempt is due at readyAt.
+func (q *RetryQueue[T]) Add(x T, readyAt time.Time) {
+	q.th.Add(&Item[T]{Value: x}, readyAt)
+}
+
+// Due removes and returns the items whose next attempt is due at or before
+// now, in order of their attempt times.
+func (q *RetryQueue[T]) Due(now time.Time) []*Item[T] {
+	return q.th.PopAllDue(now)
+}
+
+// Fail records a failed attempt of an item that was returned by Due. The item
+// is re-added with its next attempt delayed according to the backoff, or is
+// passed to the dead-letter function if it has used all of its attempts. Fail
+// returns false if the item was dead-lettered.
+func (q *RetryQueue[T]) Fail(item *Item[T], now time.Time) bool {
+	item.Failures++
+	if q.maxAttempts > 0 && item.Failures >= q.maxAttempts {
+		if q.deadLetter != nil {
+			q.deadLetter(item)
+		}
+		return false
+	}
+	q.th.Add(item, now.Add(q.backoff.Delay(item.Failures)))
+	return true
+}
+
+// NextAttempt returns the time that the next attempt is due. The second return
+// value is false if the queue is empty.
+func (q *RetryQueue[T]) NextAttempt() (time.Time, bool) {
+	return q.th.NextDeadline()
+}
+
+// C returns a channel that receives the current time when the next attempt is
+// due. See [timerheap.TimerHeap.C].
+func (q *RetryQueue[T]) C() <-chan time.Time {
+	return q.th.C()
+}
@@ -0,0 +1,89 @@
+package retryqueue_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gammazero/heap/retryqueue"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	b := retryqueue.Backoff{
+		Base: 100 * time.Millisecond,
+		Cap:  time.Second,
+	}
+	want := []time.Duration{
+		0,
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+	}
+	for failures, d := range want {
+		if got := b.Delay(failures); got != d {
+			t.Errorf("Delay(%d) = %s, want %s", failures, got, d)
+		}
+	}
+
+	b.Cap = 0
+	if d := b.Delay(100); d <= 0 {
+		t.Fatalf("uncapped delay overflowed: %s", d)
+	}
+
+	b = retryqueue.Backoff{Base: time.Second, Jitter: 0.5}
+	for range 100 {
+		d := b.Delay(1)
+		if d < 500*time.Millisecond || d > time.Second {
+			t.Fatalf("jittered delay %s out of range", d)
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+	var dead []*retryqueue.Item[string]
+	q := retryqueue.New(retryqueue.Backoff{Base: time.Second}, 3,
+		func(item *retryqueue.Item[string]) {
+			dead = append(dead, item)
+		})
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	q.Add("job", now)
+	q.Add("later", now.Add(time.Minute))
+
+	items := q.Due(now)
+	if len(items) != 1 || items[0].Value != "job" {
+		t.Fatalf("unexpected due items %v", items)
+	}
+	if !q.Fail(items[0], now) {
+		t.Fatal("item should be retried")
+	}
+	next, _ := q.NextAttempt()
+	if !next.Equal(now.Add(time.Second)) {
+		t.Fatalf("unexpected next attempt %v", next)
+	}
+
+	now = next
+	items = q.Due(now)
+	if len(items) != 1 || items[0].Failures != 1 {
+		t.Fatalf("unexpected due items %v", items)
+	}
+	q.Fail(items[0], now)
+	next, _ = q.NextAttempt()
+	if !next.Equal(now.Add(2 * time.Second)) {
+		t.Fatalf("unexpected next attempt %v", next)
+	}
+
+	now = next
+	items = q.Due(now)
+	if q.Fail(items[0], now) {
+		t.Fatal("item should be dead-lettered")
+	}
+	if len(dead) != 1 || dead[0].Value != "job" || dead[0].Failures != 3 {
+		t.Fatalf("unexpected dead letters %v", dead)
+	}
+	if q.Len() != 1 {
+		t.Fatalf("expected 1 item, got %d", q.Len())
+	}
+}
@@ -0,0 +1,72 @@
+package sample
+
+import (
+	"math/rand/v2"
+
+	"github.com/gammazero/heap"
+)
+
+// Reservoir keeps a uniform random sample of up to k values from a stream of
+// any length: after n values have been offered, each of them is in the sample
+// with probability k/n. Each value is given a random key, and the k values
+// with the largest keys are kept in a heap. It is not safe for concurrent use.
+type Reservoir[T any] struct {
+	h     *heap.Heap[keyed[T]]
+	k     int
+	count uint64
+}
+
+// NewReservoir returns a new Reservoir that keeps up to k values.
+func NewReservoir[T any](k int) *Reservoir[T] {
+	if k < 1 {
+		panic("sample: reservoir size must be positive")
+	}
+	return &Reservoir[T]{
+		h: heap.New(lessKey[T], heap.WithCapacity[keyed[T]](k)),
+		k: k,
+	}
+}
+
+// Offer offers a value from the stream to the sample. It returns true if the
+// value was added to the sample, which may have evicted another value.
+func (r *Reservoir[T]) Offer(x T) bool {
+	r.count++
+	e := keyed[T]{val: x, key: rand.Float64()}
+	if r.h.Len() < r.k {
+		r.h.Push(e)
+		return true
+	}
+	if e.key <= r.h.Peek().key {
+		return false
+	}
+	r.h.Set(0, e)
+	return true
+}
+
+// Items returns the values in the sample, in no particular order.
+func (r *Reservoir[T]) Items() []T {
+	items := make([]T, 0, r.h.Len())
+	for e := range r.h.All() {
+		items = append(items, e.val)
+	}
+	return items
+}
+
+// Len returns the number of values in the sample, which is the lesser of k
+// and the number of values offered.
+func (r *Reservoir[T]) Len() int {
+	return r.h.Len()
+}
+
+// Count returns the number of values offered.
+func (r *Reservoir[T]) Count() uint64 {
+	return r.count
+}
+
+// Reset removes all values from the sample and resets the count.
+func (r *Reservoir[T]) Reset() {
+	for r.h.Len() != 0 {
+		r.h.Pop()
+	}
+	r.count = 0
+}
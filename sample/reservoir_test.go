@@ -0,0 +1,60 @@
+package sample_test
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/gammazero/heap/sample"
+)
+
+func TestReservoir(t *testing.T) {
+	r := sample.NewReservoir[int](10)
+	for i := range 5 {
+		if !r.Offer(i) {
+			t.Fatal("value not added to sample that is not full")
+		}
+	}
+	items := r.Items()
+	slices.Sort(items)
+	if !slices.Equal(items, []int{0, 1, 2, 3, 4}) {
+		t.Fatalf("expected all values, got %v", items)
+	}
+	for i := 5; i < 1000; i++ {
+		r.Offer(i)
+	}
+	if r.Len() != 10 || r.Count() != 1000 {
+		t.Fatalf("wrong length %d or count %d", r.Len(), r.Count())
+	}
+	items = r.Items()
+	slices.Sort(items)
+	if len(slices.Compact(items)) != 10 {
+		t.Fatal("sample has duplicate values")
+	}
+	r.Reset()
+	if r.Len() != 0 || r.Count() != 0 || len(r.Items()) != 0 {
+		t.Fatal("Reset did not empty reservoir")
+	}
+}
+
+func TestReservoirUniform(t *testing.T) {
+	// Each of n values is in a sample of size k with probability k/n.
+	const n, k, trials = 20, 5, 20000
+	counts := make([]int, n)
+	for range trials {
+		r := sample.NewReservoir[int](k)
+		for i := range n {
+			r.Offer(i)
+		}
+		for _, x := range r.Items() {
+			counts[x]++
+		}
+	}
+	p := float64(k) / n
+	tol := 5 * math.Sqrt(trials*p*(1-p))
+	for i, c := range counts {
+		if math.Abs(float64(c)-p*trials) > tol {
+			t.Errorf("value %d sampled %d times, expected %v within %v", i, c, p*trials, tol)
+		}
+	}
+}
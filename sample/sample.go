@@ -0,0 +1,97 @@
+// Package sample selects random samples from streams of values, keeping the
+// sample in a heap so that a stream of any length can be sampled with memory
+// for only the sample. WeightedSample selects values with probability
+// proportional to their weights, and Reservoir keeps a uniform sample.
+package sample
+
+import (
+	"iter"
+	"math"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/gammazero/heap"
+)
+
+type keyed[T any] struct {
+	val T
+	key float64
+}
+
+func lessKey[T any](a, b keyed[T]) bool {
+	return a.key < b.key
+}
+
+// WeightedSample returns a weighted random sample of up to k values from seq,
+// without replacement, where the probability that a value is selected is
+// proportional to its weight. Values with weights that are not positive are
+// never selected. If seq has k or fewer values with positive weights, they
+// are all returned.
+//
+// The values are returned in the order they would be selected by drawing one
+// value at a time, so the first is selected with probability proportional to
+// its weight among all values, and a prefix of the sample is itself a
+// weighted sample.
+//
+// The A-ExpJ algorithm of Efraimidis and Spirakis is used. Each value is
+// given the key u^(1/w), for a random u in (0, 1) and weight w, and the values
+// with the k largest keys are kept in a heap. Rather than generating a key for
+// every value, the algorithm computes how much weight to skip before the next
+// value that enters the sample, so it generates O(k log(n/k)) random numbers
+// for n values.
+func WeightedSample[T any](k int, seq iter.Seq2[T, float64]) []T {
+	if k < 0 {
+		panic("sample: negative sample size")
+	}
+	if k == 0 {
+		return nil
+	}
+	// Keys are stored as log(u)/w, which orders values the same as u^(1/w)
+	// and does not underflow for large weights.
+	h := heap.New(lessKey[T], heap.WithCapacity[keyed[T]](k))
+	var skip float64 // weight to skip before the next value enters the sample
+	for x, w := range seq {
+		if !(w > 0) {
+			continue
+		}
+		if h.Len() < k {
+			h.Push(keyed[T]{val: x, key: math.Log(randOpen()) / w})
+			if h.Len() == k {
+				skip = math.Log(randOpen()) / h.Peek().key
+			}
+			continue
+		}
+		if skip -= w; skip > 0 {
+			continue
+		}
+		// The value enters the sample, with a key that is greater than the
+		// smallest key in the sample.
+		minKey := h.Peek().key
+		t := math.Exp(minKey * w)
+		r := t + (1-t)*randOpen()
+		h.Set(0, keyed[T]{val: x, key: math.Log(r) / w})
+		skip = math.Log(randOpen()) / h.Peek().key
+	}
+	return sortedValues(h)
+}
+
+// sortedValues returns the values in the heap in order from largest to
+// smallest key.
+func sortedValues[T any](h *heap.Heap[keyed[T]]) []T {
+	sorted := h.PopAllSorted()
+	slices.Reverse(sorted)
+	vals := make([]T, len(sorted))
+	for i, e := range sorted {
+		vals[i] = e.val
+	}
+	return vals
+}
+
+// randOpen returns a random number in the open interval (0, 1).
+func randOpen() float64 {
+	for {
+		if u := rand.Float64(); u != 0 {
+			return u
+		}
+	}
+}
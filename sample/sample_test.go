@@ -0,0 +1,89 @@
+package sample_test
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/gammazero/heap/sample"
+)
+
+func weighted(weights []float64) func(yield func(int, float64) bool) {
+	return func(yield func(int, float64) bool) {
+		for i, w := range weights {
+			if !yield(i, w) {
+				return
+			}
+		}
+	}
+}
+
+func TestWeightedSampleSmall(t *testing.T) {
+	got := sample.WeightedSample(5, weighted([]float64{1, 0, 2, -1, math.NaN()}))
+	slices.Sort(got)
+	if !slices.Equal(got, []int{0, 2}) {
+		t.Fatalf("expected values with positive weights, got %v", got)
+	}
+	if got = sample.WeightedSample(0, weighted([]float64{1})); got != nil {
+		t.Fatalf("expected empty sample, got %v", got)
+	}
+}
+
+func TestWeightedSampleDistinct(t *testing.T) {
+	weights := make([]float64, 1000)
+	for i := range weights {
+		weights[i] = float64(i%10 + 1)
+	}
+	got := sample.WeightedSample(50, weighted(weights))
+	if len(got) != 50 {
+		t.Fatalf("expected 50 values, got %d", len(got))
+	}
+	slices.Sort(got)
+	if len(slices.Compact(got)) != 50 {
+		t.Fatal("sample has duplicate values")
+	}
+}
+
+func TestWeightedSampleFrequency(t *testing.T) {
+	// With k = 1, each value is selected with probability proportional to
+	// its weight.
+	weights := []float64{1, 2, 3, 4, 10}
+	const trials = 50000
+	counts := make([]int, len(weights))
+	for range trials {
+		counts[sample.WeightedSample(1, weighted(weights))[0]]++
+	}
+	for i, w := range weights {
+		p := w / 20
+		want := p * trials
+		tol := 5 * math.Sqrt(trials*p*(1-p))
+		if math.Abs(float64(counts[i])-want) > tol {
+			t.Errorf("value %d selected %d times, expected %v within %v", i, counts[i], want, tol)
+		}
+	}
+}
+
+func TestWeightedSampleLongStream(t *testing.T) {
+	// The skipping of A-ExpJ must keep selecting values with probability
+	// proportional to weight over a long stream. Values 0-9 have weight 100
+	// and the rest have weight 1, so about half the total weight is in the
+	// first 10 values.
+	weights := make([]float64, 1010)
+	for i := range weights {
+		weights[i] = 1
+		if i < 10 {
+			weights[i] = 100
+		}
+	}
+	const trials = 20000
+	var heavy int
+	for range trials {
+		if sample.WeightedSample(1, weighted(weights))[0] < 10 {
+			heavy++
+		}
+	}
+	p := 1000.0 / 2000
+	if math.Abs(float64(heavy)-p*trials) > 5*math.Sqrt(trials*p*(1-p)) {
+		t.Fatalf("heavy values selected %d times, expected about %v", heavy, p*trials)
+	}
+}
@@ -0,0 +1,76 @@
+package heap
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// Shape describes the size and shape of a heap, for monitoring the health of
+// long-lived heaps. For example, a capacity that stays far above the number of
+// elements can indicate that a heap once held many more elements than it
+// does now, and a growing number of tombstones can indicate that removed
+// elements are not being discarded.
+type Shape struct {
+	// Len is the number of elements in the heap.
+	Len int
+	// Cap is the number of elements the heap's storage can hold.
+	Cap int
+	// Depth is the number of levels in the heap's tree.
+	Depth int
+	// LastLevelFill is the fraction of the last level of the tree that holds
+	// elements, from greater than 0 to 1, or 0 if the heap is empty.
+	LastLevelFill float64
+	// Tombstones is the number of elements in the heap that have been
+	// removed logically, but are not discarded until they reach the top of
+	// the heap. It is always 0 for a Heap, and is set by types built on a
+	// heap that remove elements lazily.
+	Tombstones int
+}
+
+// Shape returns the size and shape of the heap.
+func (h *Heap[T]) Shape() Shape {
+	if h.guard != nil {
+		h.checkRead()
+	}
+	n := len(h.data)
+	s := Shape{
+		Len:   n,
+		Cap:   cap(h.data),
+		Depth: bits.Len(uint(n)),
+	}
+	if n != 0 {
+		// The last level starts at index 2^(Depth-1) - 1 and can hold
+		// 2^(Depth-1) elements.
+		width := 1 << (s.Depth - 1)
+		s.LastLevelFill = float64(n-width+1) / float64(width)
+	}
+	return s
+}
+
+// FillRatio returns the fraction of the capacity that holds elements, or 1 if
+// the capacity is 0.
+func (s Shape) FillRatio() float64 {
+	if s.Cap == 0 {
+		return 1
+	}
+	return float64(s.Len) / float64(s.Cap)
+}
+
+// TombstoneRatio returns the fraction of the elements that are tombstones, or
+// 0 if there are no elements.
+func (s Shape) TombstoneRatio() float64 {
+	if s.Len == 0 {
+		return 0
+	}
+	return float64(s.Tombstones) / float64(s.Len)
+}
+
+// String returns a one-line description of the shape, for logging.
+func (s Shape) String() string {
+	str := fmt.Sprintf("len=%d cap=%d fill=%.2f depth=%d last-level=%.2f",
+		s.Len, s.Cap, s.FillRatio(), s.Depth, s.LastLevelFill)
+	if s.Tombstones != 0 {
+		str += fmt.Sprintf(" tombstones=%d (%.2f)", s.Tombstones, s.TombstoneRatio())
+	}
+	return str
+}
@@ -0,0 +1,44 @@
+package heap_test
+
+import (
+	"testing"
+
+	"github.com/gammazero/heap"
+)
+
+func TestShape(t *testing.T) {
+	h := heap.New(func(a, b int) bool { return a < b })
+	if s := h.Shape(); s != (heap.Shape{}) {
+		t.Fatalf("wrong shape of empty heap: %+v", s)
+	}
+	tests := []struct {
+		n     int
+		depth int
+		fill  float64
+	}{
+		{1, 1, 1},
+		{2, 2, 0.5},
+		{3, 2, 1},
+		{4, 3, 0.25},
+		{7, 3, 1},
+		{12, 4, 0.625},
+	}
+	for _, tt := range tests {
+		for h.Len() < tt.n {
+			h.Push(h.Len())
+		}
+		s := h.Shape()
+		if s.Len != tt.n || s.Cap != h.Cap() || s.Depth != tt.depth || s.LastLevelFill != tt.fill {
+			t.Errorf("n=%d: wrong shape %+v", tt.n, s)
+		}
+	}
+
+	s := heap.Shape{Len: 8, Cap: 16, Depth: 4, LastLevelFill: 0.125, Tombstones: 2}
+	if s.FillRatio() != 0.5 || s.TombstoneRatio() != 0.25 {
+		t.Fatalf("wrong ratios %v and %v", s.FillRatio(), s.TombstoneRatio())
+	}
+	const want = "len=8 cap=16 fill=0.50 depth=4 last-level=0.12 tombstones=2 (0.25)"
+	if s.String() != want {
+		t.Fatalf("wrong description %q", s.String())
+	}
+}
@@ -0,0 +1,124 @@
+// Package sim provides a discrete-event simulation engine with a virtual
+// clock.
+//
+// Events are functions scheduled to run at a virtual time. Running the
+// simulation repeatedly takes the earliest event from a heap, advances the
+// virtual clock to that event's time, and calls its function, which may
+// schedule more events.
+package sim
+
+import (
+	"time"
+
+	"github.com/gammazero/heap"
+)
+
+// Sim is a discrete-event simulation. It is not safe for concurrent use.
+type Sim struct {
+	now time.Time
+	h   *heap.Heap[*Event]
+	seq uint64
+}
+
+// Event is a scheduled event.
+type Event struct {
+	s   *Sim
+	at  time.Time
+	fn  func()
+	seq uint64
+	pos int
+}
+
+// New returns a new simulation whose virtual clock starts at start.
+func New(start time.Time) *Sim {
+	s := &Sim{
+		now: start,
+		h: heap.New(func(a, b *Event) bool {
+			if !a.at.Equal(b.at) {
+				return a.at.Before(b.at)
+			}
+			return a.seq < b.seq
+		}),
+	}
+	s.h.SetOnMove(func(e *Event, i int) {
+		e.pos = i
+	})
+	return s
+}
+
+// Now returns the current virtual time.
+func (s *Sim) Now() time.Time {
+	return s.now
+}
+
+// Len returns the number of scheduled events.
+func (s *Sim) Len() int {
+	return s.h.Len()
+}
+
+// Schedule schedules fn to run at the given virtual time. A time earlier than
+// the current virtual time is treated as the current time. Events scheduled
+// for the same time run in the order they were scheduled.
+func (s *Sim) Schedule(at time.Time, fn func()) *Event {
+	if at.Before(s.now) {
+		at = s.now
+	}
+	s.seq++
+	e := &Event{
+		s:   s,
+		at:  at,
+		fn:  fn,
+		seq: s.seq,
+	}
+	s.h.Push(e)
+	return e
+}
+
+// After schedules fn to run after the virtual duration d.
+func (s *Sim) After(d time.Duration, fn func()) *Event {
+	return s.Schedule(s.now.Add(d), fn)
+}
+
+// Step runs the next event, advancing the virtual clock to its time. It
+// returns false if there are no events.
+func (s *Sim) Step() bool {
+	if s.h.Len() == 0 {
+		return false
+	}
+	e := s.h.Pop()
+	s.now = e.at
+	e.fn()
+	return true
+}
+
+// Run runs events until there are none left.
+func (s *Sim) Run() {
+	for s.Step() {
+	}
+}
+
+// RunUntil runs all events scheduled at or before t, and then advances the
+// virtual clock to t.
+func (s *Sim) RunUntil(t time.Time) {
+	for s.h.Len() != 0 && !s.h.Peek().at.After(t) {
+		s.Step()
+	}
+	if t.After(s.now) {
+		s.now = t
+	}
+}
+
+// Time returns the virtual time that the event is scheduled for.
+func (e *Event) Time() time.Time {
+	return e.at
+}
+
+// Cancel removes the event so that it does not run. It returns false if the
+// event has already run or was already canceled.
+func (e *Event) Cancel() bool {
+	if e.pos < 0 {
+		return false
+	}
+	e.s.h.Remove(e.pos)
+	return true
+}
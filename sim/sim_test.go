@@ -0,0 +1,86 @@
+package sim_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gammazero/heap/sim"
+)
+
+var start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestRun(t *testing.T) {
+	s := sim.New(start)
+	var log []string
+	record := func(name string) func() {
+		return func() {
+			log = append(log, fmt.Sprintf("%s@%s", name, s.Now().Sub(start)))
+		}
+	}
+
+	s.After(3*time.Second, record("c"))
+	s.After(time.Second, func() {
+		record("a")()
+		s.After(time.Second, record("b"))
+	})
+	s.After(time.Second, record("a2"))
+	s.Run()
+
+	want := "[a@1s a2@1s b@2s c@3s]"
+	if fmt.Sprint(log) != want {
+		t.Fatalf("expected %s, got %v", want, log)
+	}
+	if s.Step() {
+		t.Fatal("expected no more events")
+	}
+}
+
+func TestCancel(t *testing.T) {
+	s := sim.New(start)
+	ran := map[int]bool{}
+	events := make([]*sim.Event, 5)
+	for i := range events {
+		events[i] = s.After(time.Duration(i)*time.Second, func() { ran[i] = true })
+	}
+	if !events[3].Cancel() {
+		t.Fatal("expected cancel to succeed")
+	}
+	if events[3].Cancel() {
+		t.Fatal("should not cancel twice")
+	}
+	s.Step()
+	if events[0].Cancel() {
+		t.Fatal("should not cancel event that ran")
+	}
+	if s.Len() != 3 {
+		t.Fatalf("expected 3 events, got %d", s.Len())
+	}
+	s.Run()
+	if ran[3] || len(ran) != 4 {
+		t.Fatalf("unexpected events ran: %v", ran)
+	}
+}
+
+func TestRunUntil(t *testing.T) {
+	s := sim.New(start)
+	var count int
+	var tick func()
+	tick = func() {
+		count++
+		s.After(time.Minute, tick)
+	}
+	s.Schedule(start.Add(-time.Hour), tick)
+
+	s.RunUntil(start.Add(10 * time.Minute))
+	if count != 11 {
+		t.Fatalf("expected 11 ticks, got %d", count)
+	}
+	if !s.Now().Equal(start.Add(10 * time.Minute)) {
+		t.Fatalf("unexpected time %v", s.Now())
+	}
+	s.RunUntil(start.Add(10*time.Minute + 30*time.Second))
+	if count != 11 || s.Now().Sub(start) != 10*time.Minute+30*time.Second {
+		t.Fatalf("unexpected state after partial advance: %d %v", count, s.Now())
+	}
+}
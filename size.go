@@ -0,0 +1,26 @@
+package heap
+
+import "unsafe"
+
+// SizeOf returns the approximate number of bytes of memory held by the heap.
+// This is the size of the Heap itself plus the size of its storage, which
+// holds Cap elements of type T, whether or not they are in use.
+//
+// If elemSize is not nil, it is called for each element in the heap and
+// returns the number of bytes that the element refers to outside of the heap's
+// storage, such as the contents of a string or of a pointed-to struct. These
+// are added to the total. Memory shared by multiple elements is counted once
+// for each element that refers to it.
+func (h *Heap[T]) SizeOf(elemSize func(T) int) int64 {
+	if h.guard != nil {
+		h.checkRead()
+	}
+	var zero T
+	size := int64(unsafe.Sizeof(*h)) + int64(cap(h.data))*int64(unsafe.Sizeof(zero))
+	if elemSize != nil {
+		for _, x := range h.data {
+			size += int64(elemSize(x))
+		}
+	}
+	return size
+}
@@ -0,0 +1,35 @@
+package heap_test
+
+import (
+	"cmp"
+	"testing"
+
+	"github.com/gammazero/heap"
+)
+
+func TestSizeOf(t *testing.T) {
+	h := heap.New(cmp.Less[int64])
+	empty := h.SizeOf(nil)
+	if empty <= 0 {
+		t.Fatalf("expected positive size, got %d", empty)
+	}
+	for i := range 100 {
+		h.Push(int64(i))
+	}
+	// The storage holds at least 100 8-byte elements.
+	if size := h.SizeOf(nil); size < empty+100*8 {
+		t.Fatalf("size %d too small", size)
+	}
+
+	hs := heap.New(cmp.Less[string])
+	base := hs.SizeOf(nil)
+	hs.Push("hello")
+	hs.Push("world!")
+	withElems := hs.SizeOf(func(s string) int { return len(s) })
+	if withElems != hs.SizeOf(nil)+11 {
+		t.Fatalf("expected element sizes to be added, got %d", withElems)
+	}
+	if hs.SizeOf(nil) <= base {
+		t.Fatal("expected size to grow after push")
+	}
+}
@@ -0,0 +1,121 @@
+package heap
+
+import "slices"
+
+// SetScanThreshold sets the number of elements up to which the heap keeps its
+// elements unordered, and finds the minimum element by scanning all of the
+// elements. For a heap that holds only a few elements, this is faster than
+// maintaining the heap ordering. When the heap grows beyond n elements, the
+// heap ordering is established. A threshold of 0, the default, always
+// maintains the heap ordering.
+//
+// Methods that modify the heap using its layout, such as Remove and Fix,
+// establish the heap ordering before they run. Methods that only read the
+// heap, such as Peek, At, and Export, never modify it: Peek scans the
+// elements, and methods that use the layout of the heap put a copy of the
+// elements in heap order. The scan threshold does not change their results.
+//
+// The heap ordering is established by the Push that takes the heap beyond
+// the threshold, or by SetScanThreshold if the heap already holds more than n
+// elements, so that reading a larger heap does not need to scan or copy it.
+func (h *Heap[T]) SetScanThreshold(n int) {
+	if n < 0 {
+		panic("heap: negative scan threshold")
+	}
+	h.scanMax = n
+	if !h.lazy && len(h.data) > n {
+		h.ensureOrdered()
+	}
+}
+
+// ensureOrdered establishes the heap ordering if the heap's elements are
+// unordered.
+func (h *Heap[T]) ensureOrdered() {
+	if !h.unordered {
+		return
+	}
+	if h.guard != nil {
+		h.startWrite()
+	}
+	defer h.endOp()
+	h.heapify()
+}
+
+// heapOrder returns the heap's elements in heap order without modifying the
+// heap. If the elements are unordered, a copy is put in heap order, in the
+// same layout that establishing the heap ordering gives the elements.
+func (h *Heap[T]) heapOrder() []T {
+	if !h.unordered {
+		return h.data
+	}
+	c := &Heap[T]{data: slices.Clone(h.data), less: h.less}
+	c.heapify()
+	return c.data
+}
+
+// minIndex returns the index of the minimum element of unordered data.
+func (h *Heap[T]) minIndex() int {
+	h.mustHaveLess()
+	m := 0
+	for i := 1; i < len(h.data); i++ {
+		if h.less(h.data[i], h.data[m]) {
+			m = i
+		}
+	}
+	return m
+}
+
+// pushUnordered appends an element without maintaining the heap ordering.
+func (h *Heap[T]) pushUnordered(x T) {
+	h.unordered = len(h.data) != 0
+	h.data = append(h.data, x)
+	if h.onMove != nil {
+		h.onMove(x, len(h.data)-1)
+	}
+}
+
+// popScan removes and returns the minimum element of unordered data.
+func (h *Heap[T]) popScan() T {
+	if h.stats != nil {
+		h.stats.Pops++
+	}
+	h.modify()
+	m := h.minIndex()
+	x := h.data[m]
+	n := len(h.data) - 1
+	if m != n {
+		h.data[m] = h.data[n]
+		if h.onMove != nil {
+			h.onMove(h.data[m], m)
+		}
+	}
+	h.clearSlot(n)
+	h.data = h.data[:n]
+	if h.onMove != nil {
+		h.onMove(x, -1)
+	}
+	if n <= 1 {
+		h.unordered = false
+	}
+	h.watermarksChanged()
+	return x
+}
+
+// SetLazy enables or disables lazy ordering. When lazy ordering is enabled,
+// elements pushed onto an empty heap, and onto a heap that has only had
+// elements pushed since it was empty, are appended without establishing the
+// heap ordering. The ordering is established, in O(n) time, by the next
+// method that modifies the heap other than Push, such as Pop. This is faster
+// than sifting each element into place when a heap is filled before elements
+// are removed from it.
+//
+// Methods that only read the heap do not establish the ordering. Until it is
+// established, Peek scans the elements in O(n) time, and methods that use the
+// layout of the heap, such as At and Export, put a copy of the elements in
+// heap order. Disabling lazy ordering establishes the ordering.
+func (h *Heap[T]) SetLazy(lazy bool) {
+	h.lazy = lazy
+	if !lazy && len(h.data) > h.scanMax {
+		h.ensureOrdered()
+	}
+}
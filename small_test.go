@@ -0,0 +1,216 @@
+package heap_test
+
+import (
+	"cmp"
+	"math/bits"
+	"math/rand"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/gammazero/heap"
+)
+
+func TestScanThreshold(t *testing.T) {
+	for _, n := range []int{3, 8, 20} {
+		h := heap.New(cmp.Less[int])
+		h.SetScanThreshold(8)
+		pos := map[int]int{}
+		h.SetOnMove(func(x, i int) {
+			if i < 0 {
+				delete(pos, x)
+			} else {
+				pos[x] = i
+			}
+		})
+		data := rand.Perm(n)
+		for _, x := range data {
+			h.Push(x)
+		}
+		// Fix establishes the heap ordering, which moves elements. Reading
+		// the heap does not.
+		h.Fix(0)
+		for x, i := range pos {
+			if h.At(i) != x {
+				t.Fatalf("tracked index of %d is %d, but At(%d) is %d", x, i, i, h.At(i))
+			}
+		}
+		for i := range n {
+			if h.Peek() != i {
+				t.Fatalf("expected Peek %d, got %d", i, h.Peek())
+			}
+			if x := h.Pop(); x != i {
+				t.Fatalf("expected %d, got %d", i, x)
+			}
+			// Push an element back to mix pushes with pops.
+			if i == 1 {
+				h.Push(n + 1)
+			}
+		}
+		if h.Pop() != n+1 || h.Len() != 0 || len(pos) != 0 {
+			t.Fatal("wrong final state")
+		}
+	}
+}
+
+func TestScanThresholdLayout(t *testing.T) {
+	// Methods that use the heap layout see a valid heap.
+	h := heap.New(cmp.Less[int])
+	h.SetScanThreshold(16)
+	for _, x := range []int{5, 3, 9, 1, 7} {
+		h.Push(x)
+	}
+	if h.At(0) != 1 {
+		t.Fatalf("expected At(0) to be the minimum, got %d", h.At(0))
+	}
+	h.Push(0)
+	layout := h.Export()
+	for i := 1; i < len(layout); i++ {
+		if layout[i] < layout[(i-1)/2] {
+			t.Fatalf("exported data not in heap order: %v", layout)
+		}
+	}
+	h.Push(4)
+	if x := h.Remove(0); x != 0 {
+		t.Fatalf("expected Remove(0) to remove the minimum, got %d", x)
+	}
+	var out []int
+	for h.Len() != 0 {
+		out = append(out, h.Pop())
+	}
+	if !slices.Equal(out, []int{1, 3, 4, 5, 7, 9}) {
+		t.Fatalf("wrong order: %v", out)
+	}
+	assertPanics(t, "negative threshold", func() { h.SetScanThreshold(-1) })
+}
+
+func TestReadUnordered(t *testing.T) {
+	// Reading a heap whose elements are unordered does not order them, so it
+	// can be read during iteration and by concurrent readers.
+	opts := []heap.Option[int]{heap.WithScanThreshold[int](16), heap.WithLazy[int]()}
+	for _, opt := range opts {
+		h := heap.New(cmp.Less[int], opt)
+		for _, x := range rand.Perm(10) {
+			h.Push(x)
+		}
+		var wg sync.WaitGroup
+		for range 4 {
+			wg.Go(func() {
+				h.Peek()
+				h.At(h.Len() - 1)
+				h.PeekN(3)
+				h.Export()
+			})
+		}
+		wg.Wait()
+		for range h.All() {
+			if h.Peek() != 0 || h.At(0) != 0 || h.Max() != 9 {
+				t.Fatal("wrong minimum or maximum during iteration")
+			}
+		}
+		layout := h.Export()
+		for i := range layout {
+			if h.At(i) != layout[i] {
+				t.Fatalf("At(%d) is %d, but exported layout has %d", i, h.At(i), layout[i])
+			}
+		}
+	}
+}
+
+func BenchmarkSmallHeap(b *testing.B) {
+	data := rand.Perm(8)
+	for _, threshold := range []int{0, 16} {
+		name := "heap"
+		if threshold != 0 {
+			name = "scan"
+		}
+		b.Run(name, func(b *testing.B) {
+			h := heap.New(cmp.Less[int])
+			h.SetScanThreshold(threshold)
+			for b.Loop() {
+				for _, x := range data {
+					h.Push(x)
+				}
+				for h.Len() != 0 {
+					h.Pop()
+				}
+			}
+		})
+	}
+}
+
+func TestLazy(t *testing.T) {
+	var compares int
+	h := heap.New(func(a, b int) bool {
+		compares++
+		return a < b
+	})
+	h.SetLazy(true)
+	data := rand.Perm(1000)
+	for _, x := range data {
+		h.Push(x)
+	}
+	if compares != 0 {
+		t.Fatalf("expected no comparisons while building, got %d", compares)
+	}
+	if h.Peek() != 0 {
+		t.Fatalf("expected Peek 0, got %d", h.Peek())
+	}
+	// Peek scans the elements without ordering them.
+	if compares != len(data)-1 {
+		t.Fatalf("expected %d comparisons for scan, got %d", len(data)-1, compares)
+	}
+
+	// Pop establishes the ordering, which takes fewer than 2n comparisons,
+	// and pushes then maintain it.
+	compares = 0
+	if x := h.Pop(); x != 0 {
+		t.Fatalf("expected 0, got %d", x)
+	}
+	if compares >= 2*len(data)+2*bits.Len(uint(len(data))) {
+		t.Fatalf("too many comparisons for heapify: %d", compares)
+	}
+	h.Push(0)
+	h.Push(-1)
+	for i := -1; i < len(data); i++ {
+		if x := h.Pop(); x != i {
+			t.Fatalf("expected %d, got %d", i, x)
+		}
+	}
+
+	// An emptied heap starts a new lazy batch.
+	compares = 0
+	for _, x := range data[:10] {
+		h.Push(x)
+	}
+	if compares != 0 {
+		t.Fatalf("expected no comparisons while building, got %d", compares)
+	}
+	h.SetLazy(false)
+	h.Push(-1)
+	if h.Pop() != -1 || h.Len() != 10 {
+		t.Fatal("wrong element after disabling lazy ordering")
+	}
+}
+
+func BenchmarkLazyBuild(b *testing.B) {
+	data := rand.Perm(10000)
+	for _, lazy := range []bool{false, true} {
+		name := "eager"
+		if lazy {
+			name = "lazy"
+		}
+		b.Run(name, func(b *testing.B) {
+			h := heap.New(cmp.Less[int])
+			h.SetLazy(lazy)
+			for b.Loop() {
+				for _, x := range data {
+					h.Push(x)
+				}
+				for h.Len() != 0 {
+					h.Pop()
+				}
+			}
+		})
+	}
+}
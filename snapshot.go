@@ -0,0 +1,414 @@
+package heap
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"iter"
+	"slices"
+)
+
+var (
+	// ErrSnapshotFormat is returned when loading data that is not a heap
+	// snapshot, or is a snapshot of a different kind of heap.
+	ErrSnapshotFormat = errors.New("heap: invalid snapshot format")
+	// ErrSnapshotVersion is returned when loading a snapshot written in a
+	// newer format than this version of the package can read.
+	ErrSnapshotVersion = errors.New("heap: unsupported snapshot version")
+	// ErrSnapshotCompression is returned when loading a compressed snapshot
+	// without a matching compression option.
+	ErrSnapshotCompression = errors.New("heap: unsupported snapshot compression")
+)
+
+const (
+	// snapshotVersion is the version of the snapshot format written by Save.
+	snapshotVersion = 1
+	// binaryHeapVariant identifies snapshots of a binary Heap.
+	binaryHeapVariant = 0
+	// maxPrealloc limits how many elements Load allocates space for up
+	// front, so that a corrupt element count cannot cause a huge allocation.
+	maxPrealloc = 1 << 16
+)
+
+var snapshotMagic = [4]byte{'H', 'E', 'A', 'P'}
+
+// snapshotHeader describes a snapshot. It is encoded at the start of each
+// snapshot as the magic bytes followed by the fields in big-endian order, and
+// then a checksum of the encoded header.
+type snapshotHeader struct {
+	version uint16
+	variant uint8
+	arity   uint8
+	count   uint64
+	codec   uint8 // Compression.ID, or 0 if not compressed.
+	flags   uint8 // Reserved, must be 0.
+}
+
+const snapshotHeaderSize = 18
+
+func (hdr snapshotHeader) marshal() []byte {
+	b := make([]byte, snapshotHeaderSize)
+	copy(b, snapshotMagic[:])
+	binary.BigEndian.PutUint16(b[4:], hdr.version)
+	b[6] = hdr.variant
+	b[7] = hdr.arity
+	binary.BigEndian.PutUint64(b[8:], hdr.count)
+	b[16] = hdr.codec
+	b[17] = hdr.flags
+	return b
+}
+
+// snapshotFormat reads one version of the snapshot format.
+type snapshotFormat struct {
+	// readHeader reads the rest of a header that begins with prefix, the
+	// magic bytes and version, and returns the header in its current form.
+	readHeader func(r io.Reader, prefix []byte) (snapshotHeader, error)
+	// body returns a reader of the element data that follows the header.
+	// Load reads the body to its end, so the body can verify any data
+	// after the last element.
+	body func(r io.Reader) io.Reader
+}
+
+// snapshotFormats holds the reader of each snapshot format version that Load
+// can read. When the format changes, the reader of each earlier version is
+// kept here, converting its header to the current form, so that snapshots
+// written by earlier versions of this package keep loading.
+var snapshotFormats = map[uint16]snapshotFormat{
+	1: {readHeader: readHeaderV1, body: readBodyV1},
+}
+
+// readSnapshotHeader reads a snapshot header, using the reader of the
+// snapshot's format version, and returns the header and a reader of the
+// element data that follows it.
+func readSnapshotHeader(r io.Reader) (snapshotHeader, io.Reader, error) {
+	var b [6]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return snapshotHeader{}, nil, err
+	}
+	if [4]byte(b[:4]) != snapshotMagic {
+		return snapshotHeader{}, nil, ErrSnapshotFormat
+	}
+	version := binary.BigEndian.Uint16(b[4:])
+	f, ok := snapshotFormats[version]
+	if !ok {
+		if version > snapshotVersion {
+			return snapshotHeader{}, nil, ErrSnapshotVersion
+		}
+		return snapshotHeader{}, nil, ErrSnapshotFormat
+	}
+	hdr, err := f.readHeader(r, b[:])
+	if err != nil {
+		return snapshotHeader{}, nil, err
+	}
+	return hdr, f.body(r), nil
+}
+
+// readHeaderV1 reads a version 1 header, which is followed by its checksum.
+func readHeaderV1(r io.Reader, prefix []byte) (snapshotHeader, error) {
+	var b [snapshotHeaderSize + 4]byte
+	copy(b[:], prefix)
+	if _, err := io.ReadFull(r, b[len(prefix):]); err != nil {
+		return snapshotHeader{}, unexpectedEOF(err)
+	}
+	if crc32.Checksum(b[:snapshotHeaderSize], crcTable) != binary.BigEndian.Uint32(b[snapshotHeaderSize:]) {
+		return snapshotHeader{}, &CorruptionError{Offset: 0}
+	}
+	hdr := snapshotHeader{
+		version: binary.BigEndian.Uint16(b[4:]),
+		variant: b[6],
+		arity:   b[7],
+		count:   binary.BigEndian.Uint64(b[8:]),
+		codec:   b[16],
+		flags:   b[17],
+	}
+	if hdr.flags != 0 {
+		return snapshotHeader{}, ErrSnapshotVersion
+	}
+	return hdr, nil
+}
+
+// readBodyV1 reads version 1 element data, which is written in checksummed
+// blocks.
+func readBodyV1(r io.Reader) io.Reader {
+	return newBlockReader(r, snapshotHeaderSize+4)
+}
+
+// Compression describes a compression format for snapshots. Compression
+// formats are provided by the caller, so that this package does not depend on
+// any compression library. For example, to compress snapshots with gzip:
+//
+//	heap.Compression{
+//		ID:        1,
+//		NewWriter: func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+//		NewReader: func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) },
+//	}
+type Compression struct {
+	// ID identifies the compression format in the snapshot header. It must
+	// not be 0, and must be the same when saving and loading.
+	ID byte
+	// NewWriter returns a writer that compresses data written to it and
+	// writes it to w. Closing the writer must flush all data to w, but must
+	// not close w.
+	NewWriter func(w io.Writer) io.WriteCloser
+	// NewReader returns a reader that decompresses data read from r.
+	NewReader func(r io.Reader) (io.ReadCloser, error)
+}
+
+// SnapshotOption configures Save and Load.
+type SnapshotOption func(*snapshotOptions)
+
+type snapshotOptions struct {
+	compression []Compression
+}
+
+// WithCompression configures Save to compress the snapshot, and records the
+// compression format in the snapshot header. When passed to Load, it allows
+// loading snapshots compressed with that format. Multiple compression formats
+// can be given to Load; Save uses the first.
+func WithCompression(c Compression) SnapshotOption {
+	if c.ID == 0 {
+		panic("heap: compression ID must not be 0")
+	}
+	return func(o *snapshotOptions) {
+		o.compression = append(o.compression, c)
+	}
+}
+
+func getSnapshotOptions(opts []SnapshotOption) snapshotOptions {
+	var o snapshotOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Save writes a binary snapshot of the heap to w. The snapshot contains a
+// header identifying the snapshot format and the number of elements, followed
+// by each element, in heap order, written by enc. The header and elements are
+// covered by checksums, which Load verifies. Writes to w are buffered, so enc
+// does not need to buffer its writes. Options can be given to compress the
+// elements.
+func (h *Heap[T]) Save(w io.Writer, enc func(io.Writer, T) error, opts ...SnapshotOption) error {
+	if h.guard != nil {
+		h.checkRead()
+	}
+	data := h.heapOrder()
+	o := getSnapshotOptions(opts)
+	bw := bufio.NewWriter(w)
+	hdr := snapshotHeader{
+		version: snapshotVersion,
+		variant: binaryHeapVariant,
+		arity:   2,
+		count:   uint64(len(data)),
+	}
+	var cw io.WriteCloser
+	if len(o.compression) != 0 {
+		hdr.codec = o.compression[0].ID
+	}
+	b := hdr.marshal()
+	b = binary.BigEndian.AppendUint32(b, crc32.Checksum(b, crcTable))
+	if _, err := bw.Write(b); err != nil {
+		return err
+	}
+	blocks := newBlockWriter(bw)
+	ew := io.Writer(blocks)
+	if hdr.codec != 0 {
+		cw = o.compression[0].NewWriter(blocks)
+		ew = cw
+	}
+	for _, x := range data {
+		if err := enc(ew, x); err != nil {
+			return err
+		}
+	}
+	if cw != nil {
+		if err := cw.Close(); err != nil {
+			return err
+		}
+	}
+	if err := blocks.Close(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// Load replaces the heap's elements with the elements of a snapshot written by
+// Save, using dec to read each element. Since the snapshot is already in heap
+// order, the heap is restored in O(n) without re-establishing the ordering, so
+// the heap's less function must order elements the same way as the less
+// function of the heap that was saved.
+//
+// If the snapshot does not match its checksums, Load returns a
+// *CorruptionError. To load a compressed snapshot, pass the WithCompression
+// option for its compression format.
+//
+// Load reads exactly the bytes of the snapshot from r. Wrapping r in a
+// bufio.Reader can make loading large snapshots faster. If Load returns an
+// error, the heap is not modified.
+func (h *Heap[T]) Load(r io.Reader, dec func(io.Reader) (T, error), opts ...SnapshotOption) error {
+	hdr, body, err := readSnapshotHeader(r)
+	if err != nil {
+		return err
+	}
+	if hdr.variant != binaryHeapVariant || hdr.arity != 2 {
+		return ErrSnapshotFormat
+	}
+	r = body
+	if hdr.codec != 0 {
+		o := getSnapshotOptions(opts)
+		i := slices.IndexFunc(o.compression, func(c Compression) bool {
+			return c.ID == hdr.codec
+		})
+		if i < 0 {
+			return ErrSnapshotCompression
+		}
+		cr, err := o.compression[i].NewReader(r)
+		if err != nil {
+			return err
+		}
+		// The data read is verified by the block checksums, so an error
+		// closing the decompressor is not reported.
+		defer func() { _ = cr.Close() }()
+		r = cr
+	}
+	data := make([]T, 0, min(hdr.count, maxPrealloc))
+	for range hdr.count {
+		x, err := dec(r)
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		data = append(data, x)
+	}
+	// Read to the end of the body, verifying any remaining checksums.
+	if _, err = io.Copy(io.Discard, body); err != nil {
+		return err
+	}
+	h.replace(data, false)
+	return nil
+}
+
+// Pages returns an iterator that encodes the heap as a sequence of pages, each
+// holding up to pageSize elements in heap order. Each page holds the number of
+// elements in the page followed by each element written by enc. Pages are
+// encoded one at a time as iteration proceeds, so a large heap can be written
+// out without encoding the whole heap in memory. The page slice is reused, and
+// is only valid until the next iteration.
+//
+// The pages hold the elements of the heap at the time Pages is called. The
+// heap's storage is shared with the iterator, and is copied by the first
+// modification of the heap after Pages is called, so the heap can be modified
+// while the pages are encoded, and its storage is only copied if it is. The
+// iterator does not use the heap itself, so it can run in another goroutine
+// while the heap is used by goroutines that hold the lock that guards the
+// heap, as long as Pages is called while holding that lock. The elements are
+// not copied, so elements that refer to other data must not be changed
+// in place until the pages are encoded.
+//
+// If enc returns an error, the iterator yields the error and stops.
+func (h *Heap[T]) Pages(pageSize int, enc func(io.Writer, T) error) iter.Seq2[[]byte, error] {
+	if pageSize < 1 {
+		panic("heap: page size must be positive")
+	}
+	if h.guard != nil {
+		h.checkRead()
+	}
+	data := h.heapOrder()
+	// A copy put in heap order is not shared with the heap.
+	if !h.unordered {
+		h.shared = true
+	}
+	return func(yield func([]byte, error) bool) {
+		var buf bytes.Buffer
+		for start := 0; start < len(data); start += pageSize {
+			page := data[start:min(start+pageSize, len(data))]
+			buf.Reset()
+			var count [binary.MaxVarintLen64]byte
+			buf.Write(count[:binary.PutUvarint(count[:], uint64(len(page)))])
+			for _, x := range page {
+				if err := enc(&buf, x); err != nil {
+					yield(nil, err)
+					return
+				}
+			}
+			if !yield(buf.Bytes(), nil) {
+				return
+			}
+		}
+	}
+}
+
+// LoadPage appends the elements of a page produced by Pages to the heap.
+// Loading all the pages of a heap, in order, into an empty heap restores the
+// heap exactly. Every prefix of a heap's layout is itself a valid heap, so the
+// heap is valid and can be used after each page is loaded.
+//
+// If the elements of the page are not in heap order following the elements
+// already in the heap, such as when pages are loaded out of order, LoadPage
+// returns ErrNotHeapOrder. If the page holds bytes after its last element, it
+// returns ErrSnapshotFormat. If LoadPage returns an error, the heap is not
+// modified.
+func (h *Heap[T]) LoadPage(page []byte, dec func(io.Reader) (T, error)) error {
+	r := bytes.NewReader(page)
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	data := make([]T, 0, min(n, maxPrealloc))
+	for range n {
+		x, err := dec(r)
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		data = append(data, x)
+	}
+	if r.Len() != 0 {
+		return ErrSnapshotFormat
+	}
+	if h.less == nil {
+		return errNoLess
+	}
+
+	h.ensureOrdered()
+	if h.guard != nil {
+		h.startWrite()
+	}
+	defer h.endOp()
+	start := len(h.data)
+	// The parent of each appended element is either already in the heap or
+	// earlier in the page.
+	for i := max(start, 1); i < start+len(data); i++ {
+		var parent T
+		if p := (i - 1) / 2; p >= start {
+			parent = data[p-start]
+		} else {
+			parent = h.data[p]
+		}
+		if h.less(data[i-start], parent) {
+			return ErrNotHeapOrder
+		}
+	}
+	h.modify()
+	h.data = append(h.data, data...)
+	if h.onMove != nil {
+		for i, x := range data {
+			h.onMove(x, start+i)
+		}
+	}
+	h.watermarksChanged()
+	if h.journal != nil {
+		h.recordOp(OpLoad, len(h.data))
+	}
+	return nil
+}
@@ -0,0 +1,289 @@
+package heap_test
+
+import (
+	"bytes"
+	"cmp"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"math/rand"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/gammazero/heap"
+)
+
+func encodeInt(w io.Writer, x int) error {
+	return binary.Write(w, binary.BigEndian, int64(x))
+}
+
+func decodeInt(r io.Reader) (int, error) {
+	var x int64
+	err := binary.Read(r, binary.BigEndian, &x)
+	return int(x), err
+}
+
+func TestSaveLoad(t *testing.T) {
+	less := cmp.Less[int]
+	h := heap.New(less)
+	for range 1000 {
+		h.Push(rand.Intn(10000))
+	}
+
+	var buf bytes.Buffer
+	if err := h.Save(&buf, encodeInt); err != nil {
+		t.Fatal(err)
+	}
+
+	h2 := heap.New(less)
+	if err := h2.Load(&buf, decodeInt); err != nil {
+		t.Fatal(err)
+	}
+	if h2.Len() != h.Len() {
+		t.Fatalf("expected %d elements, got %d", h.Len(), h2.Len())
+	}
+	// The layout is restored exactly.
+	for i := 0; i < h.Len(); i++ {
+		if h.At(i) != h2.At(i) {
+			t.Fatalf("element %d differs: %d != %d", i, h.At(i), h2.At(i))
+		}
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	h := heap.NewFrom(cmp.Less[int], 1, 2, 3)
+	var buf bytes.Buffer
+	if err := h.Save(&buf, encodeInt); err != nil {
+		t.Fatal(err)
+	}
+	snapshot := buf.Bytes()
+
+	h2 := heap.NewFrom(cmp.Less[int], 42)
+	err := h2.Load(bytes.NewReader(snapshot[:len(snapshot)-4]), decodeInt)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected ErrUnexpectedEOF, got %v", err)
+	}
+	if h2.Len() != 1 || h2.Peek() != 42 {
+		t.Fatal("heap modified by failed load")
+	}
+
+	if err = h2.Load(bytes.NewReader(nil), decodeInt); err != io.EOF {
+		t.Fatalf("expected EOF, got %v", err)
+	}
+
+	errEnc := errors.New("encode failed")
+	err = h.Save(io.Discard, func(io.Writer, int) error { return errEnc })
+	if err != errEnc {
+		t.Fatalf("expected encode error, got %v", err)
+	}
+}
+
+// resealHeader updates the checksum of a modified snapshot header.
+func resealHeader(snapshot []byte) {
+	sum := crc32.Checksum(snapshot[:18], crc32.MakeTable(crc32.Castagnoli))
+	binary.BigEndian.PutUint32(snapshot[18:], sum)
+}
+
+func TestLoadVersions(t *testing.T) {
+	h := heap.New(cmp.Less[int])
+	var buf bytes.Buffer
+	if err := heap.NewFrom(cmp.Less[int], 1).Save(&buf, encodeInt); err != nil {
+		t.Fatal(err)
+	}
+	snapshot := buf.Bytes()
+
+	future := bytes.Clone(snapshot)
+	binary.BigEndian.PutUint16(future[4:], 99)
+	if err := h.Load(bytes.NewReader(future), decodeInt); err != heap.ErrSnapshotVersion {
+		t.Fatalf("expected ErrSnapshotVersion, got %v", err)
+	}
+
+	// Headerless snapshots, which began with the element count, are not
+	// supported.
+	headerless := binary.BigEndian.AppendUint64(nil, 1)
+	headerless = binary.BigEndian.AppendUint64(headerless, 1)
+	if err := h.Load(bytes.NewReader(headerless), decodeInt); err != heap.ErrSnapshotFormat {
+		t.Fatalf("expected ErrSnapshotFormat, got %v", err)
+	}
+
+	old := bytes.Clone(snapshot)
+	binary.BigEndian.PutUint16(old[4:], 0)
+	resealHeader(old)
+	if err := h.Load(bytes.NewReader(old), decodeInt); err != heap.ErrSnapshotFormat {
+		t.Fatalf("expected ErrSnapshotFormat, got %v", err)
+	}
+
+	variant := bytes.Clone(snapshot)
+	variant[7] = 4
+	resealHeader(variant)
+	if err := h.Load(bytes.NewReader(variant), decodeInt); err != heap.ErrSnapshotFormat {
+		t.Fatalf("expected ErrSnapshotFormat, got %v", err)
+	}
+
+	flags := bytes.Clone(snapshot)
+	flags[17] = 1
+	resealHeader(flags)
+	if err := h.Load(bytes.NewReader(flags), decodeInt); err != heap.ErrSnapshotVersion {
+		t.Fatalf("expected ErrSnapshotVersion, got %v", err)
+	}
+
+	garbage := []byte("not a heap snapshot")
+	if err := h.Load(bytes.NewReader(garbage), decodeInt); err != heap.ErrSnapshotFormat {
+		t.Fatalf("expected ErrSnapshotFormat, got %v", err)
+	}
+	if err := h.Load(bytes.NewReader(snapshot[:10]), decodeInt); err != io.ErrUnexpectedEOF {
+		t.Fatalf("expected ErrUnexpectedEOF, got %v", err)
+	}
+	if h.Len() != 0 {
+		t.Fatal("heap modified by failed load")
+	}
+}
+
+var gzipCompression = heap.Compression{
+	ID:        1,
+	NewWriter: func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+	NewReader: func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) },
+}
+
+func TestSaveLoadCompressed(t *testing.T) {
+	h := heap.New(cmp.Less[int])
+	for i := range 1000 {
+		h.Push(1_000_000 + i%10)
+	}
+	var plain, compressed bytes.Buffer
+	if err := h.Save(&plain, encodeInt); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Save(&compressed, encodeInt, heap.WithCompression(gzipCompression)); err != nil {
+		t.Fatal(err)
+	}
+	if compressed.Len() >= plain.Len()/4 {
+		t.Fatalf("snapshot not compressed: %d bytes, uncompressed %d", compressed.Len(), plain.Len())
+	}
+
+	h2 := heap.New(cmp.Less[int])
+	err := h2.Load(bytes.NewReader(compressed.Bytes()), decodeInt)
+	if err != heap.ErrSnapshotCompression {
+		t.Fatalf("expected ErrSnapshotCompression, got %v", err)
+	}
+	if err = h2.Load(&compressed, decodeInt, heap.WithCompression(gzipCompression)); err != nil {
+		t.Fatal(err)
+	}
+	for i := range h.Len() {
+		if h.At(i) != h2.At(i) {
+			t.Fatal("loaded heap does not match saved heap")
+		}
+	}
+
+	// Uncompressed snapshots load when compression options are given.
+	if err = h2.Load(&plain, decodeInt, heap.WithCompression(gzipCompression)); err != nil {
+		t.Fatal(err)
+	}
+	assertPanics(t, "zero ID", func() { heap.WithCompression(heap.Compression{}) })
+}
+
+func TestPagesConcurrentPush(t *testing.T) {
+	less := cmp.Less[int]
+	h := heap.New(less)
+	for range 1000 {
+		h.Push(rand.Intn(10000))
+	}
+	want := h.Export()
+
+	// The pages hold the heap as it was when Pages was called, while the
+	// heap keeps being modified by another goroutine.
+	var mu sync.Mutex
+	mu.Lock()
+	pages := h.Pages(50, encodeInt)
+	mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range 1000 {
+			mu.Lock()
+			h.Push(-i)
+			h.Pop()
+			mu.Unlock()
+		}
+	}()
+	h2 := heap.New(less)
+	for page, err := range pages {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = h2.LoadPage(page, decodeInt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-done
+	if got := h2.Export(); !slices.Equal(got, want) {
+		t.Fatal("pages do not hold the heap as it was when Pages was called")
+	}
+	if h.Len() != 1000 {
+		t.Fatalf("expected 1000 elements, got %d", h.Len())
+	}
+}
+
+func TestPages(t *testing.T) {
+	less := cmp.Less[int]
+	h := heap.New(less)
+	for range 1000 {
+		h.Push(rand.Intn(10000))
+	}
+
+	var pages [][]byte
+	for page, err := range h.Pages(64, encodeInt) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages = append(pages, bytes.Clone(page))
+	}
+	if len(pages) != 16 {
+		t.Fatalf("expected 16 pages, got %d", len(pages))
+	}
+
+	h2 := heap.New(less)
+	for _, page := range pages {
+		if err := h2.LoadPage(page, decodeInt); err != nil {
+			t.Fatal(err)
+		}
+		verifyIntHeap(t, h2, 0, less)
+	}
+	for i := 0; i < h.Len(); i++ {
+		if h.At(i) != h2.At(i) {
+			t.Fatalf("element %d differs: %d != %d", i, h.At(i), h2.At(i))
+		}
+	}
+
+	if err := h2.LoadPage(pages[0][:10], decodeInt); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected ErrUnexpectedEOF, got %v", err)
+	}
+	if err := h2.LoadPage(append(bytes.Clone(pages[0]), 0), decodeInt); err != heap.ErrSnapshotFormat {
+		t.Fatalf("expected ErrSnapshotFormat, got %v", err)
+	}
+	if h2.Len() != h.Len() {
+		t.Fatal("heap modified by failed page load")
+	}
+
+	// A page whose elements are less than their parents is rejected.
+	small := heap.New(less)
+	small.Push(-1)
+	for page := range small.Pages(1, encodeInt) {
+		if err := h2.LoadPage(page, decodeInt); err != heap.ErrNotHeapOrder {
+			t.Fatalf("expected ErrNotHeapOrder, got %v", err)
+		}
+	}
+	if h2.Len() != h.Len() {
+		t.Fatal("heap modified by failed page load")
+	}
+
+	errEnc := errors.New("encode failed")
+	for _, err := range h.Pages(10, func(io.Writer, int) error { return errEnc }) {
+		if err != errEnc {
+			t.Fatalf("expected encode error, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package heap
+
+import "slices"
+
+// PopAllSorted removes all elements from the heap and returns them in sorted
+// order, from minimum to maximum. The elements are sorted in place by
+// heapsort, and the heap's storage is returned, so no memory is allocated.
+// The heap is left empty, and allocates new storage when elements are next
+// pushed.
+func (h *Heap[T]) PopAllSorted() []T {
+	h.ensureOrdered()
+	if h.guard != nil {
+		h.startWrite()
+	}
+	defer h.endOp()
+	h.modify()
+	data := h.data
+	if h.onMove != nil {
+		for _, x := range data {
+			h.onMove(x, -1)
+		}
+	}
+	if h.stats != nil {
+		h.stats.Pops += uint64(len(data))
+	}
+	if h.journal != nil {
+		// The elements are recorded in heap order rather than in the
+		// order they are returned, since they are all removed at once.
+		for i, x := range data {
+			h.record(OpPop, x, len(data)-1-i)
+		}
+	}
+
+	// Sifting must not call onMove for elements that have been removed.
+	onMove := h.onMove
+	h.onMove = nil
+	// Move each minimum element to the end of the shrinking heap, which
+	// leaves the elements in descending order.
+	for end := len(data) - 1; end > 0; end-- {
+		data[0], data[end] = data[end], data[0]
+		h.data = data[:end]
+		h.down(0)
+	}
+	slices.Reverse(data)
+	h.onMove = onMove
+
+	h.data = nil
+	h.watermarksChanged()
+	if len(data) != 0 {
+		h.notifyRemove(len(data), 0)
+	}
+	return data
+}
@@ -0,0 +1,76 @@
+package heap_test
+
+import (
+	"cmp"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/gammazero/heap"
+)
+
+func TestPopAllSorted(t *testing.T) {
+	data := make([]int, 1000)
+	for i := range data {
+		data[i] = rand.Intn(100)
+	}
+	h := heap.NewFrom(cmp.Less[int], slices.Clone(data)...)
+	var removed int
+	h.SetOnMove(func(_, i int) {
+		if i < 0 {
+			removed++
+		}
+	})
+	sorted := h.PopAllSorted()
+	slices.Sort(data)
+	if !slices.Equal(sorted, data) {
+		t.Fatal("elements not sorted")
+	}
+	if h.Len() != 0 || removed != len(data) {
+		t.Fatalf("heap not emptied: length %d, removed %d", h.Len(), removed)
+	}
+
+	// The heap can be used again.
+	h.Push(2)
+	h.Push(1)
+	if h.Pop() != 1 {
+		t.Fatal("wrong element after PopAllSorted")
+	}
+	if sorted[0] != data[0] {
+		t.Fatal("returned slice modified by later use of heap")
+	}
+
+	hm := heap.NewMax(3, 1, 2)
+	if out := hm.PopAllSorted(); !slices.Equal(out, []int{3, 2, 1}) {
+		t.Fatalf("wrong order from max-heap: %v", out)
+	}
+	if out := heap.New(cmp.Less[int]).PopAllSorted(); len(out) != 0 {
+		t.Fatal("expected empty result")
+	}
+}
+
+func TestPopAllSortedAllocs(t *testing.T) {
+	h := heap.New(cmp.Less[int])
+	data := rand.Perm(1000)
+	allocs := testing.AllocsPerRun(10, func() {
+		if err := h.ImportLevelOrder(nil); err != nil {
+			t.Fatal(err)
+		}
+		for _, x := range data {
+			h.Push(x)
+		}
+		h.PopAllSorted()
+	})
+	h2 := heap.New(cmp.Less[int])
+	pushAllocs := testing.AllocsPerRun(10, func() {
+		if err := h2.ImportLevelOrder(nil); err != nil {
+			t.Fatal(err)
+		}
+		for _, x := range data {
+			h2.Push(x)
+		}
+	})
+	if allocs != pushAllocs {
+		t.Fatalf("PopAllSorted allocated: %v allocations, pushes alone %v", allocs, pushAllocs)
+	}
+}
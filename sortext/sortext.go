@@ -0,0 +1,70 @@
+// Package sortext sorts sequences that are too large to sort in memory.
+package sortext
+
+import (
+	"io"
+	"iter"
+
+	"github.com/gammazero/heap/extheap"
+)
+
+// DefaultChunkSize is the number of elements sorted in memory at a time when
+// Options.ChunkSize is not set.
+const DefaultChunkSize = 1 << 16
+
+// Options configures SortLarge.
+type Options[T any] struct {
+	// ChunkSize is the number of elements that are sorted in memory before
+	// being written to a temporary file. If zero, DefaultChunkSize is used.
+	ChunkSize int
+	// Dir is the directory for temporary files. If empty, the default
+	// directory for temporary files is used.
+	Dir string
+	// Encode writes an element to a temporary file. It must be set.
+	Encode func(io.Writer, T) error
+	// Decode reads an element written by Encode. It must be set.
+	Decode func(io.Reader) (T, error)
+}
+
+// SortLarge returns an iterator over the elements of input in the order given
+// by less. The input is read in chunks, each chunk is sorted and written to a
+// temporary file, and the sorted chunks are merged as the iterator is
+// consumed. Temporary files are removed when iteration finishes or is
+// stopped.
+//
+// The sort is not stable. If a temporary file cannot be written or read, the
+// iterator yields the error and stops.
+func SortLarge[T any](input iter.Seq[T], less func(a, b T) bool, opts Options[T]) iter.Seq2[T, error] {
+	if opts.Encode == nil || opts.Decode == nil {
+		panic("sortext: Encode and Decode must be set")
+	}
+	chunkSize := opts.ChunkSize
+	if chunkSize == 0 {
+		chunkSize = DefaultChunkSize
+	}
+	return func(yield func(T, error) bool) {
+		h := extheap.New(less, opts.Encode, opts.Decode, chunkSize, opts.Dir)
+		// The files of a heap that has been emptied have already been
+		// removed, so Close only removes files when iteration stops early,
+		// after an error or because the caller stopped it. An error from it
+		// cannot be yielded then.
+		defer func() { _ = h.Close() }()
+		var zero T
+		for x := range input {
+			if err := h.Push(x); err != nil {
+				yield(zero, err)
+				return
+			}
+		}
+		for h.Len() != 0 {
+			x, err := h.Pop()
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			if !yield(x, nil) {
+				return
+			}
+		}
+	}
+}
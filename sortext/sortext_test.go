@@ -0,0 +1,82 @@
+package sortext_test
+
+import (
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/rand"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/gammazero/heap/sortext"
+)
+
+func encodeInt(w io.Writer, x int) error {
+	return binary.Write(w, binary.BigEndian, int64(x))
+}
+
+func decodeInt(r io.Reader) (int, error) {
+	var x int64
+	err := binary.Read(r, binary.BigEndian, &x)
+	return int(x), err
+}
+
+func TestSortLarge(t *testing.T) {
+	input := make([]int, 10000)
+	for i := range input {
+		input[i] = rand.Intn(len(input))
+	}
+	dir := t.TempDir()
+	opts := sortext.Options[int]{
+		ChunkSize: 100,
+		Dir:       dir,
+		Encode:    encodeInt,
+		Decode:    decodeInt,
+	}
+
+	var out []int
+	for x, err := range sortext.SortLarge(slices.Values(input), cmp.Less[int], opts) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, x)
+	}
+	slices.Sort(input)
+	if !slices.Equal(out, input) {
+		t.Fatal("output not sorted")
+	}
+
+	// Stop early and check that temporary files are removed.
+	for range sortext.SortLarge(slices.Values(input), cmp.Less[int], opts) {
+		break
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 0 {
+		t.Fatalf("expected temporary files to be removed, found %d", len(files))
+	}
+}
+
+func TestSortLargeError(t *testing.T) {
+	errEnc := errors.New("encode failed")
+	opts := sortext.Options[int]{
+		ChunkSize: 2,
+		Dir:       t.TempDir(),
+		Encode:    func(io.Writer, int) error { return errEnc },
+		Decode:    decodeInt,
+	}
+	var n int
+	for _, err := range sortext.SortLarge(slices.Values([]int{3, 2, 1}), cmp.Less[int], opts) {
+		if err != errEnc {
+			t.Fatalf("expected encode error, got %v", err)
+		}
+		n++
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 error, got %d", n)
+	}
+}
@@ -0,0 +1,80 @@
+package heap
+
+import "math/bits"
+
+// Stats holds counts of heap operations, for profiling how a heap is used.
+type Stats struct {
+	// Pushes is the number of elements pushed.
+	Pushes uint64
+	// Pops is the number of elements removed by Pop or Remove.
+	Pops uint64
+	// Compares is the number of calls to the less function.
+	Compares uint64
+	// SiftLevels is the total number of levels that elements were moved up
+	// or down the heap to restore the heap ordering.
+	SiftLevels uint64
+	// Reallocs is the number of times the heap's storage was reallocated to
+	// make room for pushed elements.
+	Reallocs uint64
+}
+
+type heapStats[T any] struct {
+	Stats
+	less func(a, b T) bool
+}
+
+// compare calls the less function and counts the call.
+func (s *heapStats[T]) compare(a, b T) bool {
+	s.Compares++
+	return s.less(a, b)
+}
+
+// EnableStats enables or disables counting of heap operations. Counts are
+// reset when counting is enabled. Counting adds a small cost to every heap
+// operation, and large heaps created by heapifying existing data are not
+// heapified in parallel while counting is enabled.
+func (h *Heap[T]) EnableStats(enable bool) {
+	if !enable {
+		if h.stats != nil {
+			h.less = h.stats.less
+			h.stats = nil
+		}
+		return
+	}
+	if h.stats != nil {
+		h.ResetStats()
+		return
+	}
+	s := &heapStats[T]{less: h.less}
+	// A heap without a less function is left without one, so that it still
+	// requires SetLess before use.
+	if h.less != nil {
+		h.less = s.compare
+	}
+	h.stats = s
+}
+
+// Stats returns the counts of heap operations since counting was enabled or
+// the counts were last reset. If counting is not enabled, all counts are zero.
+func (h *Heap[T]) Stats() Stats {
+	if h.stats == nil {
+		return Stats{}
+	}
+	return h.stats.Stats
+}
+
+// ResetStats sets all counts of heap operations to zero.
+func (h *Heap[T]) ResetStats() {
+	if h.stats != nil {
+		h.stats.Stats = Stats{}
+	}
+}
+
+// addSift counts the levels between indexes i and j.
+func (h *Heap[T]) addSift(i, j int) {
+	a, b := bits.Len(uint(i+1)), bits.Len(uint(j+1))
+	if a > b {
+		a, b = b, a
+	}
+	h.stats.SiftLevels += uint64(b - a)
+}
@@ -0,0 +1,89 @@
+package heap_test
+
+import (
+	"cmp"
+	"testing"
+
+	"github.com/gammazero/heap"
+)
+
+func TestStats(t *testing.T) {
+	var compares uint64
+	h := heap.New(func(a, b int) bool {
+		compares++
+		return a < b
+	})
+	h.Push(1)
+	if h.Stats() != (heap.Stats{}) {
+		t.Fatal("expected zero stats when not enabled")
+	}
+
+	h.EnableStats(true)
+	compares = 0
+	for i := 10; i > 0; i-- {
+		h.Push(i)
+	}
+	h.Pop()
+	h.Remove(h.Len() - 1)
+	s := h.Stats()
+	if s.Pushes != 10 || s.Pops != 2 {
+		t.Fatalf("wrong operation counts: %+v", s)
+	}
+	if s.Compares != compares {
+		t.Fatalf("expected %d compares, got %d", compares, s.Compares)
+	}
+	if s.SiftLevels == 0 || s.Reallocs == 0 {
+		t.Fatalf("expected sifts and reallocations: %+v", s)
+	}
+
+	h.ResetStats()
+	if h.Stats() != (heap.Stats{}) {
+		t.Fatal("expected zero stats after reset")
+	}
+
+	// Pushing an element larger than all others does not sift.
+	h.Push(100)
+	if s = h.Stats(); s.SiftLevels != 0 {
+		t.Fatalf("expected no sift levels, got %d", s.SiftLevels)
+	}
+	// Pushing a new minimum sifts it to the root.
+	h.Push(-1)
+	if s = h.Stats(); s.SiftLevels != 3 {
+		t.Fatalf("expected 3 sift levels, got %d", s.SiftLevels)
+	}
+
+	h.EnableStats(false)
+	compares = 0
+	h.Push(5)
+	if h.Stats() != (heap.Stats{}) || compares == 0 {
+		t.Fatal("stats counted after disabling")
+	}
+}
+
+func TestStatsReallocs(t *testing.T) {
+	h := heap.New(cmp.Less[int])
+	h.EnableStats(true)
+	for i := range 1000 {
+		h.Push(i)
+	}
+	// Appending grows the storage geometrically.
+	if r := h.Stats().Reallocs; r < 5 || r > 30 {
+		t.Fatalf("unexpected number of reallocations: %d", r)
+	}
+}
+
+func TestStatsZeroHeap(t *testing.T) {
+	var h heap.Heap[int]
+	h.EnableStats(true)
+	assertPanics(t, "push without less function", func() { h.Push(1) })
+
+	h.SetLess(cmp.Less[int])
+	h.Push(2)
+	h.Push(1)
+	if h.Pop() != 1 {
+		t.Fatal("expected 1")
+	}
+	if h.Stats().Compares == 0 {
+		t.Fatal("expected compares to be counted")
+	}
+}
@@ -0,0 +1,127 @@
+// Package sweep provides the event queue of a sweep-line algorithm, such as
+// finding overlapping intervals, computing a skyline, or finding segment
+// intersections.
+//
+// Events are ordered by coordinate, and events at the same coordinate are
+// ordered by kind, then by the order they were pushed. New events can be
+// pushed while sweeping, such as a crossing found between two segments that
+// become adjacent, as long as they are not behind the sweep line.
+package sweep
+
+import (
+	"cmp"
+
+	"github.com/gammazero/heap"
+)
+
+// Kind is the kind of an event.
+type Kind int
+
+const (
+	// Start is the event at which an object, such as an interval, begins.
+	Start Kind = iota
+	// Cross is an event between the start and end of objects, such as an
+	// intersection of two segments.
+	Cross
+	// End is the event at which an object ends.
+	End
+)
+
+// Event is an event at a coordinate on the sweep line.
+type Event[C cmp.Ordered, T any] struct {
+	At    C
+	Kind  Kind
+	Value T
+}
+
+// Queue holds events in the order they are swept. It is not safe for
+// concurrent use.
+type Queue[C cmp.Ordered, T any] struct {
+	h    *heap.Heap[item[C, T]]
+	rank [3]int
+	seq  uint64
+	pos  C
+	// swept is true once an event has been popped, so that pos is the
+	// position of the sweep line.
+	swept bool
+}
+
+type item[C cmp.Ordered, T any] struct {
+	ev  Event[C, T]
+	seq uint64
+}
+
+// New returns a new, empty Queue. Events at the same coordinate are ordered
+// by kind, in the order given by kinds, which must list each kind once. If no
+// kinds are given, the order is Start, Cross, End, which treats intervals as
+// closed, so that intervals that touch at a point overlap. For half-open
+// intervals, which do not overlap when one ends where the other starts, use
+// the order End, Cross, Start.
+func New[C cmp.Ordered, T any](kinds ...Kind) *Queue[C, T] {
+	q := &Queue[C, T]{}
+	if len(kinds) == 0 {
+		kinds = []Kind{Start, Cross, End}
+	}
+	if len(kinds) != len(q.rank) {
+		panic("sweep: kind order must list each kind once")
+	}
+	seen := 0
+	for i, k := range kinds {
+		if k < Start || k > End || seen&(1<<k) != 0 {
+			panic("sweep: kind order must list each kind once")
+		}
+		seen |= 1 << k
+		q.rank[k] = i
+	}
+	q.h = heap.New(func(a, b item[C, T]) bool {
+		if c := cmp.Compare(a.ev.At, b.ev.At); c != 0 {
+			return c < 0
+		}
+		if a.ev.Kind != b.ev.Kind {
+			return q.rank[a.ev.Kind] < q.rank[b.ev.Kind]
+		}
+		return a.seq < b.seq
+	})
+	return q
+}
+
+// Len returns the number of events in the queue.
+func (q *Queue[C, T]) Len() int {
+	return q.h.Len()
+}
+
+// Push adds an event. It returns false, and does not add the event, if the
+// event is behind the sweep line, at a coordinate less than that of the last
+// event popped.
+func (q *Queue[C, T]) Push(ev Event[C, T]) bool {
+	if ev.Kind < Start || ev.Kind > End {
+		panic("sweep: invalid event kind")
+	}
+	if q.swept && cmp.Less(ev.At, q.pos) {
+		return false
+	}
+	q.seq++
+	q.h.Push(item[C, T]{ev: ev, seq: q.seq})
+	return true
+}
+
+// Peek returns the next event without removing it. It panics if the queue is
+// empty.
+func (q *Queue[C, T]) Peek() Event[C, T] {
+	return q.h.Peek().ev
+}
+
+// Pop removes and returns the next event, and moves the sweep line to its
+// coordinate. It panics if the queue is empty.
+func (q *Queue[C, T]) Pop() Event[C, T] {
+	ev := q.h.Pop().ev
+	q.pos, q.swept = ev.At, true
+	return ev
+}
+
+// Position returns the coordinate of the sweep line, which is the coordinate
+// of the last event popped. The second return value is false if no event has
+// been popped.
+func (q *Queue[C, T]) Position() (C, bool) {
+	return q.pos, q.swept
+}
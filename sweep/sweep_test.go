@@ -0,0 +1,136 @@
+package sweep_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gammazero/heap/sweep"
+)
+
+type interval struct {
+	name       string
+	start, end int
+}
+
+func maxOverlap(intervals []interval, kinds ...sweep.Kind) int {
+	q := sweep.New[int, string](kinds...)
+	for _, iv := range intervals {
+		q.Push(sweep.Event[int, string]{At: iv.start, Kind: sweep.Start, Value: iv.name})
+		q.Push(sweep.Event[int, string]{At: iv.end, Kind: sweep.End, Value: iv.name})
+	}
+	var open, most int
+	for q.Len() != 0 {
+		switch q.Pop().Kind {
+		case sweep.Start:
+			open++
+			most = max(most, open)
+		case sweep.End:
+			open--
+		}
+	}
+	return most
+}
+
+func TestOverlap(t *testing.T) {
+	intervals := []interval{{"a", 0, 5}, {"b", 5, 10}, {"c", 2, 4}, {"d", 3, 6}}
+	if n := maxOverlap(intervals); n != 3 {
+		t.Fatalf("closed intervals: expected 3 overlapping, got %d", n)
+	}
+	touching := []interval{{"a", 0, 5}, {"b", 5, 10}}
+	if n := maxOverlap(touching); n != 2 {
+		t.Fatalf("closed intervals touching at a point overlap, got %d", n)
+	}
+	if n := maxOverlap(touching, sweep.End, sweep.Cross, sweep.Start); n != 1 {
+		t.Fatalf("half-open intervals touching at a point do not overlap, got %d", n)
+	}
+}
+
+func TestScheduleDuringSweep(t *testing.T) {
+	q := sweep.New[float64, string]()
+	q.Push(sweep.Event[float64, string]{At: 1, Kind: sweep.Start, Value: "s1"})
+	q.Push(sweep.Event[float64, string]{At: 5, Kind: sweep.End, Value: "s1"})
+	if _, ok := q.Position(); ok {
+		t.Fatal("expected no position before sweep")
+	}
+
+	var order []string
+	for q.Len() != 0 {
+		ev := q.Pop()
+		order = append(order, fmt.Sprint(ev.Value, "@", ev.At))
+		if ev.Value == "s1" && ev.Kind == sweep.Start {
+			if !q.Push(sweep.Event[float64, string]{At: 3, Kind: sweep.Cross, Value: "x"}) {
+				t.Fatal("event ahead of sweep line not added")
+			}
+			if q.Push(sweep.Event[float64, string]{At: 0.5, Kind: sweep.Cross, Value: "late"}) {
+				t.Fatal("event behind sweep line added")
+			}
+		}
+	}
+	if fmt.Sprint(order) != "[s1@1 x@3 s1@5]" {
+		t.Fatalf("wrong event order %v", order)
+	}
+	if pos, ok := q.Position(); !ok || pos != 5 {
+		t.Fatalf("wrong position %v", pos)
+	}
+}
+
+func TestKindOrder(t *testing.T) {
+	for _, kinds := range [][]sweep.Kind{
+		{sweep.Start, sweep.End},
+		{sweep.Start, sweep.Start, sweep.End},
+		{sweep.Start, sweep.Cross, sweep.Kind(7)},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected panic for kind order %v", kinds)
+				}
+			}()
+			sweep.New[int, int](kinds...)
+		}()
+	}
+}
+
+// Example computes the skyline of buildings: the height of the tallest
+// building at each point where the height changes.
+func Example() {
+	type building struct{ left, right, height int }
+	buildings := []building{{2, 9, 10}, {3, 7, 15}, {5, 12, 12}, {15, 20, 10}, {19, 24, 8}}
+
+	q := sweep.New[int, int](sweep.End, sweep.Cross, sweep.Start)
+	for _, b := range buildings {
+		q.Push(sweep.Event[int, int]{At: b.left, Kind: sweep.Start, Value: b.height})
+		q.Push(sweep.Event[int, int]{At: b.right, Kind: sweep.End, Value: b.height})
+	}
+	heights := map[int]int{}
+	last := 0
+	for q.Len() != 0 {
+		ev := q.Pop()
+		if ev.Kind == sweep.Start {
+			heights[ev.Value]++
+		} else if heights[ev.Value]--; heights[ev.Value] == 0 {
+			delete(heights, ev.Value)
+		}
+		// Report the height after all events at this coordinate.
+		if q.Len() != 0 && q.Peek().At == ev.At {
+			continue
+		}
+		top := 0
+		for h := range heights {
+			top = max(top, h)
+		}
+		if top != last {
+			fmt.Println(ev.At, top)
+			last = top
+		}
+	}
+
+	// Output:
+	// 2 10
+	// 3 15
+	// 7 12
+	// 12 0
+	// 15 10
+	// 20 8
+	// 24 0
+}
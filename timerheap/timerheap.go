@@ -0,0 +1,205 @@
+// Package timerheap provides a heap of values ordered by deadline, for
+// managing large numbers of timeouts with a single timer.
+package timerheap
+
+import (
+	"time"
+
+	"github.com/gammazero/heap"
+)
+
+// Scheduler is implemented by structures that hold values until a deadline.
+// Code written against Scheduler can switch between an exact TimerHeap and an
+// approximate structure such as a timing wheel.
+type Scheduler[T any] interface {
+	// Len returns the number of values held.
+	Len() int
+	// Add adds a value with the given deadline.
+	Add(x T, deadline time.Time)
+	// NextDeadline returns the earliest deadline of all values, and false if
+	// there are no values.
+	NextDeadline() (time.Time, bool)
+	// PopDue removes and returns a value that is due at now, and false if no
+	// value is due.
+	PopDue(now time.Time) (T, bool)
+	// PopAllDue removes and returns all values that are due at now.
+	PopAllDue(now time.Time) []T
+}
+
+var _ Scheduler[int] = (*TimerHeap[int])(nil)
+
+// TimerHeap holds values ordered by their deadlines. It is not safe for
+// concurrent use.
+type TimerHeap[T any] struct {
+	h     *heap.Heap[*entry[T]]
+	seq   uint64
+	timer *time.Timer
+	armed time.Time // deadline the timer is set for, zero if stopped
+}
+
+type entry[T any] struct {
+	val      T
+	at       time.Time
+	interval time.Duration
+	seq      uint64
+	pos      int
+}
+
+// Recurrence is a handle to a recurring value added by AddEvery.
+type Recurrence[T any] struct {
+	t *TimerHeap[T]
+	e *entry[T]
+}
+
+// New returns a new empty TimerHeap.
+func New[T any]() *TimerHeap[T] {
+	h := heap.New(func(a, b *entry[T]) bool {
+		if !a.at.Equal(b.at) {
+			return a.at.Before(b.at)
+		}
+		return a.seq < b.seq
+	})
+	h.SetOnMove(func(e *entry[T], i int) {
+		e.pos = i
+	})
+	return &TimerHeap[T]{h: h}
+}
+
+// Len returns the number of values in the TimerHeap.
+func (t *TimerHeap[T]) Len() int {
+	return t.h.Len()
+}
+
+// Add adds a value with the given deadline. Values with the same deadline are
+// removed in the order they were added.
+func (t *TimerHeap[T]) Add(x T, deadline time.Time) {
+	t.push(&entry[T]{val: x, at: deadline})
+}
+
+// AddEvery adds a value that is due first at the given time and then again
+// every interval after that. Each time the value is removed as due, it is
+// re-added for the first time in its schedule that is after now. The schedule
+// is computed from the first time, not from when it is removed, so it does
+// not drift; if the value is removed late enough that several due times were
+// missed, the value is returned once. The interval must be positive.
+func (t *TimerHeap[T]) AddEvery(x T, first time.Time, interval time.Duration) *Recurrence[T] {
+	if interval <= 0 {
+		panic("timerheap: non-positive interval for AddEvery")
+	}
+	e := &entry[T]{val: x, at: first, interval: interval}
+	t.push(e)
+	return &Recurrence[T]{t: t, e: e}
+}
+
+// Next returns the next time the recurring value is due.
+func (r *Recurrence[T]) Next() time.Time {
+	return r.e.at
+}
+
+// Cancel removes the recurring value from the TimerHeap. It returns false if
+// the value was already canceled.
+func (r *Recurrence[T]) Cancel() bool {
+	if r.e.pos < 0 {
+		return false
+	}
+	r.t.h.Remove(r.e.pos)
+	r.t.rearm()
+	return true
+}
+
+func (t *TimerHeap[T]) push(e *entry[T]) {
+	t.seq++
+	e.seq = t.seq
+	t.h.Push(e)
+	t.rearm()
+}
+
+// NextDeadline returns the earliest deadline of all values. The second return
+// value is false if the TimerHeap is empty.
+func (t *TimerHeap[T]) NextDeadline() (time.Time, bool) {
+	if t.h.Len() == 0 {
+		return time.Time{}, false
+	}
+	return t.h.Peek().at, true
+}
+
+// PopDue removes and returns the value with the earliest deadline, if that
+// deadline is not after now. The second return value is false if no value is
+// due.
+func (t *TimerHeap[T]) PopDue(now time.Time) (T, bool) {
+	x, ok := t.popDue(now)
+	t.fired(now)
+	return x, ok
+}
+
+// PopAllDue removes and returns all values whose deadline is not after now, in
+// deadline order. It returns nil if no values are due.
+func (t *TimerHeap[T]) PopAllDue(now time.Time) []T {
+	var due []T
+	for {
+		x, ok := t.popDue(now)
+		if !ok {
+			break
+		}
+		due = append(due, x)
+	}
+	t.fired(now)
+	return due
+}
+
+func (t *TimerHeap[T]) popDue(now time.Time) (T, bool) {
+	if t.h.Len() == 0 || t.h.Peek().at.After(now) {
+		var zero T
+		return zero, false
+	}
+	e := t.h.Pop()
+	if e.interval != 0 {
+		// The next time in the schedule is after now, so the value is not
+		// due again until a later call.
+		e.at = e.at.Add((now.Sub(e.at)/e.interval + 1) * e.interval)
+		t.seq++
+		e.seq = t.seq
+		t.h.Push(e)
+	}
+	return e.val, true
+}
+
+// fired re-arms the timer after values due at now have been removed.
+func (t *TimerHeap[T]) fired(now time.Time) {
+	if !t.armed.IsZero() && !t.armed.After(now) {
+		// The timer has fired for this deadline.
+		t.armed = time.Time{}
+	}
+	t.rearm()
+}
+
+// C returns a channel that receives the current time when the next deadline
+// arrives. After C is first called, the TimerHeap keeps an internal timer set
+// to the earliest deadline as values are added and removed. Call PopAllDue
+// with the received time to remove the values that are due.
+func (t *TimerHeap[T]) C() <-chan time.Time {
+	if t.timer == nil {
+		t.timer = time.NewTimer(time.Hour)
+		t.timer.Stop()
+		t.rearm()
+	}
+	return t.timer.C
+}
+
+func (t *TimerHeap[T]) rearm() {
+	if t.timer == nil {
+		return
+	}
+	next, ok := t.NextDeadline()
+	if !ok {
+		if !t.armed.IsZero() {
+			t.timer.Stop()
+			t.armed = time.Time{}
+		}
+		return
+	}
+	if !next.Equal(t.armed) {
+		t.timer.Reset(time.Until(next))
+		t.armed = next
+	}
+}
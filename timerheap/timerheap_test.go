@@ -0,0 +1,143 @@
+package timerheap_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gammazero/heap/timerheap"
+)
+
+func TestPopDue(t *testing.T) {
+	th := timerheap.New[string]()
+	if _, ok := th.NextDeadline(); ok {
+		t.Fatal("expected no deadline for empty heap")
+	}
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	th.Add("c", base.Add(3*time.Second))
+	th.Add("a", base.Add(1*time.Second))
+	th.Add("b1", base.Add(2*time.Second))
+	th.Add("b2", base.Add(2*time.Second))
+
+	next, ok := th.NextDeadline()
+	if !ok || !next.Equal(base.Add(time.Second)) {
+		t.Fatalf("unexpected next deadline %v", next)
+	}
+
+	if due := th.PopAllDue(base); due != nil {
+		t.Fatalf("expected nothing due, got %v", due)
+	}
+	if _, ok := th.PopDue(base); ok {
+		t.Fatal("expected nothing due")
+	}
+	x, ok := th.PopDue(base.Add(2 * time.Second))
+	if !ok || x != "a" {
+		t.Fatalf("expected a to be due, got %q", x)
+	}
+	due := th.PopAllDue(base.Add(2 * time.Second))
+	if fmt.Sprint(due) != "[b1 b2]" {
+		t.Fatalf("unexpected due values %v", due)
+	}
+	if th.Len() != 1 {
+		t.Fatalf("expected length 1, got %d", th.Len())
+	}
+	due = th.PopAllDue(base.Add(time.Hour))
+	if fmt.Sprint(due) != "[c]" {
+		t.Fatalf("unexpected due values %v", due)
+	}
+}
+
+func TestTimer(t *testing.T) {
+	th := timerheap.New[int]()
+	c := th.C()
+
+	start := time.Now()
+	th.Add(2, start.Add(40*time.Millisecond))
+	th.Add(1, start.Add(20*time.Millisecond))
+
+	var got []int
+	for th.Len() != 0 {
+		select {
+		case now := <-c:
+			got = append(got, th.PopAllDue(now)...)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for timer")
+		}
+	}
+	if fmt.Sprint(got) != "[1 2]" {
+		t.Fatalf("unexpected values %v", got)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Fatalf("timer fired too early, after %s", elapsed)
+	}
+
+	select {
+	case <-c:
+		t.Fatal("timer fired with empty heap")
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func Example() {
+	th := timerheap.New[string]()
+	now := time.Now()
+	th.Add("conn-2", now.Add(-time.Second))
+	th.Add("conn-1", now.Add(-2*time.Second))
+	th.Add("conn-3", now.Add(time.Minute))
+
+	for _, conn := range th.PopAllDue(now) {
+		fmt.Println("timed out:", conn)
+	}
+
+	// Output:
+	// timed out: conn-1
+	// timed out: conn-2
+}
+
+func TestAddEvery(t *testing.T) {
+	th := timerheap.New[string]()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	r := th.AddEvery("tick", base.Add(time.Second), 10*time.Second)
+	th.Add("once", base.Add(5*time.Second))
+
+	due := th.PopAllDue(base.Add(time.Second))
+	if fmt.Sprint(due) != "[tick]" {
+		t.Fatalf("unexpected due values %v", due)
+	}
+	if !r.Next().Equal(base.Add(11 * time.Second)) {
+		t.Fatalf("unexpected next time %v", r.Next())
+	}
+
+	// Popping late does not shift the schedule.
+	due = th.PopAllDue(base.Add(13 * time.Second))
+	if fmt.Sprint(due) != "[once tick]" {
+		t.Fatalf("unexpected due values %v", due)
+	}
+	if !r.Next().Equal(base.Add(21 * time.Second)) {
+		t.Fatalf("unexpected next time %v", r.Next())
+	}
+
+	// Missed due times are returned once.
+	due = th.PopAllDue(base.Add(55 * time.Second))
+	if fmt.Sprint(due) != "[tick]" {
+		t.Fatalf("unexpected due values %v", due)
+	}
+	if !r.Next().Equal(base.Add(61 * time.Second)) {
+		t.Fatalf("unexpected next time %v", r.Next())
+	}
+
+	if !r.Cancel() {
+		t.Fatal("expected cancel to succeed")
+	}
+	if r.Cancel() {
+		t.Fatal("should not cancel twice")
+	}
+	if th.Len() != 0 {
+		t.Fatalf("expected empty heap, got length %d", th.Len())
+	}
+	if due = th.PopAllDue(base.Add(time.Hour)); due != nil {
+		t.Fatalf("canceled value returned: %v", due)
+	}
+}
@@ -0,0 +1,223 @@
+// Package timingwheel provides a hierarchical timing wheel, which holds values
+// until their deadlines with O(1) insertion, at the cost of rounding deadlines
+// up to a fixed tick.
+//
+// TimingWheel implements [timerheap.Scheduler], so it can replace a
+// [timerheap.TimerHeap] for very large numbers of coarse-grained timeouts
+// without changing the code that uses it.
+//
+// The wheel has several levels of 64 slots each. Level 0 has one slot per
+// tick, and each slot of a higher level spans a full rotation of the level
+// below it. As time advances, the values in a higher-level slot are cascaded
+// down into the lower levels. Deadlines beyond the top level are kept in an
+// overflow list until they come within range.
+package timingwheel
+
+import (
+	"slices"
+	"time"
+
+	"github.com/gammazero/heap/timerheap"
+)
+
+const (
+	slotBits  = 6
+	numSlots  = 1 << slotBits
+	slotMask  = numSlots - 1
+	numLevels = 4
+)
+
+// TimingWheel holds values until their deadlines. A value is due at the first
+// tick boundary at or after its deadline, so it is never returned early and is
+// at most one tick late. It is not safe for concurrent use.
+type TimingWheel[T any] struct {
+	start    time.Time
+	tick     time.Duration
+	cur      int64 // last tick that has been processed
+	levels   [numLevels][numSlots][]entry[T]
+	counts   [numLevels]int
+	overflow []entry[T]
+	ready    []entry[T]
+	n        int
+}
+
+type entry[T any] struct {
+	val T
+	at  time.Time
+	t   int64 // tick at which the value is due
+}
+
+var _ timerheap.Scheduler[int] = (*TimingWheel[int])(nil)
+
+// New returns a new TimingWheel whose ticks start at start and are tick long.
+func New[T any](start time.Time, tick time.Duration) *TimingWheel[T] {
+	if tick <= 0 {
+		panic("timingwheel: non-positive tick")
+	}
+	return &TimingWheel[T]{
+		start: start,
+		tick:  tick,
+	}
+}
+
+// Len returns the number of values in the wheel.
+func (w *TimingWheel[T]) Len() int {
+	return w.n
+}
+
+// Add adds a value with the given deadline. The complexity is O(1).
+func (w *TimingWheel[T]) Add(x T, deadline time.Time) {
+	e := entry[T]{val: x, at: deadline}
+	d := deadline.Sub(w.start)
+	e.t = int64(d / w.tick)
+	if d%w.tick > 0 {
+		e.t++
+	}
+	w.n++
+	if e.t <= w.cur {
+		w.ready = append(w.ready, e)
+		return
+	}
+	w.place(e)
+}
+
+// NextDeadline returns the time at which the earliest value is due, which is
+// its deadline rounded up to a tick boundary, so that calling PopDue at that
+// time returns the value. The second return value is false if the wheel is
+// empty.
+func (w *TimingWheel[T]) NextDeadline() (time.Time, bool) {
+	if w.n == 0 {
+		return time.Time{}, false
+	}
+	if len(w.ready) != 0 {
+		return w.minDue(w.ready), true
+	}
+	for l := range numLevels {
+		if w.counts[l] == 0 {
+			continue
+		}
+		for slot := (w.cur>>(slotBits*l))&slotMask + 1; slot < numSlots; slot++ {
+			if es := w.levels[l][slot]; len(es) != 0 {
+				return w.minDue(es), true
+			}
+		}
+	}
+	return w.minDue(w.overflow), true
+}
+
+// PopDue removes and returns a value whose deadline tick has passed by now.
+// The second return value is false if no value is due.
+func (w *TimingWheel[T]) PopDue(now time.Time) (T, bool) {
+	w.advance(now)
+	if len(w.ready) == 0 {
+		var zero T
+		return zero, false
+	}
+	e := w.ready[0]
+	w.ready[0] = entry[T]{}
+	w.ready = w.ready[1:]
+	w.n--
+	return e.val, true
+}
+
+// PopAllDue removes and returns all values whose deadline ticks have passed by
+// now. Values are in deadline order, except for values that were added after
+// their deadline tick had already passed.
+func (w *TimingWheel[T]) PopAllDue(now time.Time) []T {
+	w.advance(now)
+	if len(w.ready) == 0 {
+		return nil
+	}
+	due := make([]T, len(w.ready))
+	for i, e := range w.ready {
+		due[i] = e.val
+	}
+	w.n -= len(w.ready)
+	w.ready = nil
+	return due
+}
+
+// place puts an entry due after the current tick into the lowest level that
+// can hold it.
+func (w *TimingWheel[T]) place(e entry[T]) {
+	for l := range numLevels {
+		if e.t>>(slotBits*(l+1)) == w.cur>>(slotBits*(l+1)) {
+			slot := (e.t >> (slotBits * l)) & slotMask
+			w.levels[l][slot] = append(w.levels[l][slot], e)
+			w.counts[l]++
+			return
+		}
+	}
+	w.overflow = append(w.overflow, e)
+}
+
+// advance processes all ticks up to the tick containing now, moving due
+// values to the ready list.
+func (w *TimingWheel[T]) advance(now time.Time) {
+	target := int64(now.Sub(w.start) / w.tick)
+	for w.cur < target {
+		if w.n == len(w.ready) {
+			w.cur = target
+			return
+		}
+		// Skip ahead over ticks where nothing happens. If the lowest
+		// non-empty level is l, then nothing happens until the next
+		// boundary of level l.
+		l := 0
+		for l < numLevels && w.counts[l] == 0 {
+			l++
+		}
+		if l != 0 {
+			boundary := (w.cur>>(slotBits*l) + 1) << (slotBits * l)
+			w.cur = min(boundary-1, target)
+			if w.cur == target {
+				return
+			}
+		}
+
+		w.cur++
+		// Find the highest level whose slot boundary was crossed, and
+		// cascade from there down so that values land in slots that have
+		// not been processed yet.
+		top := 0
+		for top < numLevels && w.cur&(1<<(slotBits*(top+1))-1) == 0 {
+			top++
+		}
+		if top == numLevels {
+			over := w.overflow
+			w.overflow = nil
+			for _, e := range over {
+				w.place(e)
+			}
+			top--
+		}
+		for lv := top; lv > 0; lv-- {
+			slot := (w.cur >> (slotBits * lv)) & slotMask
+			es := w.levels[lv][slot]
+			w.levels[lv][slot] = nil
+			w.counts[lv] -= len(es)
+			for _, e := range es {
+				w.place(e)
+			}
+		}
+		slot := w.cur & slotMask
+		if es := w.levels[0][slot]; len(es) != 0 {
+			slices.SortStableFunc(es, func(a, b entry[T]) int {
+				return a.at.Compare(b.at)
+			})
+			w.ready = append(w.ready, es...)
+			w.levels[0][slot] = nil
+			w.counts[0] -= len(es)
+		}
+	}
+}
+
+// minDue returns the earliest tick boundary at which any of the entries is
+// due.
+func (w *TimingWheel[T]) minDue(es []entry[T]) time.Time {
+	m := es[0].t
+	for _, e := range es[1:] {
+		m = min(m, e.t)
+	}
+	return w.start.Add(time.Duration(m) * w.tick)
+}
@@ -0,0 +1,115 @@
+package timingwheel_test
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/gammazero/heap/timerheap"
+	"github.com/gammazero/heap/timingwheel"
+)
+
+var start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestPopDue(t *testing.T) {
+	w := timingwheel.New[string](start, time.Second)
+	w.Add("b", start.Add(2500*time.Millisecond))
+	w.Add("a", start.Add(1500*time.Millisecond))
+	w.Add("c", start.Add(3*time.Second))
+
+	// The next deadline is rounded up to the tick at which a is due.
+	next, ok := w.NextDeadline()
+	if !ok || !next.Equal(start.Add(2*time.Second)) {
+		t.Fatalf("unexpected next deadline %v", next)
+	}
+	// Deadlines are rounded up to the next tick, never down.
+	if due := w.PopAllDue(start.Add(1900 * time.Millisecond)); due != nil {
+		t.Fatalf("expected nothing due, got %v", due)
+	}
+	x, ok := w.PopDue(start.Add(2 * time.Second))
+	if !ok || x != "a" {
+		t.Fatalf("expected a, got %q", x)
+	}
+	if due := w.PopAllDue(start.Add(3 * time.Second)); fmt.Sprint(due) != "[b c]" {
+		t.Fatalf("unexpected due values %v", due)
+	}
+	if _, ok := w.PopDue(start.Add(time.Hour)); ok {
+		t.Fatal("expected empty wheel")
+	}
+	if _, ok := w.NextDeadline(); ok {
+		t.Fatal("expected no deadline")
+	}
+
+	// Values added after their tick has passed are due immediately.
+	w.Add("late", start)
+	if due := w.PopAllDue(start.Add(time.Hour)); fmt.Sprint(due) != "[late]" {
+		t.Fatalf("unexpected due values %v", due)
+	}
+}
+
+func TestPopAtNextDeadline(t *testing.T) {
+	w := timingwheel.New[int](start, time.Second)
+	for i, ms := range []int{1500, 70_300, 5_000_000, 999} {
+		w.Add(i, start.Add(time.Duration(ms)*time.Millisecond))
+	}
+	// A timer-driven loop that waits until NextDeadline must find a value
+	// due each time it wakes.
+	for w.Len() != 0 {
+		next, _ := w.NextDeadline()
+		if _, ok := w.PopDue(next); !ok {
+			t.Fatalf("no value due at next deadline %v", next.Sub(start))
+		}
+	}
+}
+
+func TestMatchesTimerHeap(t *testing.T) {
+	const tick = time.Millisecond
+	schedulers := []timerheap.Scheduler[int]{
+		timerheap.New[int](),
+		timingwheel.New[int](start, tick),
+	}
+
+	// Deadlines are whole ticks spanning all wheel levels and the overflow,
+	// so both structures must release the same values at the same times.
+	rng := rand.New(rand.NewSource(1))
+	spans := []int64{10, 1 << 8, 1 << 14, 1 << 20, 1 << 26}
+	now := start
+	for round := range 200 {
+		for i := range 20 {
+			d := time.Duration(rng.Int63n(spans[rng.Intn(len(spans))])) * tick
+			x := round*100 + i
+			for _, s := range schedulers {
+				s.Add(x, now.Add(d))
+			}
+		}
+		now = now.Add(time.Duration(rng.Int63n(1<<18)) * tick)
+
+		var results [2][]int
+		for i, s := range schedulers {
+			results[i] = s.PopAllDue(now)
+			sort.Ints(results[i])
+		}
+		if fmt.Sprint(results[0]) != fmt.Sprint(results[1]) {
+			t.Fatalf("round %d: heap due %v, wheel due %v", round, results[0], results[1])
+		}
+		d0, ok0 := schedulers[0].NextDeadline()
+		d1, ok1 := schedulers[1].NextDeadline()
+		if ok0 != ok1 || !d0.Equal(d1) {
+			t.Fatalf("round %d: heap next %v, wheel next %v", round, d0, d1)
+		}
+		if schedulers[0].Len() != schedulers[1].Len() {
+			t.Fatalf("round %d: heap len %d, wheel len %d", round, schedulers[0].Len(), schedulers[1].Len())
+		}
+	}
+}
+
+func BenchmarkAdd(b *testing.B) {
+	w := timingwheel.New[int](start, time.Millisecond)
+	var i int
+	for b.Loop() {
+		w.Add(i, start.Add(time.Duration(i%100000)*time.Millisecond))
+		i++
+	}
+}
@@ -0,0 +1,111 @@
+package heap
+
+import "slices"
+
+// undoState holds the heap's elements as they were when a transaction began.
+type undoState[T any] struct {
+	saved       bool
+	data        []T
+	unordered   bool
+	lessChanged bool
+}
+
+// Begin starts a transaction. The changes made to the heap after Begin can be
+// undone together by Rollback, such as when a decision that takes several
+// elements from the heap fails part way through, or kept by Commit. Calls to
+// Begin cannot be nested.
+//
+// The heap's elements are copied when the heap is first changed after Begin,
+// so a transaction takes O(n) time and memory once, no matter how many
+// changes it makes. Begin itself takes O(1) time, and a transaction that
+// makes no changes copies nothing.
+func (h *Heap[T]) Begin() {
+	if h.undo != nil {
+		panic("heap: Begin called during transaction")
+	}
+	h.undo = &undoState[T]{}
+}
+
+// Commit ends a transaction, keeping the changes made since Begin.
+func (h *Heap[T]) Commit() {
+	if h.undo == nil {
+		panic("heap: Commit called without Begin")
+	}
+	h.undo = nil
+}
+
+// Rollback ends a transaction, undoing the changes made since Begin. The heap
+// holds the same elements, at the same indexes, as when Begin was called. The
+// onMove function is called with index -1 for each element in the heap before
+// the rollback, and then with its index for each restored element.
+//
+// Rollback does not undo a call to SetLess; the restored elements are
+// reordered by the current less function.
+func (h *Heap[T]) Rollback() {
+	u := h.undo
+	if u == nil {
+		panic("heap: Rollback called without Begin")
+	}
+	h.undo = nil
+	if !u.saved {
+		return
+	}
+	if h.guard != nil {
+		h.startWrite()
+	}
+	defer h.endOp()
+	if h.onMove != nil {
+		for _, x := range h.data {
+			h.onMove(x, -1)
+		}
+		for i, x := range u.data {
+			h.onMove(x, i)
+		}
+	}
+	h.modify()
+	h.data = u.data
+	h.unordered = u.unordered
+	if u.lessChanged {
+		h.heapify()
+	}
+	h.watermarksChanged()
+	if h.journal != nil {
+		h.recordOp(OpRollback, len(h.data))
+	}
+}
+
+// Atomic calls fn in a transaction. If fn returns an error or panics, the
+// changes made to the heap by fn are rolled back, and the error is returned
+// or the panic continues. Otherwise, the changes are committed.
+func (h *Heap[T]) Atomic(fn func() error) error {
+	h.Begin()
+	committed := false
+	defer func() {
+		if !committed {
+			h.Rollback()
+		}
+	}()
+	if err := fn(); err != nil {
+		return err
+	}
+	committed = true
+	h.Commit()
+	return nil
+}
+
+// modify is called before each change to the heap's elements. It increments
+// the heap's version, so that iterators can detect the change, copies the
+// elements' storage if it is shared with a Pages iterator, and saves the
+// elements for Rollback on the first change in a transaction.
+func (h *Heap[T]) modify() {
+	h.version++
+	if h.shared {
+		h.data = append(make([]T, 0, cap(h.data)), h.data...)
+		h.shared = false
+	}
+	if h.undo != nil && !h.undo.saved {
+		h.undo.saved = true
+		h.undo.data = slices.Clone(h.data)
+		h.undo.unordered = h.unordered
+	}
+}
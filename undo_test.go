@@ -0,0 +1,144 @@
+package heap_test
+
+import (
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/gammazero/heap"
+)
+
+func TestRollback(t *testing.T) {
+	h := heap.NewFrom(func(a, b int) bool { return a < b }, rand.Perm(50)...)
+	before := h.PeekN(h.Len())
+	layout := make([]int, h.Len())
+	for i := range layout {
+		layout[i] = h.At(i)
+	}
+
+	h.Begin()
+	h.Push(-1)
+	h.Pop()
+	h.Pop()
+	h.Remove(10)
+	h.Set(3, 100)
+	h.DeleteFunc(func(x int) bool { return x%3 == 0 })
+	h.Truncate(5)
+	h.Rollback()
+
+	if h.Len() != len(layout) {
+		t.Fatalf("heap has %d elements after rollback, expected %d", h.Len(), len(layout))
+	}
+	for i, x := range layout {
+		if h.At(i) != x {
+			t.Fatalf("element %d is %d after rollback, expected %d", i, h.At(i), x)
+		}
+	}
+	if !slices.Equal(h.PeekN(h.Len()), before) {
+		t.Fatal("wrong elements after rollback")
+	}
+
+	h.Begin()
+	h.Pop()
+	h.Commit()
+	if h.Peek() != 1 {
+		t.Fatalf("committed change lost, minimum is %d", h.Peek())
+	}
+
+	// A transaction without changes leaves the heap as it is.
+	h.Begin()
+	h.Rollback()
+	if h.Len() != 49 {
+		t.Fatalf("heap has %d elements", h.Len())
+	}
+
+	assertPanics(t, "Commit", h.Commit)
+	assertPanics(t, "Rollback", h.Rollback)
+	h.Begin()
+	assertPanics(t, "Begin", h.Begin)
+	h.Commit()
+}
+
+func TestRollbackOnMove(t *testing.T) {
+	type item struct {
+		val int
+		pos int
+	}
+	h := heap.New(func(a, b *item) bool { return a.val < b.val })
+	h.SetOnMove(func(x *item, i int) { x.pos = i })
+	items := make([]*item, 20)
+	for i := range items {
+		items[i] = &item{val: rand.IntN(100)}
+		h.Push(items[i])
+	}
+
+	added := &item{val: -1}
+	h.Begin()
+	h.Push(added)
+	for range 5 {
+		h.Pop()
+	}
+	h.Fix(h.Len() - 1)
+	h.Rollback()
+
+	if added.pos != -1 {
+		t.Fatalf("element pushed in rolled back transaction has index %d", added.pos)
+	}
+	for _, x := range items {
+		if h.At(x.pos) != x {
+			t.Fatalf("element %d has wrong index %d", x.val, x.pos)
+		}
+	}
+}
+
+func TestAtomic(t *testing.T) {
+	h := heap.NewFrom(func(a, b int) bool { return a < b }, 1, 2, 3, 4)
+	errFull := errors.New("no room")
+
+	err := h.Atomic(func() error {
+		h.Pop()
+		h.Pop()
+		return errFull
+	})
+	if err != errFull {
+		t.Fatalf("expected error, got %v", err)
+	}
+	if h.Len() != 4 || h.Peek() != 1 {
+		t.Fatal("changes not rolled back after error")
+	}
+
+	assertPanics(t, "Atomic", func() {
+		_ = h.Atomic(func() error {
+			h.Pop()
+			panic("failed")
+		})
+	})
+	if h.Len() != 4 || h.Peek() != 1 {
+		t.Fatal("changes not rolled back after panic")
+	}
+
+	if err = h.Atomic(func() error {
+		h.Pop()
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if h.Len() != 3 || h.Peek() != 2 {
+		t.Fatal("changes not committed")
+	}
+}
+
+func TestRollbackSetLess(t *testing.T) {
+	h := heap.NewFrom(func(a, b int) bool { return a < b }, rand.Perm(30)...)
+	h.Begin()
+	h.Pop()
+	h.SetLess(func(a, b int) bool { return a > b })
+	h.Rollback()
+	if h.Len() != 30 || h.Peek() != 29 {
+		t.Fatalf("wrong maximum %d after rollback", h.Peek())
+	}
+	if err := h.Verify(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,167 @@
+package heap
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// InvariantError is returned by Verify when an element is less than its
+// parent, which means the heap ordering has been broken. This happens when an
+// element is changed without calling Fix, or when the less function is not a
+// strict weak ordering, such as a less function that is not transitive.
+type InvariantError struct {
+	// Index is the index of the element that is less than its parent.
+	Index int
+	// Parent is the index of the parent.
+	Parent int
+}
+
+func (e *InvariantError) Error() string {
+	return fmt.Sprintf("heap: element at index %d is less than its parent at index %d", e.Index, e.Parent)
+}
+
+// Verify checks that the heap's elements are in heap order, and returns an
+// *InvariantError for the first element that is less than its parent. It
+// takes O(n) time. If the heap is keeping its elements unordered, because of
+// a scan threshold or lazy ordering, there is no ordering to check.
+func (h *Heap[T]) Verify() error {
+	if h.guard != nil {
+		h.checkRead()
+	}
+	if h.unordered {
+		return nil
+	}
+	h.mustHaveLess()
+	for i := 1; i < len(h.data); i++ {
+		if p := (i - 1) / 2; h.less(h.data[i], h.data[p]) {
+			return &InvariantError{Index: i, Parent: p}
+		}
+	}
+	return nil
+}
+
+// DebugHeap wraps a Heap and verifies the heap ordering after every operation
+// that modifies the heap. If the ordering is broken, it panics with a message
+// that describes the operation, the elements that are out of order, and the
+// elements that the operation moved. This finds the operation at which a
+// faulty less function, or an element changed without calling Fix, first
+// corrupts the heap, instead of much later when elements pop out of order.
+//
+// Verifying the heap takes O(n) time, so a DebugHeap is only suitable for
+// testing and debugging.
+type DebugHeap[T any] struct {
+	h *Heap[T]
+}
+
+// Debug returns a DebugHeap that wraps h. It panics if h is not in heap order.
+func Debug[T any](h *Heap[T]) *DebugHeap[T] {
+	d := &DebugHeap[T]{h: h}
+	d.check("Debug", nil)
+	return d
+}
+
+// Unwrap returns the wrapped heap.
+func (d *DebugHeap[T]) Unwrap() *Heap[T] {
+	return d.h
+}
+
+// Len returns the number of elements in the heap.
+func (d *DebugHeap[T]) Len() int {
+	return d.h.Len()
+}
+
+// Peek returns the minimum element in the heap.
+func (d *DebugHeap[T]) Peek() T {
+	return d.h.Peek()
+}
+
+// At returns the element at index i in the heap.
+func (d *DebugHeap[T]) At(i int) T {
+	return d.h.At(i)
+}
+
+// Push pushes an element onto the heap and verifies the heap.
+func (d *DebugHeap[T]) Push(x T) {
+	before := d.snapshot()
+	d.h.Push(x)
+	d.check(fmt.Sprintf("Push(%v)", x), before)
+}
+
+// Pop removes and returns the minimum element and verifies the heap.
+func (d *DebugHeap[T]) Pop() T {
+	before := d.snapshot()
+	x := d.h.Pop()
+	d.check("Pop()", before)
+	return x
+}
+
+// Remove removes and returns the element at index i and verifies the heap.
+func (d *DebugHeap[T]) Remove(i int) T {
+	before := d.snapshot()
+	x := d.h.Remove(i)
+	d.check(fmt.Sprintf("Remove(%d)", i), before)
+	return x
+}
+
+// Set replaces the element at index i and verifies the heap.
+func (d *DebugHeap[T]) Set(i int, x T) {
+	before := d.snapshot()
+	d.h.Set(i, x)
+	d.check(fmt.Sprintf("Set(%d, %v)", i, x), before)
+}
+
+// Fix re-establishes the heap ordering after the element at index i has
+// changed, and verifies the heap.
+func (d *DebugHeap[T]) Fix(i int) {
+	// The element has already been changed, so the heap is not checked
+	// before Fix.
+	before := d.snapshot()
+	d.h.Fix(i)
+	d.check(fmt.Sprintf("Fix(%d)", i), before)
+}
+
+// snapshot returns a copy of the heap's elements in their layout before an
+// operation, so that the elements moved by the operation can be reported.
+func (d *DebugHeap[T]) snapshot() []T {
+	d.h.ensureOrdered()
+	return slices.Clone(d.h.data)
+}
+
+// maxDiff limits the number of moved elements listed in a panic message.
+const maxDiff = 20
+
+// check panics if the heap is not in heap order after op.
+func (d *DebugHeap[T]) check(op string, before []T) {
+	err := d.h.Verify()
+	if err == nil {
+		return
+	}
+	ie := err.(*InvariantError)
+	data := d.h.data
+	var b strings.Builder
+	fmt.Fprintf(&b, "heap: invariant violated after %s: element %v at index %d is less than parent %v at index %d",
+		op, data[ie.Index], ie.Index, data[ie.Parent], ie.Parent)
+	if before != nil {
+		b.WriteString("\nelements moved by operation (index: before -> after):")
+		moved := 0
+		for i := range max(len(before), len(data)) {
+			var was, now string
+			if i < len(before) {
+				was = fmt.Sprint(before[i])
+			}
+			if i < len(data) {
+				now = fmt.Sprint(data[i])
+			}
+			if was == now {
+				continue
+			}
+			if moved++; moved > maxDiff {
+				b.WriteString("\n  ...")
+				break
+			}
+			fmt.Fprintf(&b, "\n  %d: %s -> %s", i, was, now)
+		}
+	}
+	panic(b.String())
+}
@@ -0,0 +1,79 @@
+package heap_test
+
+import (
+	"cmp"
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/gammazero/heap"
+)
+
+func TestVerify(t *testing.T) {
+	h := heap.NewFrom(cmp.Less[int], 5, 2, 8, 1, 9, 3)
+	if err := h.Verify(); err != nil {
+		t.Fatal(err)
+	}
+
+	type item struct{ pri int }
+	items := heap.NewFrom(func(a, b *item) bool { return a.pri < b.pri },
+		&item{1}, &item{2}, &item{3}, &item{4})
+	// Change an element without calling Fix.
+	items.At(3).pri = 0
+	var ie *heap.InvariantError
+	if err := items.Verify(); !errors.As(err, &ie) {
+		t.Fatalf("expected InvariantError, got %v", err)
+	}
+	if ie.Index != 3 || ie.Parent != 1 {
+		t.Fatalf("wrong indexes in error: %v", ie)
+	}
+}
+
+func TestDebug(t *testing.T) {
+	d := heap.Debug(heap.New(cmp.Less[int]))
+	for _, x := range []int{5, 2, 8, 1, 9, 3} {
+		d.Push(x)
+	}
+	d.Set(2, 0)
+	d.Remove(1)
+	if x := d.Pop(); x != 0 {
+		t.Fatalf("expected 0, got %d", x)
+	}
+	if d.Len() != 4 || d.Unwrap().Len() != 4 || d.At(0) != d.Peek() {
+		t.Fatal("wrong state of wrapped heap")
+	}
+}
+
+func TestDebugBadLess(t *testing.T) {
+	// This less function is not transitive for values more than 10 apart,
+	// which corrupts the heap.
+	bad := func(a, b int) bool {
+		if a-b > 10 || b-a > 10 {
+			return a > b
+		}
+		return a < b
+	}
+	d := heap.Debug(heap.New(bad))
+	var msg string
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				msg = r.(string)
+			}
+		}()
+		r := rand.New(rand.NewSource(1))
+		for range 1000 {
+			d.Push(r.Intn(50))
+			if r.Intn(3) == 0 {
+				d.Pop()
+			}
+		}
+	}()
+	if msg == "" {
+		t.Fatal("expected panic from non-transitive less function")
+	}
+	if !strings.Contains(msg, "invariant violated after") || !strings.Contains(msg, "->") {
+		t.Fatalf("panic message missing details: %s", msg)
+	}
+}
@@ -0,0 +1,355 @@
+// Package wal adds a write-ahead log to a heap, so that the heap can be
+// recovered after a crash.
+//
+// Every operation that modifies the heap is written to the log before the heap
+// is modified. After a crash, Replay restores the heap from the last
+// checkpoint and re-applies the operations in the log. Heap operations are
+// deterministic, so replaying the same operations on the same heap produces
+// the same heap.
+//
+// Each record in the log holds its length and a checksum, so that a record
+// left partially written by a crash is detected. Removed elements are logged
+// by value rather than by index, so that replay does not depend on the layout
+// of the heap.
+//
+// The log grows until Checkpoint is called, which is never done
+// automatically. Call Checkpoint periodically, such as after a number of
+// records or when the log reaches a size, to bound the size of the log and the
+// time taken to replay it.
+package wal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+
+	"github.com/gammazero/heap"
+)
+
+// ErrCorrupt is returned by Replay when a log or checkpoint cannot be
+// replayed.
+var ErrCorrupt = errors.New("wal: corrupt log")
+
+const (
+	opPush byte = iota + 1
+	opPop
+	opRemove
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// Log is a heap whose modifications are written to a log. It is not safe for
+// concurrent use.
+//
+// Once a write to the log fails, the log may end with part of a record, so
+// every later modification fails with the same error. To continue, replay the
+// log and truncate it to the offset returned by Replay.
+type Log[T any] struct {
+	h   *heap.Heap[T]
+	w   io.Writer
+	enc func(io.Writer, T) error
+	buf bytes.Buffer
+	rec []byte
+	seq uint64
+	err error
+}
+
+// New returns a Log that writes the modifications of h to w, encoding
+// elements with enc. The seq argument is the sequence number of the last
+// record already written to w, as returned by Replay, or 0 for a new log. When
+// resuming a log after Replay, w must be positioned at the end offset returned
+// by Replay, with anything after it truncated. The heap must only be modified
+// through the Log.
+func New[T any](h *heap.Heap[T], w io.Writer, enc func(io.Writer, T) error, seq uint64) *Log[T] {
+	return &Log[T]{
+		h:   h,
+		w:   w,
+		enc: enc,
+		seq: seq,
+	}
+}
+
+// Heap returns the heap, which must not be modified except through the Log.
+func (l *Log[T]) Heap() *heap.Heap[T] {
+	return l.h
+}
+
+// Len returns the number of elements in the heap.
+func (l *Log[T]) Len() int {
+	return l.h.Len()
+}
+
+// Err returns the error of the write that failed, if any.
+func (l *Log[T]) Err() error {
+	return l.err
+}
+
+// Seq returns the sequence number of the last record written to the log.
+func (l *Log[T]) Seq() uint64 {
+	return l.seq
+}
+
+// Push logs the push of an element and adds it to the heap. If the record
+// cannot be written, the heap is not modified.
+func (l *Log[T]) Push(x T) error {
+	l.begin(opPush)
+	if err := l.enc(&l.buf, x); err != nil {
+		return err
+	}
+	if err := l.commit(); err != nil {
+		return err
+	}
+	l.h.Push(x)
+	return nil
+}
+
+// Pop logs the removal of the minimum element and removes it from the heap.
+// If the record cannot be written, the heap is not modified.
+func (l *Log[T]) Pop() (T, error) {
+	if l.h.Len() == 0 {
+		panic("wal: Pop called on empty heap")
+	}
+	l.begin(opPop)
+	if err := l.commit(); err != nil {
+		var zero T
+		return zero, err
+	}
+	return l.h.Pop(), nil
+}
+
+// Remove logs the removal of the element at index i and removes it from the
+// heap. The element itself is logged, so Replay removes an equal element
+// wherever it is in the heap. If the record cannot be written, the heap is not
+// modified.
+func (l *Log[T]) Remove(i int) (T, error) {
+	if i < 0 || i >= l.h.Len() {
+		panic("wal: Remove index out of range")
+	}
+	l.begin(opRemove)
+	if err := l.enc(&l.buf, l.h.At(i)); err != nil {
+		var zero T
+		return zero, err
+	}
+	if err := l.commit(); err != nil {
+		var zero T
+		return zero, err
+	}
+	return l.h.Remove(i), nil
+}
+
+// Checkpoint writes a snapshot of the heap to snap, so that the records
+// already in the log are no longer needed to recover the heap. If the log
+// writer has Truncate and Seek methods, as *os.File does, the log is then
+// truncated.
+//
+// Replay skips log records that are already included in the checkpoint, so a
+// crash between writing the checkpoint and truncating the log is safe. To be
+// safe from a crash while writing the checkpoint, write it to a temporary
+// file and rename it over the previous checkpoint.
+func (l *Log[T]) Checkpoint(snap io.Writer) error {
+	var seq [8]byte
+	binary.BigEndian.PutUint64(seq[:], l.seq)
+	if _, err := snap.Write(seq[:]); err != nil {
+		return err
+	}
+	if err := l.h.Save(snap, l.enc); err != nil {
+		return err
+	}
+	t, ok := l.w.(interface {
+		Truncate(int64) error
+		Seek(int64, int) (int64, error)
+	})
+	if !ok {
+		return nil
+	}
+	if err := t.Truncate(0); err != nil {
+		return err
+	}
+	_, err := t.Seek(0, io.SeekStart)
+	return err
+}
+
+func (l *Log[T]) begin(op byte) {
+	l.buf.Reset()
+	l.buf.Write(binary.AppendUvarint(nil, l.seq+1))
+	l.buf.WriteByte(op)
+}
+
+// commit writes the record in buf to the log with a single write, preceded by
+// its length and followed by its checksum.
+//
+// A failed write may leave part of the record in the log, and a record
+// written after it could not be replayed. So the first write error is kept,
+// and returned for every later record without writing it.
+func (l *Log[T]) commit() error {
+	if l.err != nil {
+		return l.err
+	}
+	data := l.buf.Bytes()
+	l.rec = binary.AppendUvarint(l.rec[:0], uint64(len(data)))
+	l.rec = append(l.rec, data...)
+	l.rec = binary.BigEndian.AppendUint32(l.rec, crc32.Checksum(data, crcTable))
+	if _, err := l.w.Write(l.rec); err != nil {
+		l.err = err
+		return err
+	}
+	l.seq++
+	return nil
+}
+
+// Replay restores h from the checkpoint read from snap and then applies the
+// records read from log, decoding elements with dec. If there is no
+// checkpoint, snap may be nil and the records are applied to h as it is. A
+// removed element is found in h by comparing elements with equal.
+//
+// Replay returns the sequence number of the last record applied, and the
+// offset in log just past the last complete record. A partially written record
+// at the end of the log, left by a crash, is ignored. Before resuming the log
+// by passing the sequence number to New, truncate the log to the returned
+// offset, so that new records do not follow the partial record.
+//
+// If a record that is not at the end of the log does not match its checksum,
+// or a record cannot be applied, Replay returns ErrCorrupt along with the
+// sequence number and offset of the last record applied.
+func Replay[T any](h *heap.Heap[T], snap, log io.Reader, dec func(io.Reader) (T, error), equal func(a, b T) bool) (uint64, int64, error) {
+	var seq uint64
+	if snap != nil {
+		var b [8]byte
+		if _, err := io.ReadFull(snap, b[:]); err != nil {
+			return 0, 0, err
+		}
+		seq = binary.BigEndian.Uint64(b[:])
+		if err := h.Load(snap, dec); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	r := bufio.NewReader(log)
+	var off int64
+	var buf bytes.Buffer
+	for {
+		data, n, err := readRecord(r, &buf)
+		if err != nil {
+			if err == io.EOF {
+				return seq, off, nil
+			}
+			return seq, off, err
+		}
+		rseq, op, body, err := parseRecord(data)
+		if err != nil {
+			return seq, off, err
+		}
+		if rseq <= seq {
+			// Already included in the checkpoint.
+			off += n
+			continue
+		}
+		if rseq != seq+1 {
+			return seq, off, ErrCorrupt
+		}
+		switch op {
+		case opPush:
+			x, err := decodeAll(body, dec)
+			if err != nil {
+				return seq, off, err
+			}
+			h.Push(x)
+		case opPop:
+			if len(body) != 0 || h.Len() == 0 {
+				return seq, off, ErrCorrupt
+			}
+			h.Pop()
+		case opRemove:
+			x, err := decodeAll(body, dec)
+			if err != nil {
+				return seq, off, err
+			}
+			i := index(h, x, equal)
+			if i < 0 {
+				return seq, off, ErrCorrupt
+			}
+			h.Remove(i)
+		default:
+			return seq, off, ErrCorrupt
+		}
+		seq = rseq
+		off += n
+	}
+}
+
+// readRecord reads the next record from r into buf, verifies its checksum,
+// and returns its data and the number of bytes it took in the log. It returns
+// io.EOF at the end of the log, including when the log ends with a partially
+// written record, and ErrCorrupt if a record before the end of the log is
+// damaged.
+func readRecord(r *bufio.Reader, buf *bytes.Buffer) ([]byte, int64, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, 0, io.EOF
+		}
+		return nil, 0, atEnd(r)
+	}
+	buf.Reset()
+	// Copying, rather than allocating size bytes up front, avoids a huge
+	// allocation for a damaged length.
+	if _, err = io.CopyN(buf, r, int64(size)+4); err != nil {
+		if err == io.EOF {
+			return nil, 0, io.EOF
+		}
+		return nil, 0, err
+	}
+	b := buf.Bytes()
+	data := b[:size]
+	if crc32.Checksum(data, crcTable) != binary.BigEndian.Uint32(b[size:]) {
+		return nil, 0, atEnd(r)
+	}
+	return data, int64(uvarintLen(size)) + int64(size) + 4, nil
+}
+
+// atEnd returns io.EOF if r has no more data, so that a damaged record at the
+// end of the log is taken to be partially written, and ErrCorrupt otherwise.
+func atEnd(r *bufio.Reader) error {
+	if _, err := r.Peek(1); err == io.EOF {
+		return io.EOF
+	}
+	return ErrCorrupt
+}
+
+// parseRecord returns the sequence number, operation, and body of a record.
+func parseRecord(data []byte) (uint64, byte, []byte, error) {
+	seq, n := binary.Uvarint(data)
+	if n <= 0 || n >= len(data) {
+		return 0, 0, nil, ErrCorrupt
+	}
+	return seq, data[n], data[n+1:], nil
+}
+
+// decodeAll decodes an element that must take all of body.
+func decodeAll[T any](body []byte, dec func(io.Reader) (T, error)) (T, error) {
+	r := bytes.NewReader(body)
+	x, err := dec(r)
+	if err != nil || r.Len() != 0 {
+		var zero T
+		return zero, ErrCorrupt
+	}
+	return x, nil
+}
+
+// index returns the index of an element of h equal to x, or -1.
+func index[T any](h *heap.Heap[T], x T, equal func(a, b T) bool) int {
+	for i := range h.Len() {
+		if equal(h.At(i), x) {
+			return i
+		}
+	}
+	return -1
+}
+
+func uvarintLen(x uint64) int {
+	var b [binary.MaxVarintLen64]byte
+	return binary.PutUvarint(b[:], x)
+}
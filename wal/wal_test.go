@@ -0,0 +1,379 @@
+package wal_test
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/gammazero/heap"
+	"github.com/gammazero/heap/wal"
+)
+
+func encodeInt(w io.Writer, x int) error {
+	return binary.Write(w, binary.BigEndian, int64(x))
+}
+
+func decodeInt(r io.Reader) (int, error) {
+	var x int64
+	err := binary.Read(r, binary.BigEndian, &x)
+	return int(x), err
+}
+
+func equalInt(a, b int) bool {
+	return a == b
+}
+
+func contents(h *heap.Heap[int]) []int {
+	out := make([]int, h.Len())
+	for i := range out {
+		out[i] = h.At(i)
+	}
+	return out
+}
+
+func TestReplay(t *testing.T) {
+	var logBuf bytes.Buffer
+	l := wal.New(heap.New(cmp.Less[int]), &logBuf, encodeInt, 0)
+	for _, x := range []int{5, 3, 8, 1, 9, 2} {
+		if err := l.Push(x); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if x, err := l.Pop(); err != nil || x != 1 {
+		t.Fatalf("expected 1, got %d, %v", x, err)
+	}
+	if _, err := l.Remove(2); err != nil {
+		t.Fatal(err)
+	}
+	if l.Seq() != 8 {
+		t.Fatalf("expected seq 8, got %d", l.Seq())
+	}
+
+	// Simulate a crash part way through writing a record.
+	log := append(bytes.Clone(logBuf.Bytes()), 11, 9, 1, 0, 0)
+
+	h := heap.New(cmp.Less[int])
+	seq, end, err := wal.Replay(h, nil, bytes.NewReader(log), decodeInt, equalInt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seq != 8 {
+		t.Fatalf("expected seq 8, got %d", seq)
+	}
+	if end != int64(logBuf.Len()) {
+		t.Fatalf("expected end offset %d, got %d", logBuf.Len(), end)
+	}
+	if !slices.Equal(contents(h), contents(l.Heap())) {
+		t.Fatalf("replayed heap %v does not match %v", contents(h), contents(l.Heap()))
+	}
+}
+
+func TestReplayDifferentLayout(t *testing.T) {
+	// Replaying into a heap with a different layout removes the same
+	// elements.
+	var logBuf bytes.Buffer
+	l := wal.New(heap.New(cmp.Less[int], heap.WithScanThreshold[int](8)), &logBuf, encodeInt, 0)
+	for _, x := range []int{5, 3, 8, 1, 9, 2} {
+		if err := l.Push(x); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := l.Remove(1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Remove(3); err != nil {
+		t.Fatal(err)
+	}
+
+	h := heap.New(cmp.Less[int])
+	if _, _, err := wal.Replay(h, nil, &logBuf, decodeInt, equalInt); err != nil {
+		t.Fatal(err)
+	}
+	got, want := contents(h), contents(l.Heap())
+	slices.Sort(got)
+	slices.Sort(want)
+	if !slices.Equal(got, want) {
+		t.Fatalf("replayed heap %v does not match %v", got, want)
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	f, err := os.OpenFile(filepath.Join(dir, "log"), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	l := wal.New(heap.New(cmp.Less[int]), f, encodeInt, 0)
+	for i := range 10 {
+		if err := l.Push(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var snap bytes.Buffer
+	if err = l.Checkpoint(&snap); err != nil {
+		t.Fatal(err)
+	}
+	if fi, _ := f.Stat(); fi.Size() != 0 {
+		t.Fatalf("log not truncated, size %d", fi.Size())
+	}
+	if _, err := l.Pop(); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Push(42); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	h := heap.New(cmp.Less[int])
+	seq, _, err := wal.Replay(h, bytes.NewReader(snap.Bytes()), f, decodeInt, equalInt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seq != l.Seq() {
+		t.Fatalf("expected seq %d, got %d", l.Seq(), seq)
+	}
+	if !slices.Equal(contents(h), contents(l.Heap())) {
+		t.Fatalf("replayed heap %v does not match %v", contents(h), contents(l.Heap()))
+	}
+}
+
+func TestCheckpointNotTruncated(t *testing.T) {
+	// If the log was not truncated after a checkpoint, records already in the
+	// checkpoint are skipped.
+	var logBuf bytes.Buffer
+	l := wal.New(heap.New(cmp.Less[int]), &logBuf, encodeInt, 0)
+	if err := l.Push(3); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Push(1); err != nil {
+		t.Fatal(err)
+	}
+	var snap bytes.Buffer
+	if err := l.Checkpoint(&snap); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Pop(); err != nil {
+		t.Fatal(err)
+	}
+
+	h := heap.New(cmp.Less[int])
+	if _, _, err := wal.Replay(h, &snap, &logBuf, decodeInt, equalInt); err != nil {
+		t.Fatal(err)
+	}
+	if h.Len() != 1 || h.Peek() != 3 {
+		t.Fatalf("wrong heap after replay: %v", contents(h))
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	errWrite := errors.New("write failed")
+	l := wal.New(heap.New(cmp.Less[int]), failWriter{errWrite}, encodeInt, 0)
+	if err := l.Push(1); err != errWrite {
+		t.Fatalf("expected write error, got %v", err)
+	}
+	if l.Len() != 0 || l.Seq() != 0 {
+		t.Fatal("heap modified by failed push")
+	}
+}
+
+// record returns a log record holding the given payload.
+func record(payload ...byte) []byte {
+	b := binary.AppendUvarint(nil, uint64(len(payload)))
+	b = append(b, payload...)
+	return binary.BigEndian.AppendUint32(b, crc32.Checksum(payload, crc32.MakeTable(crc32.Castagnoli)))
+}
+
+func TestReplayCorrupt(t *testing.T) {
+	h := heap.New(cmp.Less[int])
+	// Record 1 pops from an empty heap.
+	if _, _, err := wal.Replay(h, nil, bytes.NewReader(record(1, 2)), decodeInt, equalInt); err != wal.ErrCorrupt {
+		t.Fatalf("expected ErrCorrupt, got %v", err)
+	}
+	if _, _, err := wal.Replay(h, nil, bytes.NewReader(record(1, 99)), decodeInt, equalInt); err != wal.ErrCorrupt {
+		t.Fatalf("expected ErrCorrupt for unknown op, got %v", err)
+	}
+
+	var logBuf bytes.Buffer
+	l := wal.New(heap.New(cmp.Less[int]), &logBuf, encodeInt, 0)
+	for i := range 3 {
+		if err := l.Push(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	log := logBuf.Bytes()
+	n := len(log) / 3
+
+	// A damaged record before the end of the log is reported.
+	bad := bytes.Clone(log)
+	bad[n+5] ^= 0x01
+	seq, end, err := wal.Replay(heap.New(cmp.Less[int]), nil, bytes.NewReader(bad), decodeInt, equalInt)
+	if err != wal.ErrCorrupt {
+		t.Fatalf("expected ErrCorrupt, got %v", err)
+	}
+	if seq != 1 || end != int64(n) {
+		t.Fatalf("expected seq 1 at offset %d, got seq %d at offset %d", n, seq, end)
+	}
+
+	// A damaged last record is taken to be partially written.
+	bad = bytes.Clone(log)
+	bad[len(bad)-1] ^= 0x01
+	seq, end, err = wal.Replay(heap.New(cmp.Less[int]), nil, bytes.NewReader(bad), decodeInt, equalInt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seq != 2 || end != int64(2*n) {
+		t.Fatalf("expected seq 2 at offset %d, got seq %d at offset %d", 2*n, seq, end)
+	}
+
+	// Removing an element that is not in the heap.
+	rm := record(append([]byte{4, 3}, 0, 0, 0, 0, 0, 0, 0, 9)...)
+	_, _, err = wal.Replay(heap.New(cmp.Less[int]), nil, bytes.NewReader(append(bytes.Clone(log), rm...)), decodeInt, equalInt)
+	if err != wal.ErrCorrupt {
+		t.Fatalf("expected ErrCorrupt, got %v", err)
+	}
+}
+
+func TestResumeAfterCrash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := wal.New(heap.New(cmp.Less[int]), f, encodeInt, 0)
+	for _, x := range []int{4, 7, 1, 9} {
+		if err := l.Push(x); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := l.Remove(3); err != nil {
+		t.Fatal(err)
+	}
+	// Crash part way through writing a record.
+	if _, err = f.Write([]byte{11, 2, 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err = f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Recover, truncate the partial record, and resume logging.
+	f, err = os.OpenFile(path, os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			t.Error(err)
+		}
+	}()
+	h := heap.New(cmp.Less[int])
+	seq, end, err := wal.Replay(h, nil, f, decodeInt, equalInt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = f.Truncate(end); err != nil {
+		t.Fatal(err)
+	}
+	l2 := wal.New(h, f, encodeInt, seq)
+	if err := l2.Push(3); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l2.Pop(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The records written after resuming are replayed.
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	h2 := heap.New(cmp.Less[int])
+	seq, _, err = wal.Replay(h2, nil, f, decodeInt, equalInt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seq != l2.Seq() {
+		t.Fatalf("expected seq %d, got %d", l2.Seq(), seq)
+	}
+	if !slices.Equal(contents(h2), contents(l2.Heap())) {
+		t.Fatalf("replayed heap %v does not match %v", contents(h2), contents(l2.Heap()))
+	}
+}
+
+func TestPartialWrite(t *testing.T) {
+	var logBuf bytes.Buffer
+	w := &partialWriter{w: &logBuf, n: 3}
+	l := wal.New(heap.New(cmp.Less[int]), w, encodeInt, 0)
+	if err := l.Push(1); err != nil {
+		t.Fatal(err)
+	}
+	good := logBuf.Len()
+	// The next write is cut short, leaving part of a record in the log.
+	if err := l.Push(2); err != errShortWrite {
+		t.Fatalf("expected short write error, got %v", err)
+	}
+	// Later writes fail without writing after the partial record.
+	w.n = 100
+	if err := l.Push(3); err != errShortWrite {
+		t.Fatalf("expected sticky write error, got %v", err)
+	}
+	if _, err := l.Pop(); err != errShortWrite {
+		t.Fatalf("expected sticky write error, got %v", err)
+	}
+	if l.Err() != errShortWrite || l.Len() != 1 || l.Seq() != 1 {
+		t.Fatal("log modified after failed write")
+	}
+
+	h := heap.New(cmp.Less[int])
+	seq, end, err := wal.Replay(h, nil, &logBuf, decodeInt, equalInt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seq != 1 || end != int64(good) || h.Len() != 1 {
+		t.Fatalf("expected 1 record ending at %d, got seq %d ending at %d", good, seq, end)
+	}
+}
+
+var errShortWrite = errors.New("short write")
+
+// partialWriter passes its first write through. Of each later write longer
+// than n bytes, it writes only the first n bytes and returns an error.
+type partialWriter struct {
+	w      io.Writer
+	n      int
+	writes int
+}
+
+func (w *partialWriter) Write(p []byte) (int, error) {
+	w.writes++
+	if w.writes == 1 {
+		return w.w.Write(p)
+	}
+	if len(p) > w.n {
+		n, _ := w.w.Write(p[:w.n])
+		return n, errShortWrite
+	}
+	return w.w.Write(p)
+}
+
+type failWriter struct {
+	err error
+}
+
+func (w failWriter) Write([]byte) (int, error) {
+	return 0, w.err
+}
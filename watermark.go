@@ -0,0 +1,51 @@
+package heap
+
+type watermarks struct {
+	low, high int
+	above     bool
+	fn        func(above bool)
+}
+
+// SetWatermarks sets a function that is called when the number of elements in
+// the heap rises to the high watermark, and then again when it falls to the
+// low watermark. The function is called with true when the high watermark is
+// reached, and false when the low watermark is reached. Setting fn to nil
+// removes the watermarks.
+//
+// The watermarks are only signaled alternately, so the length of the heap
+// moving back and forth across one watermark does not call the function
+// repeatedly. If the heap already holds at least high elements, then fn is
+// called immediately with true. Low must be less than high.
+//
+// The function is called after the operation that moved the heap's length
+// across a watermark completes, so it may use the heap, such as to pop
+// elements when the high watermark is reached.
+func (h *Heap[T]) SetWatermarks(low, high int, fn func(above bool)) {
+	if fn == nil {
+		h.wm = nil
+		return
+	}
+	if low >= high {
+		panic("heap: low watermark must be less than high watermark")
+	}
+	h.wm = &watermarks{
+		low:  low,
+		high: high,
+		fn:   fn,
+	}
+	h.checkWatermarks()
+}
+
+func (h *Heap[T]) checkWatermarks() {
+	wm := h.wm
+	n := len(h.data)
+	if wm.above {
+		if n <= wm.low {
+			wm.above = false
+			wm.fn(false)
+		}
+	} else if n >= wm.high {
+		wm.above = true
+		wm.fn(true)
+	}
+}
@@ -0,0 +1,63 @@
+package heap_test
+
+import (
+	"cmp"
+	"fmt"
+	"testing"
+
+	"github.com/gammazero/heap"
+)
+
+func TestWatermarks(t *testing.T) {
+	h := heap.New(cmp.Less[int])
+	var signals []bool
+	h.SetWatermarks(2, 5, func(above bool) {
+		signals = append(signals, above)
+	})
+
+	for i := range 6 {
+		h.Push(i)
+	}
+	if fmt.Sprint(signals) != "[true]" {
+		t.Fatalf("expected high signal once, got %v", signals)
+	}
+
+	// Dropping below high and rising again does not signal.
+	h.Pop()
+	h.Pop()
+	h.Push(10)
+	h.Remove(h.Len() - 1)
+	h.Pop()
+	if fmt.Sprint(signals) != "[true]" {
+		t.Fatalf("unexpected signals %v", signals)
+	}
+	h.Pop()
+	if fmt.Sprint(signals) != "[true false]" {
+		t.Fatalf("expected low signal, got %v", signals)
+	}
+	h.Pop()
+	h.Pop()
+	if len(signals) != 2 {
+		t.Fatalf("unexpected signals %v", signals)
+	}
+
+	h.SetWatermarks(0, 1, nil)
+	for i := range 10 {
+		h.Push(i)
+	}
+	if len(signals) != 2 {
+		t.Fatalf("unexpected signals after removing watermarks %v", signals)
+	}
+
+	// Setting watermarks on a heap that is already above signals at once.
+	h.SetWatermarks(1, 3, func(above bool) {
+		signals = append(signals, above)
+	})
+	if fmt.Sprint(signals) != "[true false true]" {
+		t.Fatalf("expected immediate high signal, got %v", signals)
+	}
+
+	assertPanics(t, "should panic when low is not below high", func() {
+		h.SetWatermarks(3, 3, func(bool) {})
+	})
+}
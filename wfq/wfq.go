@@ -0,0 +1,100 @@
+// Package wfq provides a weighted fair queue that shares service among
+// classes of elements in proportion to configured weights.
+//
+// Each element is stamped with a virtual finish time when it is pushed, and
+// elements are removed in order of virtual finish time using a heap. A class
+// with twice the weight of another receives twice the share of service while
+// both have elements waiting, and no class with waiting elements is starved.
+// Virtual time advances to the finish time of each removed element, as in
+// self-clocked fair queuing.
+package wfq
+
+import "github.com/gammazero/heap"
+
+// Queue is a weighted fair queue of elements of type T belonging to classes
+// identified by K. It is not safe for concurrent use.
+type Queue[K comparable, T any] struct {
+	h       *heap.Heap[entry[K, T]]
+	weights map[K]float64
+	classes map[K]*class
+	vtime   float64
+	seq     uint64
+}
+
+type class struct {
+	finish  float64 // virtual finish time of the class's last element
+	pending int
+}
+
+type entry[K comparable, T any] struct {
+	class  K
+	val    T
+	finish float64
+	seq    uint64
+}
+
+// New returns a new empty Queue.
+func New[K comparable, T any]() *Queue[K, T] {
+	return &Queue[K, T]{
+		h: heap.New(func(a, b entry[K, T]) bool {
+			if a.finish != b.finish {
+				return a.finish < b.finish
+			}
+			return a.seq < b.seq
+		}),
+		weights: make(map[K]float64),
+		classes: make(map[K]*class),
+	}
+}
+
+// SetWeight sets the weight of a class. Classes that have no weight set have a
+// weight of 1. The new weight applies to elements pushed after it is set.
+func (q *Queue[K, T]) SetWeight(c K, weight float64) {
+	if !(weight > 0) {
+		panic("wfq: weight must be positive")
+	}
+	q.weights[c] = weight
+}
+
+// Len returns the number of elements in the queue.
+func (q *Queue[K, T]) Len() int {
+	return q.h.Len()
+}
+
+// Push adds an element to the given class. The cost is the amount of service
+// the element requires, such as its size in bytes, and must be positive.
+func (q *Queue[K, T]) Push(c K, x T, cost float64) {
+	if !(cost > 0) {
+		panic("wfq: cost must be positive")
+	}
+	w, ok := q.weights[c]
+	if !ok {
+		w = 1
+	}
+	cl := q.classes[c]
+	if cl == nil {
+		cl = &class{finish: q.vtime}
+		q.classes[c] = cl
+	}
+	cl.finish = max(cl.finish, q.vtime) + cost/w
+	cl.pending++
+	q.seq++
+	q.h.Push(entry[K, T]{class: c, val: x, finish: cl.finish, seq: q.seq})
+}
+
+// Pop removes and returns the next element to be served and its class.
+func (q *Queue[K, T]) Pop() (K, T) {
+	if q.h.Len() == 0 {
+		panic("wfq: Pop called on empty queue")
+	}
+	e := q.h.Pop()
+	q.vtime = e.finish
+	cl := q.classes[e.class]
+	cl.pending--
+	if cl.pending == 0 {
+		// The class's finish time is not after the virtual time, so a new
+		// element in the class starts from the virtual time either way.
+		delete(q.classes, e.class)
+	}
+	return e.class, e.val
+}
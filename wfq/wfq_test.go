@@ -0,0 +1,91 @@
+package wfq_test
+
+import (
+	"testing"
+
+	"github.com/gammazero/heap/wfq"
+)
+
+func TestWeightedShare(t *testing.T) {
+	q := wfq.New[string, int]()
+	q.SetWeight("interactive", 3)
+	q.SetWeight("batch", 1)
+	for i := range 100 {
+		q.Push("interactive", i, 1)
+		q.Push("batch", i, 1)
+	}
+
+	counts := map[string]int{}
+	for range 40 {
+		c, _ := q.Pop()
+		counts[c]++
+	}
+	if counts["interactive"] != 30 || counts["batch"] != 10 {
+		t.Fatalf("expected 3:1 share, got %v", counts)
+	}
+}
+
+func TestFIFOWithinClass(t *testing.T) {
+	q := wfq.New[int, int]()
+	for i := range 10 {
+		q.Push(i%2, i, 1)
+	}
+	last := map[int]int{0: -1, 1: -1}
+	for q.Len() != 0 {
+		c, x := q.Pop()
+		if x <= last[c] {
+			t.Fatalf("class %d element %d out of order", c, x)
+		}
+		last[c] = x
+	}
+}
+
+func TestIdleClassNotCredited(t *testing.T) {
+	q := wfq.New[string, int]()
+	for i := range 10 {
+		q.Push("busy", i, 1)
+	}
+	for range 10 {
+		q.Pop()
+	}
+
+	// A class that was idle does not get to catch up on the service it did
+	// not use, so it alternates with a busy class of equal weight.
+	q.Push("busy", 10, 1)
+	q.Push("busy", 11, 1)
+	q.Push("idle", 0, 1)
+	q.Push("idle", 1, 1)
+	var got []string
+	for q.Len() != 0 {
+		c, _ := q.Pop()
+		got = append(got, c)
+	}
+	if got[0] == got[1] || got[2] == got[3] {
+		t.Fatalf("expected alternating classes, got %v", got)
+	}
+}
+
+func TestCostAndPanics(t *testing.T) {
+	q := wfq.New[string, string]()
+	q.Push("a", "big", 10)
+	q.Push("b", "small-1", 1)
+	q.Push("b", "small-2", 1)
+	if _, x := q.Pop(); x != "small-1" {
+		t.Fatalf("expected small-1, got %s", x)
+	}
+
+	for name, f := range map[string]func(){
+		"zero weight": func() { q.SetWeight("a", 0) },
+		"zero cost":   func() { q.Push("a", "x", 0) },
+		"empty pop":   func() { wfq.New[int, int]().Pop() },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expected panic", name)
+				}
+			}()
+			f()
+		}()
+	}
+}
@@ -0,0 +1,106 @@
+// Package window tracks the minimum or maximum of the values in a sliding
+// window, for monitoring values such as request latency over a rolling time
+// period.
+//
+// Each value is pushed with a sequence number, such as a timestamp, and
+// values older than a given sequence number are evicted. Values are kept in
+// two heaps, one ordered by value and one by sequence number, so that values
+// can be pushed in any order of sequence number, such as samples that arrive
+// late. Push and eviction of each value take O(log n) time.
+package window
+
+import (
+	"cmp"
+
+	"github.com/gammazero/heap"
+)
+
+// Window holds values with sequence numbers, and tracks the value that is
+// first in the order given by its less function. It is not safe for
+// concurrent use.
+type Window[T any] struct {
+	byVal *heap.Heap[*entry[T]]
+	bySeq *heap.Heap[*entry[T]]
+}
+
+type entry[T any] struct {
+	val    T
+	seq    int64
+	valPos int
+	seqPos int
+}
+
+// New returns a new Window that tracks the value that is least according to
+// less. To track the greatest value, pass a less function that returns
+// whether a is greater than b.
+func New[T any](less func(a, b T) bool) *Window[T] {
+	w := &Window[T]{
+		byVal: heap.New(func(a, b *entry[T]) bool {
+			return less(a.val, b.val)
+		}),
+		bySeq: heap.New(func(a, b *entry[T]) bool {
+			return a.seq < b.seq
+		}),
+	}
+	w.byVal.SetOnMove(func(e *entry[T], i int) {
+		e.valPos = i
+	})
+	w.bySeq.SetOnMove(func(e *entry[T], i int) {
+		e.seqPos = i
+	})
+	return w
+}
+
+// NewMin returns a new Window that tracks the minimum value.
+func NewMin[T cmp.Ordered]() *Window[T] {
+	return New(cmp.Less[T])
+}
+
+// NewMax returns a new Window that tracks the maximum value.
+func NewMax[T cmp.Ordered]() *Window[T] {
+	return New(func(a, b T) bool { return cmp.Less(b, a) })
+}
+
+// Len returns the number of values in the window.
+func (w *Window[T]) Len() int {
+	return w.byVal.Len()
+}
+
+// Push adds a value with the given sequence number.
+func (w *Window[T]) Push(x T, seq int64) {
+	e := &entry[T]{val: x, seq: seq}
+	w.byVal.Push(e)
+	w.bySeq.Push(e)
+}
+
+// Evict removes the values with sequence numbers less than olderThan, and
+// returns the number of values removed.
+func (w *Window[T]) Evict(olderThan int64) int {
+	var n int
+	for w.bySeq.Len() != 0 && w.bySeq.Peek().seq < olderThan {
+		e := w.bySeq.Pop()
+		w.byVal.Remove(e.valPos)
+		n++
+	}
+	return n
+}
+
+// Top returns the least value in the window according to the less function,
+// which is the minimum or maximum value, and its sequence number. If the
+// window is empty, ok is false.
+func (w *Window[T]) Top() (x T, seq int64, ok bool) {
+	if w.byVal.Len() == 0 {
+		return x, 0, false
+	}
+	e := w.byVal.Peek()
+	return e.val, e.seq, true
+}
+
+// Oldest returns the smallest sequence number in the window. If the window is
+// empty, ok is false.
+func (w *Window[T]) Oldest() (seq int64, ok bool) {
+	if w.bySeq.Len() == 0 {
+		return 0, false
+	}
+	return w.bySeq.Peek().seq, true
+}
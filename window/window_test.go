@@ -0,0 +1,66 @@
+package window_test
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gammazero/heap/window"
+)
+
+func TestSlidingMinMax(t *testing.T) {
+	const span = 10
+	minW := window.NewMin[int]()
+	maxW := window.NewMax[int]()
+	var vals []int
+	for seq := range int64(300) {
+		x := rand.Intn(1000)
+		vals = append(vals, x)
+		minW.Push(x, seq)
+		maxW.Push(x, seq)
+		minW.Evict(seq - span + 1)
+		maxW.Evict(seq - span + 1)
+
+		in := vals[max(0, len(vals)-span):]
+		if minW.Len() != len(in) || maxW.Len() != len(in) {
+			t.Fatalf("seq %d: wrong length %d, expected %d", seq, minW.Len(), len(in))
+		}
+		if x, _, _ := minW.Top(); x != slices.Min(in) {
+			t.Fatalf("seq %d: min %d, expected %d", seq, x, slices.Min(in))
+		}
+		if x, _, _ := maxW.Top(); x != slices.Max(in) {
+			t.Fatalf("seq %d: max %d, expected %d", seq, x, slices.Max(in))
+		}
+	}
+}
+
+func TestOutOfOrder(t *testing.T) {
+	w := window.NewMax[time.Duration]()
+	now := time.Now()
+	w.Push(5*time.Millisecond, now.UnixNano())
+	w.Push(90*time.Millisecond, now.Add(-2*time.Minute).UnixNano())
+	w.Push(20*time.Millisecond, now.Add(-30*time.Second).UnixNano())
+
+	if x, _, _ := w.Top(); x != 90*time.Millisecond {
+		t.Fatalf("expected max 90ms, got %v", x)
+	}
+	if oldest, _ := w.Oldest(); oldest != now.Add(-2*time.Minute).UnixNano() {
+		t.Fatal("wrong oldest sequence number")
+	}
+	if n := w.Evict(now.Add(-time.Minute).UnixNano()); n != 1 {
+		t.Fatalf("expected 1 value evicted, got %d", n)
+	}
+	x, seq, ok := w.Top()
+	if !ok || x != 20*time.Millisecond || seq != now.Add(-30*time.Second).UnixNano() {
+		t.Fatalf("wrong max %v at %d after evicting", x, seq)
+	}
+
+	w.Evict(now.Add(time.Second).UnixNano())
+	if _, _, ok := w.Top(); ok {
+		t.Fatal("expected empty window")
+	}
+	if _, ok := w.Oldest(); ok {
+		t.Fatal("expected no oldest value in empty window")
+	}
+}
@@ -0,0 +1,184 @@
+// Package workerpool runs tasks on a fixed number of workers, always starting
+// the queued task with the highest priority first.
+//
+// Tasks that have the same priority run in the order they were submitted.
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/gammazero/heap"
+)
+
+// ErrStopped is passed to the completion callback of a task that was not run
+// because the worker pool was stopped.
+var ErrStopped = errors.New("workerpool: stopped")
+
+// WorkerPool is a collection of workers that run submitted tasks in priority
+// order.
+type WorkerPool struct {
+	mu       sync.Mutex
+	cond     sync.Cond
+	tasks    *heap.Heap[*task]
+	seq      uint64
+	stopping bool
+	draining bool
+	wg       sync.WaitGroup
+}
+
+type task struct {
+	fn   func(context.Context) error
+	ctx  context.Context
+	done func(error)
+	prio int
+	seq  uint64
+}
+
+// TaskOption configures an individual task given to Submit.
+type TaskOption func(*task)
+
+// WithContext sets the context passed to the task. If the context is canceled
+// before the task starts, then the task is not run and its completion
+// callback receives the context's error.
+func WithContext(ctx context.Context) TaskOption {
+	return func(t *task) {
+		t.ctx = ctx
+	}
+}
+
+// OnDone sets a function that is called with the task's result once the task
+// is finished or is discarded without running.
+func OnDone(fn func(error)) TaskOption {
+	return func(t *task) {
+		t.done = fn
+	}
+}
+
+// New creates and starts a pool of worker goroutines. The maxWorkers
+// parameter specifies the number of workers that run tasks concurrently. If
+// maxWorkers is less than 1, then 1 is used.
+func New(maxWorkers int) *WorkerPool {
+	if maxWorkers < 1 {
+		maxWorkers = 1
+	}
+	p := &WorkerPool{
+		tasks: heap.New(func(a, b *task) bool {
+			if a.prio != b.prio {
+				return a.prio > b.prio
+			}
+			return a.seq < b.seq
+		}),
+	}
+	p.cond.L = &p.mu
+	p.wg.Add(maxWorkers)
+	for range maxWorkers {
+		go p.worker()
+	}
+	return p
+}
+
+// Submit enqueues a task to be run by a worker. Tasks with a higher priority
+// are started before tasks with a lower priority.
+//
+// If the pool is stopped, then the task is not run and its completion
+// callback receives ErrStopped.
+func (p *WorkerPool) Submit(fn func(context.Context) error, prio int, opts ...TaskOption) {
+	t := &task{
+		fn:   fn,
+		ctx:  context.Background(),
+		prio: prio,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	p.mu.Lock()
+	if p.stopping {
+		p.mu.Unlock()
+		t.finish(ErrStopped)
+		return
+	}
+	p.seq++
+	t.seq = p.seq
+	p.tasks.Push(t)
+	p.mu.Unlock()
+	p.cond.Signal()
+}
+
+// WaitingQueueSize returns the number of tasks waiting for a worker.
+func (p *WorkerPool) WaitingQueueSize() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.tasks.Len()
+}
+
+// Stopped returns true if the pool has been stopped.
+func (p *WorkerPool) Stopped() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stopping
+}
+
+// Stop stops the worker pool and waits for only the currently running tasks
+// to complete. Waiting tasks are discarded and their completion callbacks
+// receive ErrStopped. Tasks submitted after Stop are also discarded.
+func (p *WorkerPool) Stop() {
+	p.stop(false)
+}
+
+// StopWait stops the worker pool and waits for all waiting tasks to run and
+// complete. Tasks submitted after StopWait are discarded.
+func (p *WorkerPool) StopWait() {
+	p.stop(true)
+}
+
+func (p *WorkerPool) stop(drain bool) {
+	p.mu.Lock()
+	if !p.stopping {
+		p.stopping = true
+		p.draining = drain
+	}
+	var discarded []*task
+	if !p.draining {
+		for p.tasks.Len() != 0 {
+			discarded = append(discarded, p.tasks.Pop())
+		}
+	}
+	p.mu.Unlock()
+	p.cond.Broadcast()
+
+	for _, t := range discarded {
+		t.finish(ErrStopped)
+	}
+	p.wg.Wait()
+}
+
+func (p *WorkerPool) worker() {
+	defer p.wg.Done()
+	for {
+		p.mu.Lock()
+		for p.tasks.Len() == 0 && !p.stopping {
+			p.cond.Wait()
+		}
+		if p.tasks.Len() == 0 {
+			p.mu.Unlock()
+			return
+		}
+		t := p.tasks.Pop()
+		p.mu.Unlock()
+
+		if err := t.ctx.Err(); err != nil {
+			t.finish(err)
+			continue
+		}
+		t.finish(t.fn(t.ctx))
+	}
+}
+
+func (t *task) finish(err error) {
+	if t.done != nil {
+		t.done(err)
+	}
+}
@@ -0,0 +1,131 @@
+package workerpool_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gammazero/heap/workerpool"
+)
+
+func TestPriorityOrder(t *testing.T) {
+	wp := workerpool.New(1)
+
+	// Block the only worker so that all other tasks queue up.
+	release := make(chan struct{})
+	started := make(chan struct{})
+	wp.Submit(func(context.Context) error {
+		close(started)
+		<-release
+		return nil
+	}, 0)
+	<-started
+
+	var mu sync.Mutex
+	var order []int
+	for _, prio := range []int{1, 5, 3, 5, 2} {
+		wp.Submit(func(context.Context) error {
+			mu.Lock()
+			order = append(order, prio)
+			mu.Unlock()
+			return nil
+		}, prio)
+	}
+	if wp.WaitingQueueSize() != 5 {
+		t.Fatalf("expected 5 waiting tasks, got %d", wp.WaitingQueueSize())
+	}
+	close(release)
+	wp.StopWait()
+
+	want := []int{5, 5, 3, 2, 1}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("expected order %v, got %v", want, order)
+		}
+	}
+}
+
+func TestOnDone(t *testing.T) {
+	wp := workerpool.New(2)
+	errTask := errors.New("task failed")
+
+	results := make(chan error, 2)
+	wp.Submit(func(context.Context) error { return nil }, 0,
+		workerpool.OnDone(func(err error) { results <- err }))
+	wp.Submit(func(context.Context) error { return errTask }, 0,
+		workerpool.OnDone(func(err error) { results <- err }))
+	wp.StopWait()
+
+	var gotNil, gotErr bool
+	for range 2 {
+		switch err := <-results; err {
+		case nil:
+			gotNil = true
+		case errTask:
+			gotErr = true
+		default:
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if !gotNil || !gotErr {
+		t.Fatal("missing completion callback")
+	}
+}
+
+func TestCanceledContext(t *testing.T) {
+	wp := workerpool.New(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var ran atomic.Bool
+	var result error
+	wp.Submit(func(context.Context) error {
+		ran.Store(true)
+		return nil
+	}, 0, workerpool.WithContext(ctx), workerpool.OnDone(func(err error) {
+		result = err
+	}))
+	wp.StopWait()
+
+	if ran.Load() {
+		t.Fatal("task with canceled context should not run")
+	}
+	if !errors.Is(result, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", result)
+	}
+}
+
+func TestStopDiscardsWaiting(t *testing.T) {
+	wp := workerpool.New(1)
+	release := make(chan struct{})
+	started := make(chan struct{})
+	wp.Submit(func(context.Context) error {
+		close(started)
+		<-release
+		return nil
+	}, 0)
+	<-started
+
+	// Waiting tasks are discarded before Stop waits for the running task, so
+	// release the running task once all waiting tasks are discarded.
+	var discarded atomic.Int32
+	onDone := workerpool.OnDone(func(err error) {
+		if errors.Is(err, workerpool.ErrStopped) && discarded.Add(1) == 3 {
+			close(release)
+		}
+	})
+	for range 3 {
+		wp.Submit(func(context.Context) error { return nil }, 0, onDone)
+	}
+	wp.Stop()
+	if !wp.Stopped() {
+		t.Fatal("expected pool to be stopped")
+	}
+
+	wp.Submit(func(context.Context) error { return nil }, 0, onDone)
+	if discarded.Load() != 4 {
+		t.Fatalf("expected 4 discarded tasks, got %d", discarded.Load())
+	}
+}
@@ -0,0 +1,39 @@
+package heap
+
+import "reflect"
+
+// hasPointers reports whether values of type T contain pointers that the
+// garbage collector must scan. Vacated slots in the heap's storage only need
+// to be zeroed, to release what they refer to, when T contains pointers.
+func hasPointers[T any]() bool {
+	return typeHasPointers(reflect.TypeFor[T]())
+}
+
+func typeHasPointers(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64, reflect.Uintptr, reflect.Float32,
+		reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return false
+	case reflect.Array:
+		return t.Len() != 0 && typeHasPointers(t.Elem())
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if typeHasPointers(t.Field(i).Type) {
+				return true
+			}
+		}
+		return false
+	}
+	// Pointers, strings, slices, maps, channels, functions, and interfaces.
+	return true
+}
+
+// clearSlot zeroes the element at index i if T contains pointers.
+func (h *Heap[T]) clearSlot(i int) {
+	if !h.pointerFree {
+		var zero T
+		h.data[i] = zero
+	}
+}
@@ -0,0 +1,59 @@
+package heap_test
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/gammazero/heap"
+)
+
+func TestPopReleasesPointers(t *testing.T) {
+	type item struct {
+		prio int
+		buf  []byte
+	}
+	h := heap.New(func(a, b *item) bool { return a.prio < b.prio })
+	released := make(chan struct{})
+	x := &item{prio: 1, buf: make([]byte, 1024)}
+	runtime.AddCleanup(x, func(ch chan struct{}) { close(ch) }, released)
+	h.Push(x)
+	h.Push(&item{prio: 2})
+	x = nil
+	h.Pop()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		runtime.GC()
+		select {
+		case <-released:
+			return
+		default:
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("popped element still referenced by heap")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestPointerFreeStruct(t *testing.T) {
+	type item struct {
+		prio    int
+		payload [64]int64
+	}
+	h := heap.New(func(a, b item) bool { return a.prio < b.prio })
+	for i := 100; i > 0; i-- {
+		h.Push(item{prio: i, payload: [64]int64{63: int64(i)}})
+	}
+	h.Remove(50)
+	h.DeleteFunc(func(x item) bool { return x.prio > 90 })
+	prev := 0
+	for h.Len() != 0 {
+		x := h.Pop()
+		if x.prio < prev || x.payload[63] != int64(x.prio) {
+			t.Fatalf("wrong element %d after %d", x.prio, prev)
+		}
+		prev = x.prio
+	}
+}